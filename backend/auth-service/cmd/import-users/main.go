@@ -0,0 +1,90 @@
+// Command import-users imports the users of another platform from a CSV or
+// JSONL export and prints a JSON report of conflicts and invalid records.
+//
+//	import-users -file users.csv -dry-run
+//	import-users -file users.jsonl -role user
+//
+// It reads the same configuration as the service.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/deps"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/logger"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/secrets"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/service"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/userimport"
+	"go.uber.org/zap"
+)
+
+func main() {
+	file := flag.String("file", "", "export to import")
+	format := flag.String("format", "", "csv or jsonl; taken from the file extension when empty")
+	role := flag.String("role", service.DefaultRoleName, "role of the imported users")
+	dryRun := flag.Bool("dry-run", false, "report conflicts without importing")
+	flag.Parse()
+
+	log := logger.NewLogger()
+	defer log.Sync()
+
+	if *file == "" {
+		log.Fatal("Missing -file")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	input, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("Failed to open export", zap.Error(err))
+	}
+	records, err := userimport.Read(input, *format)
+	input.Close()
+	if err != nil {
+		log.Fatal("Failed to parse export", zap.String("file", *file), zap.Error(err))
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Error loading config", zap.Error(err))
+	}
+	keyring, err := secrets.NewKeyring(*cfg)
+	if err != nil {
+		log.Fatal("Failed to init token secret keys", zap.Error(err))
+	}
+	pool, err := db.InitDB(*cfg, log)
+	if err != nil {
+		log.Fatal("Failed to init db", zap.Error(err))
+	}
+	defer pool.Close()
+	implementation := deps.NewDB(pool, keyring, cfg.AuditHMACKey, log)
+
+	ctx := context.Background()
+	roleID, err := implementation.RoleQuery().GetIDByName(ctx, *role)
+	if err != nil {
+		log.Fatal("Failed to find role", zap.String("role", *role), zap.Error(err))
+	}
+
+	report, err := userimport.NewImporter(implementation, log).Import(ctx, records, userimport.Options{
+		RoleID: roleID,
+		DryRun: *dryRun,
+	})
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if report != nil {
+		if err := encoder.Encode(report); err != nil {
+			log.Error("Failed to write report", zap.Error(err))
+		}
+	}
+	if err != nil {
+		log.Fatal("Import failed", zap.Error(err))
+	}
+}
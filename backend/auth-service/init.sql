@@ -1,3 +1,16 @@
+DROP TABLE outbox_events;
+DROP TABLE audit_events;
+DROP FUNCTION audit_events_append_only;
+DROP TABLE user_status_changes;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE signing_keys;
+DROP TABLE login_attempts;
+DROP TABLE mfa_challenges;
+DROP TABLE mfa_recovery_codes;
+DROP TABLE email_verifications;
+DROP TABLE password_resets;
+DROP TABLE sessions;
 DROP TABLE users;
 DROP TABLE roles;
 
@@ -5,7 +18,8 @@ CREATE TABLE roles (
    roles_id_pk BIGSERIAL PRIMARY KEY,
    roles_name TEXT UNIQUE,
    roles_code INT UNIQUE,
-   roles_descr TEXT
+   roles_descr TEXT,
+   roles_mfa_required BOOLEAN NOT NULL DEFAULT false
 );
 
 CREATE TABLE users (
@@ -13,16 +27,198 @@ CREATE TABLE users (
    users_username VARCHAR(100) UNIQUE,
    users_password_hash TEXT,
    users_email TEXT UNIQUE,
+   users_email_verified BOOLEAN NOT NULL DEFAULT false,
    users_auth_time TIMESTAMP DEFAULT now(),
    users_roles_id_fk BIGINT,
    users_access_token_secret TEXT,
    users_refresh_token_secret TEXT,
-   users_access_token_jti UUID,
-   users_refresh_token_jti UUID,
+   users_totp_secret TEXT,
+   users_totp_enabled BOOLEAN NOT NULL DEFAULT false,
+   users_totp_last_step BIGINT NOT NULL DEFAULT 0,
+   users_status TEXT NOT NULL DEFAULT 'active' CHECK (users_status IN ('pending', 'active', 'suspended', 'deleted')),
+   users_status_reason TEXT,
+   users_status_changed_at TIMESTAMP,
    users_created_at TIMESTAMP DEFAULT now(),
    users_updated_at TIMESTAMP DEFAULT now(),
    FOREIGN KEY(users_roles_id_fk) REFERENCES roles(roles_id_pk)
 );
 
-INSERT INTO roles (roles_name, roles_code, roles_descr)
-VALUES ('user', 1, 'default user of app');
\ No newline at end of file
+CREATE INDEX users_roles_id_fk_idx ON users(users_roles_id_fk);
+CREATE INDEX users_created_at_idx ON users(users_created_at);
+CREATE INDEX users_auth_time_idx ON users(users_auth_time);
+CREATE INDEX users_username_prefix_idx ON users(lower(users_username) text_pattern_ops);
+CREATE INDEX users_email_prefix_idx ON users(lower(users_email) text_pattern_ops);
+CREATE UNIQUE INDEX users_email_lower_idx ON users(lower(users_email));
+CREATE INDEX users_status_idx ON users(users_status);
+
+CREATE TABLE sessions (
+   sessions_id_pk UUID PRIMARY KEY,
+   sessions_users_id_fk BIGINT NOT NULL,
+   sessions_access_token_jti UUID NOT NULL,
+   sessions_refresh_token_jti UUID NOT NULL,
+   sessions_user_agent TEXT,
+   sessions_ip_address TEXT,
+   sessions_created_at TIMESTAMP DEFAULT now(),
+   sessions_last_used_at TIMESTAMP DEFAULT now(),
+   sessions_expires_at TIMESTAMP NOT NULL,
+   FOREIGN KEY(sessions_users_id_fk) REFERENCES users(users_id_pk) ON DELETE CASCADE
+);
+
+CREATE INDEX sessions_users_id_fk_idx ON sessions(sessions_users_id_fk);
+
+CREATE TABLE password_resets (
+   password_resets_id_pk BIGSERIAL PRIMARY KEY,
+   password_resets_users_id_fk BIGINT NOT NULL,
+   password_resets_token_hash TEXT UNIQUE NOT NULL,
+   password_resets_expires_at TIMESTAMP NOT NULL,
+   password_resets_used_at TIMESTAMP,
+   password_resets_created_at TIMESTAMP DEFAULT now(),
+   FOREIGN KEY(password_resets_users_id_fk) REFERENCES users(users_id_pk) ON DELETE CASCADE
+);
+
+CREATE TABLE email_verifications (
+   email_verifications_id_pk BIGSERIAL PRIMARY KEY,
+   email_verifications_users_id_fk BIGINT NOT NULL,
+   email_verifications_token_hash TEXT UNIQUE NOT NULL,
+   email_verifications_expires_at TIMESTAMP NOT NULL,
+   email_verifications_used_at TIMESTAMP,
+   email_verifications_created_at TIMESTAMP DEFAULT now(),
+   FOREIGN KEY(email_verifications_users_id_fk) REFERENCES users(users_id_pk) ON DELETE CASCADE
+);
+
+CREATE TABLE mfa_recovery_codes (
+   mfa_recovery_codes_id_pk BIGSERIAL PRIMARY KEY,
+   mfa_recovery_codes_users_id_fk BIGINT NOT NULL,
+   mfa_recovery_codes_code_hash TEXT NOT NULL,
+   mfa_recovery_codes_used_at TIMESTAMP,
+   mfa_recovery_codes_created_at TIMESTAMP DEFAULT now(),
+   FOREIGN KEY(mfa_recovery_codes_users_id_fk) REFERENCES users(users_id_pk) ON DELETE CASCADE
+);
+
+CREATE INDEX mfa_recovery_codes_users_id_fk_idx ON mfa_recovery_codes(mfa_recovery_codes_users_id_fk);
+
+-- Open MFA challenges, one per MFA token Login handed out. A challenge is
+-- deleted when the second factor passes or after too many wrong codes.
+CREATE TABLE mfa_challenges (
+   mfa_challenges_jti_pk UUID PRIMARY KEY,
+   mfa_challenges_users_id_fk BIGINT NOT NULL,
+   mfa_challenges_failures INT NOT NULL DEFAULT 0,
+   mfa_challenges_expires_at TIMESTAMP NOT NULL,
+   mfa_challenges_created_at TIMESTAMP DEFAULT now(),
+   FOREIGN KEY(mfa_challenges_users_id_fk) REFERENCES users(users_id_pk) ON DELETE CASCADE
+);
+
+CREATE INDEX mfa_challenges_expires_at_idx ON mfa_challenges(mfa_challenges_expires_at);
+
+CREATE TABLE login_attempts (
+   login_attempts_id_pk BIGSERIAL PRIMARY KEY,
+   login_attempts_scope TEXT NOT NULL,
+   login_attempts_key TEXT NOT NULL,
+   login_attempts_failures INT NOT NULL DEFAULT 0,
+   login_attempts_last_failure_at TIMESTAMP NOT NULL,
+   login_attempts_locked_until TIMESTAMP,
+   UNIQUE(login_attempts_scope, login_attempts_key)
+);
+
+CREATE TABLE signing_keys (
+   signing_keys_id_pk TEXT PRIMARY KEY,
+   signing_keys_algorithm TEXT NOT NULL,
+   signing_keys_private_key TEXT NOT NULL,
+   signing_keys_state TEXT NOT NULL,
+   signing_keys_created_at TIMESTAMP DEFAULT now(),
+   signing_keys_rotated_at TIMESTAMP,
+   signing_keys_retired_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX signing_keys_active_idx ON signing_keys(signing_keys_state) WHERE signing_keys_state = 'active';
+
+CREATE TABLE permissions (
+   permissions_id_pk BIGSERIAL PRIMARY KEY,
+   permissions_name TEXT UNIQUE NOT NULL,
+   permissions_descr TEXT
+);
+
+CREATE TABLE role_permissions (
+   role_permissions_roles_id_fk BIGINT NOT NULL,
+   role_permissions_permissions_id_fk BIGINT NOT NULL,
+   PRIMARY KEY(role_permissions_roles_id_fk, role_permissions_permissions_id_fk),
+   FOREIGN KEY(role_permissions_roles_id_fk) REFERENCES roles(roles_id_pk) ON DELETE CASCADE,
+   FOREIGN KEY(role_permissions_permissions_id_fk) REFERENCES permissions(permissions_id_pk) ON DELETE CASCADE
+);
+
+CREATE TABLE user_status_changes (
+   user_status_changes_id_pk BIGSERIAL PRIMARY KEY,
+   user_status_changes_users_id_fk BIGINT NOT NULL,
+   user_status_changes_from TEXT NOT NULL,
+   user_status_changes_to TEXT NOT NULL,
+   user_status_changes_reason TEXT,
+   user_status_changes_actor_id BIGINT,
+   user_status_changes_created_at TIMESTAMP DEFAULT now(),
+   FOREIGN KEY(user_status_changes_users_id_fk) REFERENCES users(users_id_pk) ON DELETE CASCADE
+);
+
+CREATE INDEX user_status_changes_users_id_fk_idx ON user_status_changes(user_status_changes_users_id_fk);
+
+-- Actor and subject are plain IDs rather than foreign keys: the audit log
+-- must outlive the users it mentions.
+CREATE TABLE audit_events (
+   audit_events_id_pk BIGSERIAL PRIMARY KEY,
+   audit_events_type TEXT NOT NULL,
+   audit_events_actor_id BIGINT,
+   audit_events_subject_id BIGINT,
+   audit_events_ip_address TEXT,
+   audit_events_user_agent TEXT,
+   audit_events_outcome TEXT NOT NULL CHECK (audit_events_outcome IN ('success', 'failure')),
+   audit_events_reason TEXT,
+   audit_events_created_at TIMESTAMP NOT NULL,
+   audit_events_prev_hash TEXT NOT NULL,
+   audit_events_hash TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX audit_events_type_created_at_idx ON audit_events(audit_events_type, audit_events_created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events(audit_events_actor_id);
+CREATE INDEX audit_events_subject_id_idx ON audit_events(audit_events_subject_id);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+   RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- Events for other services, written in the transaction of the change they
+-- describe and delivered by the outbox relay.
+CREATE TABLE outbox_events (
+   outbox_events_id_pk BIGSERIAL PRIMARY KEY,
+   outbox_events_type TEXT NOT NULL,
+   outbox_events_user_id BIGINT NOT NULL,
+   outbox_events_payload JSONB NOT NULL,
+   outbox_events_attempts INT NOT NULL DEFAULT 0,
+   outbox_events_next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+   outbox_events_last_error TEXT,
+   outbox_events_published_at TIMESTAMP,
+   outbox_events_created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events(outbox_events_user_id, outbox_events_id_pk) WHERE outbox_events_published_at IS NULL;
+CREATE INDEX outbox_events_published_at_idx ON outbox_events(outbox_events_published_at);
+
+INSERT INTO roles (roles_name, roles_code, roles_descr, roles_mfa_required)
+VALUES ('user', 1, 'default user of app', false),
+       ('admin', 2, 'administrator of app', true);
+
+INSERT INTO permissions (permissions_name, permissions_descr)
+VALUES ('tests:author', 'create and edit tests'),
+       ('users:admin', 'manage user accounts'),
+       ('roles:admin', 'manage roles and permissions and assign roles'),
+       ('audit:read', 'read and verify the audit log'),
+       ('keys:rotate', 'rotate the token signing key'),
+       ('results:read_class', 'read results of a whole class');
+
+INSERT INTO role_permissions (role_permissions_roles_id_fk, role_permissions_permissions_id_fk)
+SELECT roles_id_pk, permissions_id_pk
+FROM roles, permissions
+WHERE roles_name = 'admin';
\ No newline at end of file
@@ -1,13 +1,33 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Email verification modes. With "off" no verification mail is sent, with
+// "claim" unverified users may log in and tokens carry an email_verified claim,
+// with "required" Login refuses unverified users.
+const (
+	EmailVerificationOff      = "off"
+	EmailVerificationClaim    = "claim"
+	EmailVerificationRequired = "required"
+)
+
+// Password hashing algorithms. New hashes use the configured one; hashes of
+// the other are still verified and replaced on the next login.
+const (
+	HashAlgorithmArgon2id = "argon2id"
+	HashAlgorithmBcrypt   = "bcrypt"
+)
+
 type AppConfig struct {
 	DBHost                   string
 	DBPort                   string
@@ -15,8 +35,83 @@ type AppConfig struct {
 	DBPassword               string
 	DBName                   string
 	GRPCAddr                 string
+	HTTPAddr                 string
+	TrustedProxies           []netip.Prefix
 	ACCESS_TOKEN_EXPIRES_IN  time.Duration
 	REFRESH_TOKEN_EXPIRES_IN time.Duration
+	SESSION_TOUCH_INTERVAL   time.Duration
+
+	PASSWORD_RESET_EXPIRES_IN      time.Duration
+	PASSWORD_RESET_RESEND_INTERVAL time.Duration
+	PasswordResetURL               string
+
+	PasswordMinLength        int
+	PasswordMaxLength        int
+	PasswordMinStrength      int
+	PasswordBreachedListFile string
+	PasswordDictionaryDir    string
+
+	PasswordHashAlgorithm string
+	Argon2Memory          int
+	Argon2Time            int
+	Argon2Parallelism     int
+	BcryptCost            int
+
+	EmailVerificationMode              string
+	EMAIL_VERIFICATION_EXPIRES_IN      time.Duration
+	EMAIL_VERIFICATION_RESEND_INTERVAL time.Duration
+	EmailVerificationURL               string
+
+	MFA_TOKEN_EXPIRES_IN time.Duration
+	TOTPIssuer           string
+
+	JWTIssuer                 string
+	JWTAudiences              []string
+	JWT_LEEWAY                time.Duration
+	JWTPermissionsClaim       bool
+	JWTSigningKeyFile         string
+	JWTSigningAlgorithm       string
+	SigningKeyEncryptionKey   string
+	JWT_KEY_ROTATION_INTERVAL time.Duration
+	JWT_KEY_REFRESH_INTERVAL  time.Duration
+
+	TokenSecretKeys                 string
+	TokenSecretKeysFile             string
+	TokenSecretKeyID                string
+	TokenSecretRequireEncryption    bool
+	TokenSecretReencryptBatchSize   int
+	TOKEN_SECRET_REENCRYPT_INTERVAL time.Duration
+
+	LoginMaxAccountFailures int
+	LoginMaxIPFailures      int
+	LOGIN_FAILURE_WINDOW    time.Duration
+	LOGIN_LOCKOUT_BASE      time.Duration
+	LOGIN_LOCKOUT_MAX       time.Duration
+
+	MailBackend  string
+	MailFrom     string
+	MailFileDir  string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	OutboxBackend       string
+	OutboxWebhookURL    string
+	OutboxWebhookSecret string
+	OutboxGRPCAddr      string
+	// OutboxNATSURL is the NATS server of the nats backend. When it is empty
+	// the service embeds a server for local testing.
+	OutboxNATSURL          string
+	OutboxNATSEmbeddedAddr string
+	OutboxNATSDataDir      string
+	OutboxBatchSize        int
+	OUTBOX_POLL_INTERVAL   time.Duration
+	OUTBOX_RETRY_BASE      time.Duration
+	OUTBOX_RETRY_MAX       time.Duration
+	OUTBOX_RETENTION       time.Duration
+
+	AuditHMACKey []byte
 }
 
 func LoadConfig() (*AppConfig, error) {
@@ -32,6 +127,42 @@ func LoadConfig() (*AppConfig, error) {
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBName:     os.Getenv("DB_NAME"),
 		GRPCAddr:   os.Getenv("GRPC_ADDR"),
+		HTTPAddr:   os.Getenv("HTTP_ADDR"),
+
+		PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+
+		PasswordBreachedListFile: os.Getenv("PASSWORD_BREACHED_LIST_FILE"),
+		PasswordDictionaryDir:    os.Getenv("PASSWORD_DICTIONARY_DIR"),
+		PasswordHashAlgorithm:    os.Getenv("PASSWORD_HASH_ALGORITHM"),
+
+		EmailVerificationMode: os.Getenv("EMAIL_VERIFICATION_MODE"),
+		EmailVerificationURL:  os.Getenv("EMAIL_VERIFICATION_URL"),
+
+		TOTPIssuer: os.Getenv("TOTP_ISSUER"),
+
+		JWTIssuer:               os.Getenv("JWT_ISSUER"),
+		JWTSigningKeyFile:       os.Getenv("JWT_SIGNING_KEY_FILE"),
+		JWTSigningAlgorithm:     os.Getenv("JWT_SIGNING_ALG"),
+		SigningKeyEncryptionKey: os.Getenv("SIGNING_KEY_ENCRYPTION_KEY"),
+
+		TokenSecretKeys:     os.Getenv("TOKEN_SECRET_KEYS"),
+		TokenSecretKeysFile: os.Getenv("TOKEN_SECRET_KEYS_FILE"),
+		TokenSecretKeyID:    os.Getenv("TOKEN_SECRET_KEY_ID"),
+
+		MailBackend:  os.Getenv("MAIL_BACKEND"),
+		MailFrom:     os.Getenv("MAIL_FROM"),
+		MailFileDir:  os.Getenv("MAIL_FILE_DIR"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+
+		OutboxBackend:       os.Getenv("OUTBOX_BACKEND"),
+		OutboxWebhookURL:    os.Getenv("OUTBOX_WEBHOOK_URL"),
+		OutboxWebhookSecret: os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+		OutboxGRPCAddr:      os.Getenv("OUTBOX_GRPC_ADDR"),
+		OutboxNATSURL:       os.Getenv("OUTBOX_NATS_URL"),
+		OutboxNATSDataDir:   os.Getenv("OUTBOX_NATS_DATA_DIR"),
 	}
 
 	accessTokenExpiresIn := os.Getenv("ACCESS_TOKEN_EXPIRES_IN")
@@ -53,6 +184,279 @@ func LoadConfig() (*AppConfig, error) {
 		return nil, fmt.Errorf("failed to parse REFRESH_TOKEN_EXPIRES_IN: %v", err)
 	}
 	cfg.REFRESH_TOKEN_EXPIRES_IN = refreshDuration
+	cfg.SESSION_TOUCH_INTERVAL, err = durationOrDefault("SESSION_TOUCH_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.PASSWORD_RESET_EXPIRES_IN, err = durationOrDefault("PASSWORD_RESET_EXPIRES_IN", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.PASSWORD_RESET_RESEND_INTERVAL, err = durationOrDefault("PASSWORD_RESET_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.PasswordHashAlgorithm {
+	case "":
+		cfg.PasswordHashAlgorithm = HashAlgorithmArgon2id
+	case HashAlgorithmArgon2id, HashAlgorithmBcrypt:
+	default:
+		return nil, fmt.Errorf("unknown PASSWORD_HASH_ALGORITHM %q", cfg.PasswordHashAlgorithm)
+	}
+	// The defaults follow the second recommended option of RFC 9106, with
+	// less parallelism: 64 MiB of memory and three passes.
+	cfg.Argon2Memory, err = intOrDefault("ARGON2_MEMORY_KIB", 64*1024)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Argon2Time, err = intOrDefault("ARGON2_TIME", 3)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Argon2Parallelism, err = intOrDefault("ARGON2_PARALLELISM", 2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Argon2Time < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 || cfg.Argon2Memory < 8*cfg.Argon2Parallelism {
+		return nil, fmt.Errorf("ARGON2_TIME and ARGON2_PARALLELISM must be positive and ARGON2_MEMORY_KIB at least 8 per thread")
+	}
+	cfg.BcryptCost, err = intOrDefault("BCRYPT_COST", 10)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BcryptCost < 4 || cfg.BcryptCost > 31 {
+		return nil, fmt.Errorf("BCRYPT_COST must be between 4 and 31")
+	}
+
+	cfg.PasswordMinLength, err = intOrDefault("PASSWORD_MIN_LENGTH", 8)
+	if err != nil {
+		return nil, err
+	}
+	cfg.PasswordMaxLength, err = intOrDefault("PASSWORD_MAX_LENGTH", 64)
+	if err != nil {
+		return nil, err
+	}
+	// bcrypt ignores everything after the first 72 bytes; argon2id takes any
+	// length, but the strength estimate gets slow on very long passwords.
+	maxLength := 256
+	if cfg.PasswordHashAlgorithm == HashAlgorithmBcrypt {
+		maxLength = 72
+	}
+	if cfg.PasswordMinLength < 1 || cfg.PasswordMaxLength < cfg.PasswordMinLength || cfg.PasswordMaxLength > maxLength {
+		return nil, fmt.Errorf("PASSWORD_MIN_LENGTH and PASSWORD_MAX_LENGTH must satisfy 1 <= min <= max <= %d", maxLength)
+	}
+	cfg.PasswordMinStrength, err = intOrDefault("PASSWORD_MIN_STRENGTH", 2)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PasswordMinStrength < 0 || cfg.PasswordMinStrength > 4 {
+		return nil, fmt.Errorf("PASSWORD_MIN_STRENGTH must be between 0 and 4")
+	}
+
+	switch cfg.EmailVerificationMode {
+	case "":
+		cfg.EmailVerificationMode = EmailVerificationClaim
+	case EmailVerificationOff, EmailVerificationClaim, EmailVerificationRequired:
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_VERIFICATION_MODE %q", cfg.EmailVerificationMode)
+	}
+	cfg.EMAIL_VERIFICATION_EXPIRES_IN, err = durationOrDefault("EMAIL_VERIFICATION_EXPIRES_IN", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.EMAIL_VERIFICATION_RESEND_INTERVAL, err = durationOrDefault("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.MFA_TOKEN_EXPIRES_IN, err = durationOrDefault("MFA_TOKEN_EXPIRES_IN", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TOTPIssuer == "" {
+		cfg.TOTPIssuer = "SiriusLingo"
+	}
+
+	if cfg.HTTPAddr == "" {
+		cfg.HTTPAddr = ":8081"
+	}
+	// Only proxies listed here are trusted with x-forwarded-for; without any,
+	// the client address is always the address of the peer.
+	for _, value := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return nil, fmt.Errorf("failed to parse TRUSTED_PROXIES entry %q: %v", value, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix.Masked())
+	}
+	if cfg.JWTIssuer == "" {
+		cfg.JWTIssuer = "siriuslingo-auth"
+	}
+	for _, audience := range strings.Split(os.Getenv("JWT_AUDIENCES"), ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			cfg.JWTAudiences = append(cfg.JWTAudiences, audience)
+		}
+	}
+	cfg.JWT_LEEWAY, err = durationOrDefault("JWT_LEEWAY", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if value := os.Getenv("JWT_PERMISSIONS_CLAIM"); value != "" {
+		cfg.JWTPermissionsClaim, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT_PERMISSIONS_CLAIM: %v", err)
+		}
+	}
+	if cfg.JWTSigningAlgorithm == "" {
+		cfg.JWTSigningAlgorithm = "EdDSA"
+	}
+	if cfg.SigningKeyEncryptionKey == "" {
+		return nil, fmt.Errorf("SIGNING_KEY_ENCRYPTION_KEY is empty or not set in .env")
+	}
+	cfg.JWT_KEY_ROTATION_INTERVAL, err = positiveDurationOrDefault("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.JWT_KEY_REFRESH_INTERVAL, err = positiveDurationOrDefault("JWT_KEY_REFRESH_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.TokenSecretKeys == "" && cfg.TokenSecretKeysFile == "" {
+		return nil, fmt.Errorf("neither TOKEN_SECRET_KEYS nor TOKEN_SECRET_KEYS_FILE is set in .env")
+	}
+	if cfg.TokenSecretKeys != "" && cfg.TokenSecretKeysFile != "" {
+		return nil, fmt.Errorf("only one of TOKEN_SECRET_KEYS and TOKEN_SECRET_KEYS_FILE may be set")
+	}
+	if value := os.Getenv("TOKEN_SECRET_REQUIRE_ENCRYPTION"); value != "" {
+		cfg.TokenSecretRequireEncryption, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TOKEN_SECRET_REQUIRE_ENCRYPTION: %v", err)
+		}
+	}
+	cfg.TokenSecretReencryptBatchSize, err = intOrDefault("TOKEN_SECRET_REENCRYPT_BATCH_SIZE", 500)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TokenSecretReencryptBatchSize <= 0 {
+		return nil, fmt.Errorf("TOKEN_SECRET_REENCRYPT_BATCH_SIZE must be positive")
+	}
+	cfg.TOKEN_SECRET_REENCRYPT_INTERVAL, err = positiveDurationOrDefault("TOKEN_SECRET_REENCRYPT_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.LoginMaxAccountFailures, err = intOrDefault("LOGIN_MAX_ACCOUNT_FAILURES", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginMaxIPFailures, err = intOrDefault("LOGIN_MAX_IP_FAILURES", 20)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LOGIN_FAILURE_WINDOW, err = durationOrDefault("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LOGIN_LOCKOUT_BASE, err = durationOrDefault("LOGIN_LOCKOUT_BASE", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LOGIN_LOCKOUT_MAX, err = durationOrDefault("LOGIN_LOCKOUT_MAX", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OutboxNATSEmbeddedAddr = os.Getenv("OUTBOX_NATS_EMBEDDED_ADDR")
+	if cfg.OutboxNATSEmbeddedAddr == "" {
+		cfg.OutboxNATSEmbeddedAddr = "127.0.0.1:4222"
+	}
+	cfg.OutboxBatchSize, err = intOrDefault("OUTBOX_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.OutboxBatchSize <= 0 {
+		return nil, fmt.Errorf("OUTBOX_BATCH_SIZE must be positive")
+	}
+	cfg.OUTBOX_POLL_INTERVAL, err = positiveDurationOrDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OUTBOX_RETRY_BASE, err = positiveDurationOrDefault("OUTBOX_RETRY_BASE", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OUTBOX_RETRY_MAX, err = positiveDurationOrDefault("OUTBOX_RETRY_MAX", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OUTBOX_RETENTION, err = durationOrDefault("OUTBOX_RETENTION", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	// The audit chain is keyed so that only the service can extend it; the key
+	// must not be stored with the database.
+	if os.Getenv("AUDIT_HMAC_KEY") == "" {
+		return nil, fmt.Errorf("AUDIT_HMAC_KEY is empty or not set in .env")
+	}
+	cfg.AuditHMACKey, err = base64.StdEncoding.DecodeString(os.Getenv("AUDIT_HMAC_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AUDIT_HMAC_KEY: %v", err)
+	}
+	if len(cfg.AuditHMACKey) < 32 {
+		return nil, fmt.Errorf("AUDIT_HMAC_KEY must be at least 32 bytes, got %d", len(cfg.AuditHMACKey))
+	}
 
 	return &cfg, nil
 }
+
+// durationOrDefault parses an optional duration variable, falling back to def
+// when it is not set.
+func durationOrDefault(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+	return duration, nil
+}
+
+// positiveDurationOrDefault is durationOrDefault for intervals, which must be
+// positive: a ticker panics on anything else.
+func positiveDurationOrDefault(name string, def time.Duration) (time.Duration, error) {
+	duration, err := durationOrDefault(name, def)
+	if err != nil {
+		return 0, err
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("%s must be positive", name)
+	}
+	return duration, nil
+}
+
+// intOrDefault parses an optional integer variable, falling back to def when
+// it is not set.
+func intOrDefault(name string, def int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const AuditEventsTable = "audit_events"
+
+const (
+	AuditEventsID        = "audit_events_id_pk"
+	AuditEventsType      = "audit_events_type"
+	AuditEventsActorID   = "audit_events_actor_id"
+	AuditEventsSubjectID = "audit_events_subject_id"
+	AuditEventsIPAddress = "audit_events_ip_address"
+	AuditEventsUserAgent = "audit_events_user_agent"
+	AuditEventsOutcome   = "audit_events_outcome"
+	AuditEventsReason    = "audit_events_reason"
+	AuditEventsCreatedAt = "audit_events_created_at"
+	AuditEventsPrevHash  = "audit_events_prev_hash"
+	AuditEventsHash      = "audit_events_hash"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditGenesisHash is the previous hash of the first event of the chain.
+var AuditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// auditChainLock is the advisory lock that serializes appends, so that every
+// event links to the one written right before it.
+const auditChainLock = 0x61756469
+
+// AuditEvent is a security relevant action. Events are never updated or
+// deleted; each one carries the hash of its predecessor, so removing or
+// altering an event breaks the chain from there on. Hashes are keyed with
+// AUDIT_HMAC_KEY, so without the key a chain cannot be rewritten to cover
+// up a change. Events removed from the end of the chain leave no gap; they
+// are only noticed against a head recorded earlier, see VerifyAuditLog.
+type AuditEvent struct {
+	ID        int64     `db:"audit_events_id_pk"`
+	Type      string    `db:"audit_events_type" insert:"audit_events_type"`
+	ActorID   *int64    `db:"audit_events_actor_id" insert:"audit_events_actor_id"`
+	SubjectID *int64    `db:"audit_events_subject_id" insert:"audit_events_subject_id"`
+	IPAddress string    `db:"audit_events_ip_address" insert:"audit_events_ip_address"`
+	UserAgent string    `db:"audit_events_user_agent" insert:"audit_events_user_agent"`
+	Outcome   string    `db:"audit_events_outcome" insert:"audit_events_outcome"`
+	Reason    string    `db:"audit_events_reason" insert:"audit_events_reason"`
+	CreatedAt time.Time `db:"audit_events_created_at" insert:"audit_events_created_at"`
+	PrevHash  string    `db:"audit_events_prev_hash" insert:"audit_events_prev_hash"`
+	Hash      string    `db:"audit_events_hash" insert:"audit_events_hash"`
+}
+
+var (
+	stomAuditEventSelect = stom.MustNewStom(AuditEvent{}).SetTag(selectTag)
+	stomAuditEventInsert = stom.MustNewStom(AuditEvent{}).SetTag(insertTag)
+)
+
+func (e *AuditEvent) columns(pref string) []string {
+	return colNamesWithPref(stomAuditEventSelect.TagValues(), pref)
+}
+
+// ComputeHash computes the HMAC-SHA256 under key of the previous hash together
+// with every field of the event but its ID and own hash.
+func (e *AuditEvent) ComputeHash(key []byte) string {
+	payload, _ := json.Marshal(struct {
+		Type      string `json:"type"`
+		ActorID   *int64 `json:"actor_id"`
+		SubjectID *int64 `json:"subject_id"`
+		IPAddress string `json:"ip_address"`
+		UserAgent string `json:"user_agent"`
+		Outcome   string `json:"outcome"`
+		Reason    string `json:"reason"`
+		CreatedAt int64  `json:"created_at"`
+	}{
+		Type:      e.Type,
+		ActorID:   e.ActorID,
+		SubjectID: e.SubjectID,
+		IPAddress: e.IPAddress,
+		UserAgent: e.UserAgent,
+		Outcome:   e.Outcome,
+		Reason:    e.Reason,
+		CreatedAt: e.CreatedAt.UnixMicro(),
+	})
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(e.PrevHash + "\n"))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AuditEventFilter selects events for QueryAuditEvents, newest first. Zero
+// fields do not filter; BeforeID continues after the last event of a page.
+type AuditEventFilter struct {
+	Types     []string
+	ActorID   int64
+	SubjectID int64
+	From      *time.Time
+	To        *time.Time
+	BeforeID  int64
+	Limit     uint64
+}
+
+type AuditEventQuery interface {
+	Append(ctx context.Context, event *AuditEvent) (*AuditEvent, error)
+	List(ctx context.Context, filter *AuditEventFilter) ([]*AuditEvent, error)
+	ListChain(ctx context.Context, afterID int64, limit uint64) ([]*AuditEvent, error)
+}
+
+type auditEventQuery struct {
+	runner  *pgxpool.Pool
+	sq      squirrel.StatementBuilderType
+	hmacKey []byte
+	logger  *zap.Logger
+}
+
+func NewAuditEventQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, hmacKey []byte, logger *zap.Logger) AuditEventQuery {
+	return &auditEventQuery{
+		runner:  runner,
+		sq:      sq,
+		hmacKey: hmacKey,
+		logger:  logger,
+	}
+}
+
+// Append links the event to the last one of the chain and stores it. The
+// creation time is set here, rounded to what the database keeps. The new head
+// of the chain is logged, which keeps a record of it outside the database.
+func (a *auditEventQuery) Append(ctx context.Context, event *AuditEvent) (*AuditEvent, error) {
+	a.logger.Debug("Appending audit event", zap.String("type", event.Type))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, a.logger, a.runner)
+	if err != nil {
+		a.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	lastQb, lastArgs, err := a.sq.Select(AuditEventsHash).
+		From(AuditEventsTable).
+		OrderBy(AuditEventsID + " DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		a.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		a.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", auditChainLock); err != nil {
+		a.logger.Error("Failed to lock audit chain", zap.Error(err))
+		return nil, fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+	prevHash := AuditGenesisHash
+	err = tx.QueryRow(ctx, lastQb, lastArgs...).Scan(&prevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		a.logger.Error("Failed to fetch last audit event", zap.Error(err))
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	event.PrevHash = prevHash
+	event.Hash = event.ComputeHash(a.hmacKey)
+
+	insertMap, err := stomAuditEventInsert.ToMap(event)
+	if err != nil {
+		a.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := a.sq.Insert(AuditEventsTable).
+		SetMap(insertMap).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		a.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	err = pgxscan.Get(ctx, tx, event, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			a.logger.Warn("Database error",
+				zap.String("type", event.Type),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			a.logger.Error("Failed to insert audit event", zap.String("type", event.Type), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		a.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	a.logger.Info("Audit event appended successfully",
+		zap.Int64("event_id", event.ID),
+		zap.String("type", event.Type),
+		zap.String("hash", event.Hash))
+	return event, nil
+}
+
+func (a *auditEventQuery) List(ctx context.Context, filter *AuditEventFilter) ([]*AuditEvent, error) {
+	a.logger.Debug("Listing audit events", zap.Strings("types", filter.Types), zap.Int64("before_id", filter.BeforeID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, a.logger, a.runner)
+	if err != nil {
+		a.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	builder := a.sq.Select((&AuditEvent{}).columns("")...).
+		From(AuditEventsTable).
+		OrderBy(AuditEventsID + " DESC").
+		Limit(filter.Limit)
+	if len(filter.Types) > 0 {
+		builder = builder.Where(squirrel.Eq{AuditEventsType: filter.Types})
+	}
+	if filter.ActorID != 0 {
+		builder = builder.Where(squirrel.Eq{AuditEventsActorID: filter.ActorID})
+	}
+	if filter.SubjectID != 0 {
+		builder = builder.Where(squirrel.Eq{AuditEventsSubjectID: filter.SubjectID})
+	}
+	if filter.From != nil {
+		builder = builder.Where(squirrel.GtOrEq{AuditEventsCreatedAt: *filter.From})
+	}
+	if filter.To != nil {
+		builder = builder.Where(squirrel.Lt{AuditEventsCreatedAt: *filter.To})
+	}
+	if filter.BeforeID != 0 {
+		builder = builder.Where(squirrel.Lt{AuditEventsID: filter.BeforeID})
+	}
+
+	qb, args, err := builder.ToSql()
+	if err != nil {
+		a.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var events []*AuditEvent
+	err = pgxscan.Select(ctx, conn, &events, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			a.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			a.logger.Warn("Failed to list audit events", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	a.logger.Info("Audit events listed successfully", zap.Int("count", len(events)))
+	return events, nil
+}
+
+// ListChain returns events in chain order, starting after afterID.
+func (a *auditEventQuery) ListChain(ctx context.Context, afterID int64, limit uint64) ([]*AuditEvent, error) {
+	a.logger.Debug("Listing audit chain", zap.Int64("after_id", afterID), zap.Uint64("limit", limit))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, a.logger, a.runner)
+	if err != nil {
+		a.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := a.sq.Select((&AuditEvent{}).columns("")...).
+		From(AuditEventsTable).
+		Where(squirrel.Gt{AuditEventsID: afterID}).
+		OrderBy(AuditEventsID).
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		a.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var events []*AuditEvent
+	err = pgxscan.Select(ctx, conn, &events, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			a.logger.Warn("Database error",
+				zap.Int64("after_id", afterID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			a.logger.Warn("Failed to list audit chain", zap.Int64("after_id", afterID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	a.logger.Info("Audit chain listed successfully", zap.Int64("after_id", afterID), zap.Int("count", len(events)))
+	return events, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestAuditEventComputeHash(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	actorID := int64(7)
+	base := AuditEvent{
+		Type:      "auth.login",
+		ActorID:   &actorID,
+		IPAddress: "203.0.113.7",
+		UserAgent: "test",
+		Outcome:   AuditOutcomeSuccess,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+		PrevHash:  AuditGenesisHash,
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(AuditGenesisHash + "\n"))
+	mac.Write([]byte(`{"type":"auth.login","actor_id":7,"subject_id":null,"ip_address":"203.0.113.7","user_agent":"test","outcome":"success","reason":"","created_at":1704164645000006}`))
+	want := hex.EncodeToString(mac.Sum(nil))
+	if got := base.ComputeHash(key); got != want {
+		t.Fatalf("ComputeHash() = %s, want %s", got, want)
+	}
+
+	otherActorID := int64(8)
+	tests := []struct {
+		name   string
+		key    []byte
+		change func(e *AuditEvent)
+	}{
+		{name: "other key", key: []byte("fedcba9876543210fedcba9876543210")},
+		{name: "type", change: func(e *AuditEvent) { e.Type = "auth.logout" }},
+		{name: "actor", change: func(e *AuditEvent) { e.ActorID = &otherActorID }},
+		{name: "subject", change: func(e *AuditEvent) { e.SubjectID = &actorID }},
+		{name: "ip address", change: func(e *AuditEvent) { e.IPAddress = "198.51.100.1" }},
+		{name: "outcome", change: func(e *AuditEvent) { e.Outcome = AuditOutcomeFailure }},
+		{name: "reason", change: func(e *AuditEvent) { e.Reason = "bad password" }},
+		{name: "created at", change: func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{name: "previous hash", change: func(e *AuditEvent) { e.PrevHash = want }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, eventKey := base, key
+			if tt.key != nil {
+				eventKey = tt.key
+			}
+			if tt.change != nil {
+				tt.change(&event)
+			}
+			if got := event.ComputeHash(eventKey); got == want {
+				t.Errorf("ComputeHash() did not change")
+			}
+		})
+	}
+}
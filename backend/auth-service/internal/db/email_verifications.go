@@ -0,0 +1,287 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const EmailVerificationsTable = "email_verifications"
+
+const (
+	EmailVerificationsID        = "email_verifications_id_pk"
+	EmailVerificationsUserID    = "email_verifications_users_id_fk"
+	EmailVerificationsTokenHash = "email_verifications_token_hash"
+	EmailVerificationsExpiresAt = "email_verifications_expires_at"
+	EmailVerificationsUsedAt    = "email_verifications_used_at"
+	EmailVerificationsCreatedAt = "email_verifications_created_at"
+)
+
+// EmailVerification is a single-use token proving ownership of the user's
+// email. Only the SHA-256 hash of the token is stored.
+type EmailVerification struct {
+	ID        int64      `db:"email_verifications_id_pk"`
+	UserID    int64      `db:"email_verifications_users_id_fk" insert:"email_verifications_users_id_fk"`
+	TokenHash string     `db:"email_verifications_token_hash" insert:"email_verifications_token_hash"`
+	ExpiresAt time.Time  `db:"email_verifications_expires_at" insert:"email_verifications_expires_at"`
+	UsedAt    *time.Time `db:"email_verifications_used_at"`
+	CreatedAt *time.Time `db:"email_verifications_created_at"`
+}
+
+var (
+	stomEmailVerificationSelect = stom.MustNewStom(EmailVerification{}).SetTag(selectTag)
+	stomEmailVerificationInsert = stom.MustNewStom(EmailVerification{}).SetTag(insertTag)
+)
+
+func (e *EmailVerification) columns(pref string) []string {
+	return colNamesWithPref(stomEmailVerificationSelect.TagValues(), pref)
+}
+
+type EmailVerificationQuery interface {
+	GetByTokenHash(ctx context.Context, tokenHash string) (*EmailVerification, error)
+	GetLatestByUserID(ctx context.Context, userID int64) (*EmailVerification, error)
+	Insert(ctx context.Context, verification *EmailVerification) (*EmailVerification, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	DeleteByUserID(ctx context.Context, userID int64) (int64, error)
+}
+
+type emailVerificationQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewEmailVerificationQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) EmailVerificationQuery {
+	return &emailVerificationQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+func (e *emailVerificationQuery) GetByTokenHash(ctx context.Context, tokenHash string) (*EmailVerification, error) {
+	e.logger.Debug("Fetching email verification by token hash")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, e.logger, e.runner)
+	if err != nil {
+		e.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	verification := &EmailVerification{}
+	qb, args, err := e.sq.Select(verification.columns("")...).
+		From(EmailVerificationsTable).
+		Where(squirrel.Eq{EmailVerificationsTokenHash: tokenHash}).
+		ToSql()
+	if err != nil {
+		e.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, verification, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			e.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			e.logger.Warn("Failed to fetch email verification", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	e.logger.Info("Email verification fetched successfully", zap.Int64("verification_id", verification.ID))
+	return verification, nil
+}
+
+func (e *emailVerificationQuery) GetLatestByUserID(ctx context.Context, userID int64) (*EmailVerification, error) {
+	e.logger.Debug("Fetching latest email verification", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, e.logger, e.runner)
+	if err != nil {
+		e.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	verification := &EmailVerification{}
+	qb, args, err := e.sq.Select(verification.columns("")...).
+		From(EmailVerificationsTable).
+		Where(squirrel.Eq{EmailVerificationsUserID: userID}).
+		OrderBy(EmailVerificationsCreatedAt + " DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		e.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, verification, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			e.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			e.logger.Warn("Failed to fetch email verification", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	e.logger.Info("Email verification fetched successfully", zap.Int64("verification_id", verification.ID))
+	return verification, nil
+}
+
+func (e *emailVerificationQuery) Insert(ctx context.Context, verification *EmailVerification) (*EmailVerification, error) {
+	e.logger.Debug("Inserting email verification", zap.Int64("user_id", verification.UserID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, e.logger, e.runner)
+	if err != nil {
+		e.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	insertMap, err := stomEmailVerificationInsert.ToMap(verification)
+	if err != nil {
+		e.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := e.sq.Insert(EmailVerificationsTable).
+		SetMap(insertMap).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		e.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	err = pgxscan.Get(ctx, conn, verification, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			e.logger.Warn("Database error",
+				zap.Int64("user_id", verification.UserID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			e.logger.Error("Failed to insert email verification", zap.Int64("user_id", verification.UserID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	e.logger.Info("Email verification inserted successfully", zap.Int64("verification_id", verification.ID))
+	return verification, nil
+}
+
+// MarkUsed consumes the verification token. It reports false when the token was
+// already used or has expired in the meantime.
+func (e *emailVerificationQuery) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	e.logger.Debug("Marking email verification as used", zap.Int64("verification_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, e.logger, e.runner)
+	if err != nil {
+		e.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	now := time.Now()
+	qb, args, err := e.sq.Update(EmailVerificationsTable).
+		Set(EmailVerificationsUsedAt, now).
+		Where(squirrel.Eq{EmailVerificationsID: id, EmailVerificationsUsedAt: nil}).
+		Where(squirrel.Gt{EmailVerificationsExpiresAt: now}).
+		ToSql()
+	if err != nil {
+		e.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			e.logger.Warn("Database error",
+				zap.Int64("verification_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			e.logger.Error("Failed to mark email verification as used", zap.Int64("verification_id", id), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		e.logger.Warn("Email verification already used or expired", zap.Int64("verification_id", id))
+		return false, nil
+	}
+	e.logger.Info("Email verification marked as used", zap.Int64("verification_id", id))
+	return true, nil
+}
+
+func (e *emailVerificationQuery) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	e.logger.Debug("Deleting email verifications by user ID", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, e.logger, e.runner)
+	if err != nil {
+		e.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := e.sq.Delete(EmailVerificationsTable).
+		Where(squirrel.Eq{EmailVerificationsUserID: userID}).
+		ToSql()
+	if err != nil {
+		e.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			e.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			e.logger.Error("Failed to delete email verifications", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	e.logger.Info("Email verifications deleted successfully", zap.Int64("user_id", userID), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
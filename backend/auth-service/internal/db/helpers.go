@@ -2,19 +2,20 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
 const (
-	insertTag     = "insert"
-	selectTag     = "db"
-	updateTag     = "update"
-	updateAuthTag = "updateAuth"
+	insertTag = "insert"
+	selectTag = "db"
+	updateTag = "update"
 )
 
 func colNamesWithPref(cols []string, pref string) []string {
@@ -68,3 +69,20 @@ func acquireHealthyConn(ctx context.Context, logger *zap.Logger, runner *pgxpool
 	}
 	return nil, fmt.Errorf("failed to find healthy connection after %d attempts", maxAttempts)
 }
+
+// IsUniqueViolation reports whether err is caused by a unique constraint.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// ViolatedConstraint returns the name of the constraint err violates, or "".
+// Postgres names unique constraints after their table and columns, like
+// users_users_email_key.
+func ViolatedConstraint(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
@@ -3,17 +3,50 @@ package db
 type Implementation interface {
 	UserQuery() UserQuery
 	RoleQuery() RoleQuery
+	SessionQuery() SessionQuery
+	PasswordResetQuery() PasswordResetQuery
+	EmailVerificationQuery() EmailVerificationQuery
+	RecoveryCodeQuery() RecoveryCodeQuery
+	LoginAttemptQuery() LoginAttemptQuery
+	SigningKeyQuery() SigningKeyQuery
+	PermissionQuery() PermissionQuery
+	UserStatusChangeQuery() UserStatusChangeQuery
+	AuditEventQuery() AuditEventQuery
+	OutboxEventQuery() OutboxEventQuery
+	MFAChallengeQuery() MFAChallengeQuery
 }
 
 type implementation struct {
-	userQuery UserQuery
-	roleQuery RoleQuery
+	userQuery              UserQuery
+	roleQuery              RoleQuery
+	sessionQuery           SessionQuery
+	passwordResetQuery     PasswordResetQuery
+	emailVerificationQuery EmailVerificationQuery
+	recoveryCodeQuery      RecoveryCodeQuery
+	loginAttemptQuery      LoginAttemptQuery
+	signingKeyQuery        SigningKeyQuery
+	permissionQuery        PermissionQuery
+	userStatusChangeQuery  UserStatusChangeQuery
+	auditEventQuery        AuditEventQuery
+	outboxEventQuery       OutboxEventQuery
+	mfaChallengeQuery      MFAChallengeQuery
 }
 
-func NewImplementation(userQuery UserQuery, roleQuery RoleQuery) Implementation {
+func NewImplementation(userQuery UserQuery, roleQuery RoleQuery, sessionQuery SessionQuery, passwordResetQuery PasswordResetQuery, emailVerificationQuery EmailVerificationQuery, recoveryCodeQuery RecoveryCodeQuery, loginAttemptQuery LoginAttemptQuery, signingKeyQuery SigningKeyQuery, permissionQuery PermissionQuery, userStatusChangeQuery UserStatusChangeQuery, auditEventQuery AuditEventQuery, outboxEventQuery OutboxEventQuery, mfaChallengeQuery MFAChallengeQuery) Implementation {
 	return &implementation{
-		userQuery: userQuery,
-		roleQuery: roleQuery,
+		userQuery:              userQuery,
+		roleQuery:              roleQuery,
+		sessionQuery:           sessionQuery,
+		passwordResetQuery:     passwordResetQuery,
+		emailVerificationQuery: emailVerificationQuery,
+		recoveryCodeQuery:      recoveryCodeQuery,
+		loginAttemptQuery:      loginAttemptQuery,
+		signingKeyQuery:        signingKeyQuery,
+		permissionQuery:        permissionQuery,
+		userStatusChangeQuery:  userStatusChangeQuery,
+		auditEventQuery:        auditEventQuery,
+		outboxEventQuery:       outboxEventQuery,
+		mfaChallengeQuery:      mfaChallengeQuery,
 	}
 }
 
@@ -24,3 +57,47 @@ func (i *implementation) UserQuery() UserQuery {
 func (i *implementation) RoleQuery() RoleQuery {
 	return i.roleQuery
 }
+
+func (i *implementation) SessionQuery() SessionQuery {
+	return i.sessionQuery
+}
+
+func (i *implementation) PasswordResetQuery() PasswordResetQuery {
+	return i.passwordResetQuery
+}
+
+func (i *implementation) EmailVerificationQuery() EmailVerificationQuery {
+	return i.emailVerificationQuery
+}
+
+func (i *implementation) RecoveryCodeQuery() RecoveryCodeQuery {
+	return i.recoveryCodeQuery
+}
+
+func (i *implementation) LoginAttemptQuery() LoginAttemptQuery {
+	return i.loginAttemptQuery
+}
+
+func (i *implementation) SigningKeyQuery() SigningKeyQuery {
+	return i.signingKeyQuery
+}
+
+func (i *implementation) PermissionQuery() PermissionQuery {
+	return i.permissionQuery
+}
+
+func (i *implementation) UserStatusChangeQuery() UserStatusChangeQuery {
+	return i.userStatusChangeQuery
+}
+
+func (i *implementation) AuditEventQuery() AuditEventQuery {
+	return i.auditEventQuery
+}
+
+func (i *implementation) OutboxEventQuery() OutboxEventQuery {
+	return i.outboxEventQuery
+}
+
+func (i *implementation) MFAChallengeQuery() MFAChallengeQuery {
+	return i.mfaChallengeQuery
+}
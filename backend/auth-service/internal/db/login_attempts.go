@@ -0,0 +1,251 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const LoginAttemptsTable = "login_attempts"
+
+const (
+	LoginAttemptsID            = "login_attempts_id_pk"
+	LoginAttemptsScope         = "login_attempts_scope"
+	LoginAttemptsKey           = "login_attempts_key"
+	LoginAttemptsFailures      = "login_attempts_failures"
+	LoginAttemptsLastFailureAt = "login_attempts_last_failure_at"
+	LoginAttemptsLockedUntil   = "login_attempts_locked_until"
+)
+
+// Scopes of failed login tracking: per account (keyed by username) and per
+// client address.
+const (
+	LoginAttemptScopeAccount = "account"
+	LoginAttemptScopeIP      = "ip"
+)
+
+// LoginAttempt counts consecutive failed logins for an account or a client
+// address and holds the lockout derived from them.
+type LoginAttempt struct {
+	ID            int64      `db:"login_attempts_id_pk"`
+	Scope         string     `db:"login_attempts_scope" insert:"login_attempts_scope"`
+	Key           string     `db:"login_attempts_key" insert:"login_attempts_key"`
+	Failures      int        `db:"login_attempts_failures" insert:"login_attempts_failures"`
+	LastFailureAt time.Time  `db:"login_attempts_last_failure_at" insert:"login_attempts_last_failure_at"`
+	LockedUntil   *time.Time `db:"login_attempts_locked_until"`
+}
+
+var (
+	stomLoginAttemptSelect = stom.MustNewStom(LoginAttempt{}).SetTag(selectTag)
+	stomLoginAttemptInsert = stom.MustNewStom(LoginAttempt{}).SetTag(insertTag)
+)
+
+func (l *LoginAttempt) columns(pref string) []string {
+	return colNamesWithPref(stomLoginAttemptSelect.TagValues(), pref)
+}
+
+type LoginAttemptQuery interface {
+	Get(ctx context.Context, scope, key string) (*LoginAttempt, error)
+	RegisterFailure(ctx context.Context, scope, key string, window time.Duration) (*LoginAttempt, error)
+	Lock(ctx context.Context, scope, key string, until time.Time) error
+	Delete(ctx context.Context, scope, key string) (int64, error)
+}
+
+type loginAttemptQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewLoginAttemptQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) LoginAttemptQuery {
+	return &loginAttemptQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+func (l *loginAttemptQuery) Get(ctx context.Context, scope, key string) (*LoginAttempt, error) {
+	l.logger.Debug("Fetching login attempts", zap.String("scope", scope), zap.String("key", key))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, l.logger, l.runner)
+	if err != nil {
+		l.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	attempt := &LoginAttempt{}
+	qb, args, err := l.sq.Select(attempt.columns("")...).
+		From(LoginAttemptsTable).
+		Where(squirrel.Eq{LoginAttemptsScope: scope, LoginAttemptsKey: key}).
+		ToSql()
+	if err != nil {
+		l.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, attempt, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			l.logger.Warn("Database error",
+				zap.String("scope", scope),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			l.logger.Warn("Failed to fetch login attempts", zap.String("scope", scope), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	l.logger.Info("Login attempts fetched successfully", zap.String("scope", scope), zap.Int("failures", attempt.Failures))
+	return attempt, nil
+}
+
+// RegisterFailure atomically counts a failed login. The counter starts over
+// when the previous failure is older than window.
+func (l *loginAttemptQuery) RegisterFailure(ctx context.Context, scope, key string, window time.Duration) (*LoginAttempt, error) {
+	l.logger.Debug("Registering failed login", zap.String("scope", scope), zap.String("key", key))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, l.logger, l.runner)
+	if err != nil {
+		l.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	now := time.Now()
+	attempt := &LoginAttempt{Scope: scope, Key: key, Failures: 1, LastFailureAt: now}
+	insertMap, err := stomLoginAttemptInsert.ToMap(attempt)
+	if err != nil {
+		l.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := l.sq.Insert(LoginAttemptsTable).
+		SetMap(insertMap).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (%[1]s, %[2]s) DO UPDATE SET "+
+				"%[3]s = CASE WHEN %[5]s.%[4]s < ? THEN 1 ELSE %[5]s.%[3]s + 1 END, "+
+				"%[4]s = EXCLUDED.%[4]s RETURNING *",
+			LoginAttemptsScope, LoginAttemptsKey, LoginAttemptsFailures, LoginAttemptsLastFailureAt, LoginAttemptsTable,
+		), now.Add(-window)).
+		ToSql()
+	if err != nil {
+		l.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, attempt, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			l.logger.Warn("Database error",
+				zap.String("scope", scope),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			l.logger.Error("Failed to register failed login", zap.String("scope", scope), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	l.logger.Info("Failed login registered", zap.String("scope", scope), zap.Int("failures", attempt.Failures))
+	return attempt, nil
+}
+
+func (l *loginAttemptQuery) Lock(ctx context.Context, scope, key string, until time.Time) error {
+	l.logger.Debug("Locking login", zap.String("scope", scope), zap.String("key", key), zap.Time("until", until))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, l.logger, l.runner)
+	if err != nil {
+		l.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := l.sq.Update(LoginAttemptsTable).
+		Set(LoginAttemptsLockedUntil, until).
+		Where(squirrel.Eq{LoginAttemptsScope: scope, LoginAttemptsKey: key}).
+		ToSql()
+	if err != nil {
+		l.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	_, err = conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			l.logger.Warn("Database error",
+				zap.String("scope", scope),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			l.logger.Error("Failed to lock login", zap.String("scope", scope), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	l.logger.Info("Login locked", zap.String("scope", scope), zap.Time("until", until))
+	return nil
+}
+
+// Delete clears the failure counter and any lockout.
+func (l *loginAttemptQuery) Delete(ctx context.Context, scope, key string) (int64, error) {
+	l.logger.Debug("Deleting login attempts", zap.String("scope", scope), zap.String("key", key))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, l.logger, l.runner)
+	if err != nil {
+		l.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := l.sq.Delete(LoginAttemptsTable).
+		Where(squirrel.Eq{LoginAttemptsScope: scope, LoginAttemptsKey: key}).
+		ToSql()
+	if err != nil {
+		l.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			l.logger.Warn("Database error",
+				zap.String("scope", scope),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			l.logger.Error("Failed to delete login attempts", zap.String("scope", scope), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	l.logger.Info("Login attempts deleted successfully", zap.String("scope", scope), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const MFAChallengesTable = "mfa_challenges"
+
+const (
+	MFAChallengesJTI       = "mfa_challenges_jti_pk"
+	MFAChallengesUserID    = "mfa_challenges_users_id_fk"
+	MFAChallengesFailures  = "mfa_challenges_failures"
+	MFAChallengesExpiresAt = "mfa_challenges_expires_at"
+	MFAChallengesCreatedAt = "mfa_challenges_created_at"
+)
+
+// MFAChallengeQuery tracks the MFA tokens handed out by Login, keyed by their
+// JTI, so that each token completes at most one login and allows a limited
+// number of wrong codes.
+type MFAChallengeQuery interface {
+	Insert(ctx context.Context, jti string, userID int64, expiresAt time.Time) error
+	Exists(ctx context.Context, jti string, userID int64) (bool, error)
+	RegisterFailure(ctx context.Context, jti string) (int, error)
+	Use(ctx context.Context, jti string, userID int64) (bool, error)
+}
+
+type mfaChallengeQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewMFAChallengeQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) MFAChallengeQuery {
+	return &mfaChallengeQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+// Insert records a new challenge. Expired challenges of every user are
+// deleted on the way.
+func (m *mfaChallengeQuery) Insert(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	m.logger.Debug("Inserting MFA challenge", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, m.logger, m.runner)
+	if err != nil {
+		m.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	deleteQb, deleteArgs, err := m.sq.Delete(MFAChallengesTable).
+		Where(squirrel.Lt{MFAChallengesExpiresAt: time.Now()}).
+		ToSql()
+	if err != nil {
+		m.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	insertQb, insertArgs, err := m.sq.Insert(MFAChallengesTable).
+		Columns(MFAChallengesJTI, MFAChallengesUserID, MFAChallengesExpiresAt).
+		Values(jti, userID, expiresAt).
+		ToSql()
+	if err != nil {
+		m.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		m.logger.Error("Failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, deleteQb, deleteArgs...); err != nil {
+		m.logger.Error("Failed to delete expired MFA challenges", zap.Error(err))
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, insertQb, insertArgs...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			m.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			m.logger.Error("Failed to insert MFA challenge", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		m.logger.Error("Failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	m.logger.Info("MFA challenge inserted successfully", zap.Int64("user_id", userID))
+	return nil
+}
+
+// Exists reports whether the challenge is still open.
+func (m *mfaChallengeQuery) Exists(ctx context.Context, jti string, userID int64) (bool, error) {
+	m.logger.Debug("Checking MFA challenge", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, m.logger, m.runner)
+	if err != nil {
+		m.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := m.sq.Select("1").
+		From(MFAChallengesTable).
+		Where(squirrel.Eq{MFAChallengesJTI: jti, MFAChallengesUserID: userID}).
+		Where(squirrel.Gt{MFAChallengesExpiresAt: time.Now()}).
+		ToSql()
+	if err != nil {
+		m.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var one int
+	err = conn.QueryRow(ctx, qb, args...).Scan(&one)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			m.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			m.logger.Error("Failed to check MFA challenge", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+	return true, nil
+}
+
+// RegisterFailure counts a wrong code against the challenge and returns the
+// failures so far, or 0 when the challenge is gone.
+func (m *mfaChallengeQuery) RegisterFailure(ctx context.Context, jti string) (int, error) {
+	m.logger.Debug("Registering failed MFA attempt")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, m.logger, m.runner)
+	if err != nil {
+		m.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := m.sq.Update(MFAChallengesTable).
+		Set(MFAChallengesFailures, squirrel.Expr(MFAChallengesFailures+" + 1")).
+		Where(squirrel.Eq{MFAChallengesJTI: jti}).
+		Suffix("RETURNING " + MFAChallengesFailures).
+		ToSql()
+	if err != nil {
+		m.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var failures int
+	err = conn.QueryRow(ctx, qb, args...).Scan(&failures)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			m.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			m.logger.Error("Failed to register failed MFA attempt", zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	m.logger.Info("Failed MFA attempt registered", zap.Int("failures", failures))
+	return failures, nil
+}
+
+// Use closes the challenge. It reports false when the challenge does not
+// exist, has expired or was already closed, which makes the MFA token
+// single-use even with concurrent requests.
+func (m *mfaChallengeQuery) Use(ctx context.Context, jti string, userID int64) (bool, error) {
+	m.logger.Debug("Using MFA challenge", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, m.logger, m.runner)
+	if err != nil {
+		m.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := m.sq.Delete(MFAChallengesTable).
+		Where(squirrel.Eq{MFAChallengesJTI: jti, MFAChallengesUserID: userID}).
+		Where(squirrel.Gt{MFAChallengesExpiresAt: time.Now()}).
+		ToSql()
+	if err != nil {
+		m.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			m.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			m.logger.Error("Failed to use MFA challenge", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		m.logger.Warn("MFA challenge not found or already used", zap.Int64("user_id", userID))
+		return false, nil
+	}
+	m.logger.Info("MFA challenge used", zap.Int64("user_id", userID))
+	return true, nil
+}
@@ -0,0 +1,302 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const OutboxEventsTable = "outbox_events"
+
+const (
+	OutboxEventsID            = "outbox_events_id_pk"
+	OutboxEventsType          = "outbox_events_type"
+	OutboxEventsUserID        = "outbox_events_user_id"
+	OutboxEventsPayload       = "outbox_events_payload"
+	OutboxEventsAttempts      = "outbox_events_attempts"
+	OutboxEventsNextAttemptAt = "outbox_events_next_attempt_at"
+	OutboxEventsLastError     = "outbox_events_last_error"
+	OutboxEventsPublishedAt   = "outbox_events_published_at"
+	OutboxEventsCreatedAt     = "outbox_events_created_at"
+)
+
+// User lifecycle events published through the outbox.
+const (
+	OutboxUserRegistered   = "user.registered"
+	OutboxUserDeleted      = "user.deleted"
+	OutboxUserRoleChanged  = "user.role_changed"
+	OutboxUserEmailChanged = "user.email_changed"
+)
+
+// OutboxEvent is a change waiting to be published to other services. It is
+// written in the transaction that makes the change, so an event exists if and
+// only if the change was committed. The payload is the JSON state of the user
+// right after the change.
+type OutboxEvent struct {
+	ID            int64      `db:"outbox_events_id_pk"`
+	Type          string     `db:"outbox_events_type"`
+	UserID        int64      `db:"outbox_events_user_id"`
+	Payload       []byte     `db:"outbox_events_payload"`
+	Attempts      int        `db:"outbox_events_attempts"`
+	NextAttemptAt time.Time  `db:"outbox_events_next_attempt_at"`
+	LastError     *string    `db:"outbox_events_last_error"`
+	PublishedAt   *time.Time `db:"outbox_events_published_at"`
+	CreatedAt     time.Time  `db:"outbox_events_created_at"`
+}
+
+var stomOutboxEventSelect = stom.MustNewStom(OutboxEvent{}).SetTag(selectTag)
+
+func (e *OutboxEvent) columns(pref string) []string {
+	return colNamesWithPref(stomOutboxEventSelect.TagValues(), pref)
+}
+
+type OutboxEventQuery interface {
+	Claim(ctx context.Context, limit uint64, lease time.Duration) ([]*OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxEventQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewOutboxEventQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) OutboxEventQuery {
+	return &outboxEventQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+// queueUserEvents writes an event of eventType for every user matched by
+// where. It belongs in the transaction that changed the users, after the
+// change, so that the payload carries their new state.
+func queueUserEvents(ctx context.Context, tx pgx.Tx, sq squirrel.StatementBuilderType, logger *zap.Logger, eventType string, where squirrel.Sqlizer) error {
+	payload := "json_build_object(" +
+		"'user_id', " + UsersID + ", " +
+		"'username', " + UsersUsername + ", " +
+		"'email', " + UsersEmail + ", " +
+		"'email_verified', " + UsersEmailVerified + ", " +
+		"'role', " + RolesName + ", " +
+		"'status', " + UsersStatus + ")"
+	users := squirrel.Select().
+		Column("?::text", eventType).
+		Column(UsersID).
+		Column(payload).
+		From(UsersTable).
+		Join(RolesTable + " ON " + RolesID + " = " + UsersRoleID).
+		Where(where)
+	qb, args, err := sq.Insert(OutboxEventsTable).
+		Columns(OutboxEventsType, OutboxEventsUserID, OutboxEventsPayload).
+		Select(users).
+		ToSql()
+	if err != nil {
+		logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, qb, args...); err != nil {
+		logger.Error("Failed to queue user events", zap.String("type", eventType), zap.Error(err))
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	return nil
+}
+
+// Claim takes up to limit events that are due and leases them for lease:
+// other relays skip them until the lease runs out, so an event whose relay
+// dies before reporting back is retried. An event is held back while an
+// earlier one for the same user is unpublished, which keeps the events of a
+// user in order. The events are returned oldest first.
+func (o *outboxEventQuery) Claim(ctx context.Context, limit uint64, lease time.Duration) ([]*OutboxEvent, error) {
+	o.logger.Debug("Claiming outbox events", zap.Uint64("limit", limit))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, o.logger, o.runner)
+	if err != nil {
+		o.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	now := time.Now()
+	earlier := squirrel.Select("1").
+		From(OutboxEventsTable + " earlier").
+		Where("earlier." + OutboxEventsUserID + " = due." + OutboxEventsUserID).
+		Where("earlier." + OutboxEventsPublishedAt + " IS NULL").
+		Where("earlier." + OutboxEventsID + " < due." + OutboxEventsID)
+	due := squirrel.Select("due." + OutboxEventsID).
+		From(OutboxEventsTable + " due").
+		Where("due." + OutboxEventsPublishedAt + " IS NULL").
+		Where(squirrel.LtOrEq{"due." + OutboxEventsNextAttemptAt: now}).
+		Where(earlier.Prefix("NOT EXISTS (").Suffix(")")).
+		OrderBy("due." + OutboxEventsID).
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+	qb, args, err := o.sq.Update(OutboxEventsTable).
+		Set(OutboxEventsAttempts, squirrel.Expr(OutboxEventsAttempts+" + 1")).
+		Set(OutboxEventsNextAttemptAt, now.Add(lease)).
+		Where(due.Prefix(OutboxEventsID + " IN (").Suffix(")")).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		o.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var events []*OutboxEvent
+	err = pgxscan.Select(ctx, conn, &events, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			o.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			o.logger.Error("Failed to claim outbox events", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	slices.SortFunc(events, func(a, b *OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	if len(events) > 0 {
+		o.logger.Info("Outbox events claimed successfully", zap.Int("count", len(events)))
+	}
+	return events, nil
+}
+
+func (o *outboxEventQuery) MarkPublished(ctx context.Context, id int64) error {
+	o.logger.Debug("Marking outbox event as published", zap.Int64("event_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, o.logger, o.runner)
+	if err != nil {
+		o.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := o.sq.Update(OutboxEventsTable).
+		Set(OutboxEventsPublishedAt, time.Now()).
+		Set(OutboxEventsLastError, nil).
+		Where(squirrel.Eq{OutboxEventsID: id}).
+		ToSql()
+	if err != nil {
+		o.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, qb, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			o.logger.Warn("Database error",
+				zap.Int64("event_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			o.logger.Error("Failed to mark outbox event as published", zap.Int64("event_id", id), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	o.logger.Info("Outbox event marked as published", zap.Int64("event_id", id))
+	return nil
+}
+
+// MarkFailed records a failed delivery and schedules the next attempt.
+func (o *outboxEventQuery) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	o.logger.Debug("Marking outbox event as failed", zap.Int64("event_id", id), zap.Time("next_attempt_at", nextAttemptAt))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, o.logger, o.runner)
+	if err != nil {
+		o.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := o.sq.Update(OutboxEventsTable).
+		Set(OutboxEventsNextAttemptAt, nextAttemptAt).
+		Set(OutboxEventsLastError, lastError).
+		Where(squirrel.Eq{OutboxEventsID: id}).
+		ToSql()
+	if err != nil {
+		o.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, qb, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			o.logger.Warn("Database error",
+				zap.Int64("event_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			o.logger.Error("Failed to mark outbox event as failed", zap.Int64("event_id", id), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	o.logger.Info("Outbox event marked as failed", zap.Int64("event_id", id))
+	return nil
+}
+
+func (o *outboxEventQuery) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	o.logger.Debug("Deleting published outbox events", zap.Time("before", before))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, o.logger, o.runner)
+	if err != nil {
+		o.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := o.sq.Delete(OutboxEventsTable).
+		Where(squirrel.Lt{OutboxEventsPublishedAt: before}).
+		ToSql()
+	if err != nil {
+		o.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			o.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			o.logger.Error("Failed to delete published outbox events", zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	deleted := result.RowsAffected()
+	o.logger.Info("Published outbox events deleted successfully", zap.Int64("count", deleted))
+	return deleted, nil
+}
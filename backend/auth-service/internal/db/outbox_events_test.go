@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestOutboxEventClaim(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	query := NewOutboxEventQuery(pool, testSQ, zap.NewNop())
+
+	// Two events of user 1 and one of user 2, in this order.
+	for _, userID := range []int64{1, 1, 2} {
+		if _, err := pool.Exec(ctx,
+			"INSERT INTO "+OutboxEventsTable+" ("+OutboxEventsType+", "+OutboxEventsUserID+", "+OutboxEventsPayload+") VALUES ($1, $2, '{}')",
+			OutboxUserRegistered, userID); err != nil {
+			t.Fatalf("failed to insert outbox event: %v", err)
+		}
+	}
+	claim := func() []*OutboxEvent {
+		t.Helper()
+		events, err := query.Claim(ctx, 10, time.Minute)
+		if err != nil {
+			t.Fatalf("Claim() error = %v", err)
+		}
+		return events
+	}
+	ids := func(events []*OutboxEvent) []int64 {
+		var ids []int64
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return ids
+	}
+
+	// The second event of user 1 waits for the first one.
+	events := claim()
+	if !slices.Equal(ids(events), []int64{1, 3}) {
+		t.Fatalf("Claim() = %v, want [1 3]", ids(events))
+	}
+	if events[0].Attempts != 1 {
+		t.Errorf("Claim() attempts = %d, want 1", events[0].Attempts)
+	}
+	if events := claim(); len(events) != 0 {
+		t.Fatalf("Claim() of leased events = %v, want none", ids(events))
+	}
+
+	// A failed event is claimed again once it is due and still holds back
+	// the next event of its user.
+	if err := query.MarkFailed(ctx, 1, time.Now().Add(-time.Second), "consumer down"); err != nil {
+		t.Fatalf("MarkFailed() error = %v", err)
+	}
+	events = claim()
+	if !slices.Equal(ids(events), []int64{1}) {
+		t.Fatalf("Claim() after a failure = %v, want [1]", ids(events))
+	}
+	if events[0].Attempts != 2 || events[0].LastError == nil || *events[0].LastError != "consumer down" {
+		t.Errorf("Claim() after a failure = attempts %d, last error %v", events[0].Attempts, events[0].LastError)
+	}
+
+	// Publishing it releases the next event of the user.
+	if err := query.MarkPublished(ctx, 1); err != nil {
+		t.Fatalf("MarkPublished() error = %v", err)
+	}
+	if events := claim(); !slices.Equal(ids(events), []int64{2}) {
+		t.Errorf("Claim() after publishing = %v, want [2]", ids(events))
+	}
+}
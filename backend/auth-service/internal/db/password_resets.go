@@ -0,0 +1,290 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const PasswordResetsTable = "password_resets"
+
+const (
+	PasswordResetsID        = "password_resets_id_pk"
+	PasswordResetsUserID    = "password_resets_users_id_fk"
+	PasswordResetsTokenHash = "password_resets_token_hash"
+	PasswordResetsExpiresAt = "password_resets_expires_at"
+	PasswordResetsUsedAt    = "password_resets_used_at"
+	PasswordResetsCreatedAt = "password_resets_created_at"
+)
+
+// PasswordReset is a single-use reset token. Only the SHA-256 hash of the
+// token is stored.
+type PasswordReset struct {
+	ID        int64      `db:"password_resets_id_pk"`
+	UserID    int64      `db:"password_resets_users_id_fk" insert:"password_resets_users_id_fk"`
+	TokenHash string     `db:"password_resets_token_hash" insert:"password_resets_token_hash"`
+	ExpiresAt time.Time  `db:"password_resets_expires_at" insert:"password_resets_expires_at"`
+	UsedAt    *time.Time `db:"password_resets_used_at"`
+	CreatedAt *time.Time `db:"password_resets_created_at"`
+}
+
+var (
+	stomPasswordResetSelect = stom.MustNewStom(PasswordReset{}).SetTag(selectTag)
+	stomPasswordResetInsert = stom.MustNewStom(PasswordReset{}).SetTag(insertTag)
+)
+
+func (p *PasswordReset) columns(pref string) []string {
+	return colNamesWithPref(stomPasswordResetSelect.TagValues(), pref)
+}
+
+type PasswordResetQuery interface {
+	GetByTokenHash(ctx context.Context, tokenHash string) (*PasswordReset, error)
+	GetLatestActiveByUserID(ctx context.Context, userID int64) (*PasswordReset, error)
+	Insert(ctx context.Context, reset *PasswordReset) (*PasswordReset, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	DeleteByUserID(ctx context.Context, userID int64) (int64, error)
+}
+
+type passwordResetQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewPasswordResetQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) PasswordResetQuery {
+	return &passwordResetQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+func (p *passwordResetQuery) GetByTokenHash(ctx context.Context, tokenHash string) (*PasswordReset, error) {
+	p.logger.Debug("Fetching password reset by token hash")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	reset := &PasswordReset{}
+	qb, args, err := p.sq.Select(reset.columns("")...).
+		From(PasswordResetsTable).
+		Where(squirrel.Eq{PasswordResetsTokenHash: tokenHash}).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, reset, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Warn("Failed to fetch password reset", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Password reset fetched successfully", zap.Int64("reset_id", reset.ID))
+	return reset, nil
+}
+
+// GetLatestActiveByUserID returns the newest reset token of the user that is
+// neither used nor expired, or nil.
+func (p *passwordResetQuery) GetLatestActiveByUserID(ctx context.Context, userID int64) (*PasswordReset, error) {
+	p.logger.Debug("Fetching latest active password reset", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	reset := &PasswordReset{}
+	qb, args, err := p.sq.Select(reset.columns("")...).
+		From(PasswordResetsTable).
+		Where(squirrel.Eq{PasswordResetsUserID: userID, PasswordResetsUsedAt: nil}).
+		Where(squirrel.Gt{PasswordResetsExpiresAt: time.Now()}).
+		OrderBy(PasswordResetsCreatedAt + " DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, reset, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Warn("Failed to fetch password reset", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Password reset fetched successfully", zap.Int64("reset_id", reset.ID))
+	return reset, nil
+}
+
+func (p *passwordResetQuery) Insert(ctx context.Context, reset *PasswordReset) (*PasswordReset, error) {
+	p.logger.Debug("Inserting password reset", zap.Int64("user_id", reset.UserID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	insertMap, err := stomPasswordResetInsert.ToMap(reset)
+	if err != nil {
+		p.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := p.sq.Insert(PasswordResetsTable).
+		SetMap(insertMap).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	err = pgxscan.Get(ctx, conn, reset, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("user_id", reset.UserID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to insert password reset", zap.Int64("user_id", reset.UserID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Password reset inserted successfully", zap.Int64("reset_id", reset.ID))
+	return reset, nil
+}
+
+// MarkUsed consumes the reset token. It reports false when the token was
+// already used or has expired in the meantime.
+func (p *passwordResetQuery) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	p.logger.Debug("Marking password reset as used", zap.Int64("reset_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	now := time.Now()
+	qb, args, err := p.sq.Update(PasswordResetsTable).
+		Set(PasswordResetsUsedAt, now).
+		Where(squirrel.Eq{PasswordResetsID: id, PasswordResetsUsedAt: nil}).
+		Where(squirrel.Gt{PasswordResetsExpiresAt: now}).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("reset_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to mark password reset as used", zap.Int64("reset_id", id), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		p.logger.Warn("Password reset already used or expired", zap.Int64("reset_id", id))
+		return false, nil
+	}
+	p.logger.Info("Password reset marked as used", zap.Int64("reset_id", id))
+	return true, nil
+}
+
+func (p *passwordResetQuery) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	p.logger.Debug("Deleting password resets by user ID", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := p.sq.Delete(PasswordResetsTable).
+		Where(squirrel.Eq{PasswordResetsUserID: userID}).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to delete password resets", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	p.logger.Info("Password resets deleted successfully", zap.Int64("user_id", userID), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
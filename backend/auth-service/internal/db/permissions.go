@@ -0,0 +1,370 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	PermissionsTable     = "permissions"
+	RolePermissionsTable = "role_permissions"
+)
+
+const (
+	PermissionsID          = "permissions_id_pk"
+	PermissionsName        = "permissions_name"
+	PermissionsDescription = "permissions_descr"
+
+	RolePermissionsRoleID       = "role_permissions_roles_id_fk"
+	RolePermissionsPermissionID = "role_permissions_permissions_id_fk"
+)
+
+// Permission is a named capability such as "tests:author". Roles are granted
+// permissions through the role_permissions table.
+type Permission struct {
+	ID          int64  `db:"permissions_id_pk"`
+	Name        string `db:"permissions_name" insert:"permissions_name"`
+	Description string `db:"permissions_descr" insert:"permissions_descr"`
+}
+
+var (
+	stomPermissionSelect = stom.MustNewStom(Permission{}).SetTag(selectTag)
+	stomPermissionInsert = stom.MustNewStom(Permission{}).SetTag(insertTag)
+)
+
+func (p *Permission) columns(pref string) []string {
+	return colNamesWithPref(stomPermissionSelect.TagValues(), pref)
+}
+
+type PermissionQuery interface {
+	GetByName(ctx context.Context, name string) (*Permission, error)
+	List(ctx context.Context) ([]*Permission, error)
+	ListByRoleID(ctx context.Context, roleID int64) ([]*Permission, error)
+	Insert(ctx context.Context, permission *Permission) (*Permission, error)
+	Delete(ctx context.Context, id int64) (bool, error)
+	Grant(ctx context.Context, roleID, permissionID int64) (bool, error)
+	Revoke(ctx context.Context, roleID, permissionID int64) (bool, error)
+}
+
+type permissionQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewPermissionQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) PermissionQuery {
+	return &permissionQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+func (p *permissionQuery) GetByName(ctx context.Context, name string) (*Permission, error) {
+	p.logger.Debug("Fetching permission by name", zap.String("name", name))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	permission := &Permission{}
+	qb, args, err := p.sq.Select(permission.columns("")...).
+		From(PermissionsTable).
+		Where(squirrel.Eq{PermissionsName: name}).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, permission, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.String("name", name),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Warn("Failed to fetch permission", zap.String("name", name), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Permission fetched successfully", zap.String("name", name))
+	return permission, nil
+}
+
+func (p *permissionQuery) List(ctx context.Context) ([]*Permission, error) {
+	p.logger.Debug("Listing permissions")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var permissions []*Permission
+	qb, args, err := p.sq.Select((&Permission{}).columns("")...).
+		From(PermissionsTable).
+		OrderBy(PermissionsName).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, conn, &permissions, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Warn("Failed to list permissions", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Permissions listed successfully", zap.Int("count", len(permissions)))
+	return permissions, nil
+}
+
+func (p *permissionQuery) ListByRoleID(ctx context.Context, roleID int64) ([]*Permission, error) {
+	p.logger.Debug("Listing permissions by role ID", zap.Int64("role_id", roleID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var permissions []*Permission
+	qb, args, err := p.sq.Select((&Permission{}).columns("p")...).
+		From(PermissionsTable + " p").
+		Join(fmt.Sprintf("%s rp ON rp.%s = p.%s", RolePermissionsTable, RolePermissionsPermissionID, PermissionsID)).
+		Where(squirrel.Eq{"rp." + RolePermissionsRoleID: roleID}).
+		OrderBy("p." + PermissionsName).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, conn, &permissions, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("role_id", roleID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Warn("Failed to list role permissions", zap.Int64("role_id", roleID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Role permissions listed successfully", zap.Int64("role_id", roleID), zap.Int("count", len(permissions)))
+	return permissions, nil
+}
+
+func (p *permissionQuery) Insert(ctx context.Context, permission *Permission) (*Permission, error) {
+	p.logger.Debug("Inserting permission", zap.String("name", permission.Name))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	insertMap, err := stomPermissionInsert.ToMap(permission)
+	if err != nil {
+		p.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := p.sq.Insert(PermissionsTable).
+		SetMap(insertMap).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	err = pgxscan.Get(ctx, conn, permission, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.String("name", permission.Name),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to insert permission", zap.String("name", permission.Name), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	p.logger.Info("Permission inserted successfully", zap.Int64("permission_id", permission.ID))
+	return permission, nil
+}
+
+// Delete removes the permission together with all its grants.
+func (p *permissionQuery) Delete(ctx context.Context, id int64) (bool, error) {
+	p.logger.Debug("Deleting permission", zap.Int64("permission_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := p.sq.Delete(PermissionsTable).
+		Where(squirrel.Eq{PermissionsID: id}).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("permission_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to delete permission", zap.Int64("permission_id", id), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	deleted := result.RowsAffected() > 0
+	p.logger.Info("Permission deleted", zap.Int64("permission_id", id), zap.Bool("deleted", deleted))
+	return deleted, nil
+}
+
+// Grant gives the permission to the role. It reports false when the role
+// already had it.
+func (p *permissionQuery) Grant(ctx context.Context, roleID, permissionID int64) (bool, error) {
+	p.logger.Debug("Granting permission", zap.Int64("role_id", roleID), zap.Int64("permission_id", permissionID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := p.sq.Insert(RolePermissionsTable).
+		Columns(RolePermissionsRoleID, RolePermissionsPermissionID).
+		Values(roleID, permissionID).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("role_id", roleID),
+				zap.Int64("permission_id", permissionID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to grant permission", zap.Int64("role_id", roleID), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	granted := result.RowsAffected() > 0
+	p.logger.Info("Permission granted", zap.Int64("role_id", roleID), zap.Int64("permission_id", permissionID), zap.Bool("granted", granted))
+	return granted, nil
+}
+
+// Revoke takes the permission away from the role. It reports false when the
+// role did not have it.
+func (p *permissionQuery) Revoke(ctx context.Context, roleID, permissionID int64) (bool, error) {
+	p.logger.Debug("Revoking permission", zap.Int64("role_id", roleID), zap.Int64("permission_id", permissionID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, p.logger, p.runner)
+	if err != nil {
+		p.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := p.sq.Delete(RolePermissionsTable).
+		Where(squirrel.Eq{
+			RolePermissionsRoleID:       roleID,
+			RolePermissionsPermissionID: permissionID,
+		}).
+		ToSql()
+	if err != nil {
+		p.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			p.logger.Warn("Database error",
+				zap.Int64("role_id", roleID),
+				zap.Int64("permission_id", permissionID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			p.logger.Error("Failed to revoke permission", zap.Int64("role_id", roleID), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	revoked := result.RowsAffected() > 0
+	p.logger.Info("Permission revoked", zap.Int64("role_id", roleID), zap.Int64("permission_id", permissionID), zap.Bool("revoked", revoked))
+	return revoked, nil
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const RecoveryCodesTable = "mfa_recovery_codes"
+
+const (
+	RecoveryCodesID        = "mfa_recovery_codes_id_pk"
+	RecoveryCodesUserID    = "mfa_recovery_codes_users_id_fk"
+	RecoveryCodesCodeHash  = "mfa_recovery_codes_code_hash"
+	RecoveryCodesUsedAt    = "mfa_recovery_codes_used_at"
+	RecoveryCodesCreatedAt = "mfa_recovery_codes_created_at"
+)
+
+type RecoveryCodeQuery interface {
+	Replace(ctx context.Context, userID int64, codeHashes []string) error
+	Use(ctx context.Context, userID int64, codeHash string) (bool, error)
+	DeleteByUserID(ctx context.Context, userID int64) (int64, error)
+}
+
+type recoveryCodeQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewRecoveryCodeQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) RecoveryCodeQuery {
+	return &recoveryCodeQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+// Replace atomically swaps all recovery codes of the user for the given set.
+func (r *recoveryCodeQuery) Replace(ctx context.Context, userID int64, codeHashes []string) error {
+	r.logger.Debug("Replacing recovery codes", zap.Int64("user_id", userID), zap.Int("count", len(codeHashes)))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, r.logger, r.runner)
+	if err != nil {
+		r.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	deleteQb, deleteArgs, err := r.sq.Delete(RecoveryCodesTable).
+		Where(squirrel.Eq{RecoveryCodesUserID: userID}).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	insert := r.sq.Insert(RecoveryCodesTable).Columns(RecoveryCodesUserID, RecoveryCodesCodeHash)
+	for _, hash := range codeHashes {
+		insert = insert.Values(userID, hash)
+	}
+	insertQb, insertArgs, err := insert.ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, deleteQb, deleteArgs...); err != nil {
+		r.logger.Error("Failed to delete recovery codes", zap.Int64("user_id", userID), zap.Error(err))
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, insertQb, insertArgs...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			r.logger.Error("Failed to insert recovery codes", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info("Recovery codes replaced successfully", zap.Int64("user_id", userID))
+	return nil
+}
+
+// Use consumes a recovery code. It reports false when the code does not exist
+// or was already used.
+func (r *recoveryCodeQuery) Use(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	r.logger.Debug("Using recovery code", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, r.logger, r.runner)
+	if err != nil {
+		r.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := r.sq.Update(RecoveryCodesTable).
+		Set(RecoveryCodesUsedAt, time.Now()).
+		Where(squirrel.Eq{
+			RecoveryCodesUserID:   userID,
+			RecoveryCodesCodeHash: codeHash,
+			RecoveryCodesUsedAt:   nil,
+		}).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			r.logger.Error("Failed to use recovery code", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		r.logger.Warn("Recovery code not found or already used", zap.Int64("user_id", userID))
+		return false, nil
+	}
+	r.logger.Info("Recovery code used", zap.Int64("user_id", userID))
+	return true, nil
+}
+
+func (r *recoveryCodeQuery) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	r.logger.Debug("Deleting recovery codes by user ID", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, r.logger, r.runner)
+	if err != nil {
+		r.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := r.sq.Delete(RecoveryCodesTable).
+		Where(squirrel.Eq{RecoveryCodesUserID: userID}).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			r.logger.Error("Failed to delete recovery codes", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	r.logger.Info("Recovery codes deleted successfully", zap.Int64("user_id", userID), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
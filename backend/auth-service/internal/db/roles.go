@@ -23,13 +23,15 @@ const (
 	RolesCode        = "roles_code"
 	RolesName        = "roles_name"
 	RolesDescription = "roles_descr"
+	RolesMFARequired = "roles_mfa_required"
 )
 
 type Role struct {
-	ID          int64  `db:"roles_id_pk" insert:"roles_id_pk"`
-	Code        string `db:"roles_code" insert:"roles_code"`
-	Name        string `db:"roles_name" insert:"roles_name"`
-	Description string `db:"roles_descr" insert:"roles_descr"`
+	ID          int64  `db:"roles_id_pk"`
+	Code        int    `db:"roles_code" insert:"roles_code" update:"roles_code"`
+	Name        string `db:"roles_name" insert:"roles_name" update:"roles_name"`
+	Description string `db:"roles_descr" insert:"roles_descr" update:"roles_descr"`
+	MFARequired bool   `db:"roles_mfa_required" insert:"roles_mfa_required" update:"roles_mfa_required"`
 }
 
 var (
@@ -44,11 +46,14 @@ func (r *Role) columns(pref string) []string {
 
 type RoleQuery interface {
 	GetByID(ctx context.Context, id int64) (*Role, error)
+	GetByName(ctx context.Context, name string) (*Role, error)
 	GetIDByCode(ctx context.Context, code int) (int64, error)
 	GetIDByName(ctx context.Context, name string) (int64, error)
 	Insert(ctx context.Context, role *Role) (*Role, error)
 	Update(ctx context.Context, role *Role, id int64) (*Role, error)
+	List(ctx context.Context) ([]*Role, error)
 	Delete(ctx context.Context, id int64) error
+	DeleteAndReassign(ctx context.Context, id int64, replacementID int64) (int64, error)
 }
 
 type roleQuery struct {
@@ -108,6 +113,50 @@ func (r *roleQuery) GetByID(ctx context.Context, id int64) (*Role, error) {
 	return role, nil
 }
 
+func (r *roleQuery) GetByName(ctx context.Context, name string) (*Role, error) {
+	name = strings.ToLower(name)
+	r.logger.Debug("Fetching role by name", zap.String("name", name))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, r.logger, r.runner)
+	if err != nil {
+		r.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	role := &Role{}
+	qb, args, err := r.sq.Select(role.columns("")...).
+		From(RolesTable).
+		Where(squirrel.Eq{RolesName: name}).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, role, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Warn("Database error",
+				zap.String("name", name),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			r.logger.Warn("Failed to fetch role", zap.String("name", name), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	r.logger.Info("Role fetched successfully", zap.String("name", name), zap.Int64("role_id", role.ID))
+	return role, nil
+}
+
 func (r *roleQuery) GetIDByName(ctx context.Context, name string) (int64, error) {
 	name = strings.ToLower(name)
 	r.logger.Debug("Fetching role ID by name", zap.String("name", name))
@@ -122,7 +171,7 @@ func (r *roleQuery) GetIDByName(ctx context.Context, name string) (int64, error)
 	defer conn.Release()
 
 	var roleID int64
-	qb, args, err := r.sq.Select("id").
+	qb, args, err := r.sq.Select(RolesID).
 		From(RolesTable).
 		Where(squirrel.Eq{RolesName: name}).
 		ToSql()
@@ -161,7 +210,7 @@ func (r *roleQuery) GetIDByCode(ctx context.Context, code int) (int64, error) {
 	defer conn.Release()
 
 	var roleID int64
-	qb, args, err := r.sq.Select("id").
+	qb, args, err := r.sq.Select(RolesID).
 		From(RolesTable).
 		Where(squirrel.Eq{RolesCode: code}).
 		ToSql()
@@ -318,3 +367,138 @@ func (r *roleQuery) Delete(ctx context.Context, id int64) error {
 	r.logger.Info("Role deleted successfully", zap.Int64("role_id", id))
 	return nil
 }
+
+func (r *roleQuery) List(ctx context.Context) ([]*Role, error) {
+	r.logger.Debug("Listing roles")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, r.logger, r.runner)
+	if err != nil {
+		r.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var roles []*Role
+	qb, args, err := r.sq.Select((&Role{}).columns("")...).
+		From(RolesTable).
+		OrderBy(RolesCode).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, conn, &roles, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			r.logger.Warn("Failed to list roles", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	r.logger.Info("Roles listed successfully", zap.Int("count", len(roles)))
+	return roles, nil
+}
+
+// DeleteAndReassign moves the users of role id to replacementID, ends their
+// sessions, queues their role change events and deletes the role, all in one
+// transaction. It returns how many users were reassigned.
+func (r *roleQuery) DeleteAndReassign(ctx context.Context, id int64, replacementID int64) (int64, error) {
+	r.logger.Debug("Deleting role with reassignment", zap.Int64("role_id", id), zap.Int64("replacement_role_id", replacementID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, r.logger, r.runner)
+	if err != nil {
+		r.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	members := squirrel.Select(UsersID).
+		From(UsersTable).
+		Where(squirrel.Eq{UsersRoleID: id})
+	sessionsQb, sessionsArgs, err := r.sq.Delete(SessionsTable).
+		Where(members.Prefix(SessionsUserID + " IN (").Suffix(")")).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+	usersQb, usersArgs, err := r.sq.Update(UsersTable).
+		Set(UsersRoleID, replacementID).
+		Set(UsersUpdatedAt, time.Now()).
+		Where(squirrel.Eq{UsersRoleID: id}).
+		Suffix("RETURNING " + UsersID).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+	roleQb, roleArgs, err := r.sq.Delete(RolesTable).
+		Where(squirrel.Eq{RolesID: id}).
+		ToSql()
+	if err != nil {
+		r.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, sessionsQb, sessionsArgs...); err != nil {
+		r.logger.Error("Failed to delete sessions of role", zap.Int64("role_id", id), zap.Error(err))
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	var userIDs []int64
+	if err := pgxscan.Select(ctx, tx, &userIDs, usersQb, usersArgs...); err != nil {
+		r.logger.Error("Failed to reassign users", zap.Int64("role_id", id), zap.Error(err))
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if len(userIDs) > 0 {
+		if err := queueUserEvents(ctx, tx, r.sq, r.logger, OutboxUserRoleChanged, squirrel.Eq{UsersID: userIDs}); err != nil {
+			return 0, err
+		}
+	}
+	reassigned := int64(len(userIDs))
+	result, err := tx.Exec(ctx, roleQb, roleArgs...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Warn("Database error",
+				zap.Int64("role_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			r.logger.Error("Failed to delete role", zap.Int64("role_id", id), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		r.logger.Warn("No role found to delete", zap.Int64("role_id", id))
+		return 0, fmt.Errorf("no role found with id %d", id)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info("Role deleted successfully",
+		zap.Int64("role_id", id),
+		zap.Int64("replacement_role_id", replacementID),
+		zap.Int64("reassigned_users", reassigned))
+	return reassigned, nil
+}
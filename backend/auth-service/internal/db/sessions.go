@@ -0,0 +1,472 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const SessionsTable = "sessions"
+
+const (
+	SessionsID              = "sessions_id_pk"
+	SessionsUserID          = "sessions_users_id_fk"
+	SessionsAccessTokenJTI  = "sessions_access_token_jti"
+	SessionsRefreshTokenJTI = "sessions_refresh_token_jti"
+	SessionsUserAgent       = "sessions_user_agent"
+	SessionsIPAddress       = "sessions_ip_address"
+	SessionsCreatedAt       = "sessions_created_at"
+	SessionsLastUsedAt      = "sessions_last_used_at"
+	SessionsExpiresAt       = "sessions_expires_at"
+)
+
+type Session struct {
+	ID              string     `db:"sessions_id_pk" insert:"sessions_id_pk"`
+	UserID          int64      `db:"sessions_users_id_fk" insert:"sessions_users_id_fk"`
+	AccessTokenJTI  string     `db:"sessions_access_token_jti" insert:"sessions_access_token_jti"`
+	RefreshTokenJTI string     `db:"sessions_refresh_token_jti" insert:"sessions_refresh_token_jti"`
+	UserAgent       string     `db:"sessions_user_agent" insert:"sessions_user_agent"`
+	IPAddress       string     `db:"sessions_ip_address" insert:"sessions_ip_address"`
+	CreatedAt       *time.Time `db:"sessions_created_at"`
+	LastUsedAt      *time.Time `db:"sessions_last_used_at"`
+	ExpiresAt       time.Time  `db:"sessions_expires_at" insert:"sessions_expires_at"`
+}
+
+var (
+	stomSessionSelect = stom.MustNewStom(Session{}).SetTag(selectTag)
+	stomSessionInsert = stom.MustNewStom(Session{}).SetTag(insertTag)
+)
+
+func (s *Session) columns(pref string) []string {
+	return colNamesWithPref(stomSessionSelect.TagValues(), pref)
+}
+
+type SessionQuery interface {
+	GetByID(ctx context.Context, id string) (*Session, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*Session, error)
+	Insert(ctx context.Context, session *Session) (*Session, error)
+	Rotate(ctx context.Context, id string, oldRefreshJTI string, accessJTI string, refreshJTI string, expiresAt time.Time) (*Session, error)
+	Touch(ctx context.Context, id string, usedBefore time.Time) error
+	Delete(ctx context.Context, id string) error
+	DeleteByUserID(ctx context.Context, userID int64) (int64, error)
+	DeleteByRoleID(ctx context.Context, roleID int64) (int64, error)
+	DeleteOthers(ctx context.Context, userID int64, keepID string) (int64, error)
+}
+
+type sessionQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewSessionQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) SessionQuery {
+	return &sessionQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+func (s *sessionQuery) GetByID(ctx context.Context, id string) (*Session, error) {
+	s.logger.Debug("Fetching session by ID", zap.String("session_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	session := &Session{}
+	qb, args, err := s.sq.Select(session.columns("")...).
+		From(SessionsTable).
+		Where(squirrel.Eq{SessionsID: id}).
+		Where(squirrel.Gt{SessionsExpiresAt: time.Now()}).
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, session, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.String("session_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Warn("Failed to fetch session", zap.String("session_id", id), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	s.logger.Info("Session fetched successfully", zap.String("session_id", id))
+	return session, nil
+}
+
+func (s *sessionQuery) ListByUserID(ctx context.Context, userID int64) ([]*Session, error) {
+	s.logger.Debug("Listing sessions by user ID", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var sessions []*Session
+	qb, args, err := s.sq.Select((&Session{}).columns("")...).
+		From(SessionsTable).
+		Where(squirrel.Eq{SessionsUserID: userID}).
+		Where(squirrel.Gt{SessionsExpiresAt: time.Now()}).
+		OrderBy(SessionsLastUsedAt + " DESC").
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, conn, &sessions, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Warn("Failed to list sessions", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	s.logger.Info("Sessions listed successfully", zap.Int64("user_id", userID), zap.Int("count", len(sessions)))
+	return sessions, nil
+}
+
+func (s *sessionQuery) Insert(ctx context.Context, session *Session) (*Session, error) {
+	s.logger.Debug("Inserting session", zap.String("session_id", session.ID), zap.Int64("user_id", session.UserID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	insertMap, err := stomSessionInsert.ToMap(session)
+	if err != nil {
+		s.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := s.sq.Insert(SessionsTable).
+		SetMap(insertMap).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	err = pgxscan.Get(ctx, conn, session, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.Int64("user_id", session.UserID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to insert session", zap.Int64("user_id", session.UserID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	s.logger.Info("Session inserted successfully", zap.String("session_id", session.ID), zap.Int64("user_id", session.UserID))
+	return session, nil
+}
+
+// Rotate replaces the token JTIs of the session only if the stored refresh JTI
+// still equals oldRefreshJTI. It returns nil without error when another request
+// has already rotated (or revoked) the session.
+func (s *sessionQuery) Rotate(ctx context.Context, id string, oldRefreshJTI string, accessJTI string, refreshJTI string, expiresAt time.Time) (*Session, error) {
+	s.logger.Debug("Rotating session tokens", zap.String("session_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var session Session
+	qb, args, err := s.sq.Update(SessionsTable).
+		Set(SessionsAccessTokenJTI, accessJTI).
+		Set(SessionsRefreshTokenJTI, refreshJTI).
+		Set(SessionsLastUsedAt, time.Now()).
+		Set(SessionsExpiresAt, expiresAt).
+		Where(squirrel.Eq{SessionsID: id, SessionsRefreshTokenJTI: oldRefreshJTI}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, &session, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("Session already rotated", zap.String("session_id", id))
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.String("session_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to rotate session tokens", zap.String("session_id", id), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	s.logger.Info("Session tokens rotated successfully", zap.String("session_id", id))
+	return &session, nil
+}
+
+// Touch records that the session was just used. Sessions used after
+// usedBefore are left alone, so concurrent requests write the row once.
+func (s *sessionQuery) Touch(ctx context.Context, id string, usedBefore time.Time) error {
+	s.logger.Debug("Touching session", zap.String("session_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := s.sq.Update(SessionsTable).
+		Set(SessionsLastUsedAt, time.Now()).
+		Where(squirrel.Eq{SessionsID: id}).
+		Where(squirrel.Or{
+			squirrel.Eq{SessionsLastUsedAt: nil},
+			squirrel.Lt{SessionsLastUsedAt: usedBefore},
+		}).
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, qb, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.String("session_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to touch session", zap.String("session_id", id), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	return nil
+}
+
+func (s *sessionQuery) Delete(ctx context.Context, id string) error {
+	s.logger.Debug("Deleting session", zap.String("session_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := s.sq.Delete(SessionsTable).
+		Where(squirrel.Eq{SessionsID: id}).
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.String("session_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to delete session", zap.String("session_id", id), zap.Error(err))
+		}
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		s.logger.Warn("No session found to delete", zap.String("session_id", id))
+		return fmt.Errorf("no session found with id %s", id)
+	}
+
+	s.logger.Info("Session deleted successfully", zap.String("session_id", id))
+	return nil
+}
+
+func (s *sessionQuery) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	s.logger.Debug("Deleting sessions by user ID", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := s.sq.Delete(SessionsTable).
+		Where(squirrel.Eq{SessionsUserID: userID}).
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to delete sessions", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	s.logger.Info("Sessions deleted successfully", zap.Int64("user_id", userID), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
+
+// DeleteByRoleID revokes the sessions of every user holding the role.
+func (s *sessionQuery) DeleteByRoleID(ctx context.Context, roleID int64) (int64, error) {
+	s.logger.Debug("Deleting sessions by role ID", zap.Int64("role_id", roleID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	members := squirrel.Select(UsersID).
+		From(UsersTable).
+		Where(squirrel.Eq{UsersRoleID: roleID})
+	qb, args, err := s.sq.Delete(SessionsTable).
+		Where(members.Prefix(SessionsUserID + " IN (").Suffix(")")).
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.Int64("role_id", roleID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to delete sessions", zap.Int64("role_id", roleID), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	s.logger.Info("Sessions deleted successfully", zap.Int64("role_id", roleID), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
+
+// DeleteOthers revokes every session of the user except keepID.
+func (s *sessionQuery) DeleteOthers(ctx context.Context, userID int64, keepID string) (int64, error) {
+	s.logger.Debug("Deleting other sessions of user", zap.Int64("user_id", userID), zap.String("keep_session_id", keepID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, s.logger, s.runner)
+	if err != nil {
+		s.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := s.sq.Delete(SessionsTable).
+		Where(squirrel.Eq{SessionsUserID: userID}).
+		Where(squirrel.NotEq{SessionsID: keepID}).
+		ToSql()
+	if err != nil {
+		s.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			s.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			s.logger.Error("Failed to delete sessions", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	s.logger.Info("Other sessions deleted successfully", zap.Int64("user_id", userID), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
@@ -0,0 +1,252 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const SigningKeysTable = "signing_keys"
+
+const (
+	SigningKeysID         = "signing_keys_id_pk"
+	SigningKeysAlgorithm  = "signing_keys_algorithm"
+	SigningKeysPrivateKey = "signing_keys_private_key"
+	SigningKeysState      = "signing_keys_state"
+	SigningKeysCreatedAt  = "signing_keys_created_at"
+	SigningKeysRotatedAt  = "signing_keys_rotated_at"
+	SigningKeysRetiredAt  = "signing_keys_retired_at"
+)
+
+// Signing key states. The active key signs new tokens; verify-only keys are
+// former active keys whose tokens are still accepted; retired keys are kept
+// for the record only.
+const (
+	SigningKeyActive     = "active"
+	SigningKeyVerifyOnly = "verify_only"
+	SigningKeyRetired    = "retired"
+)
+
+// SigningKey is a JWT signing key. The key ID is its JWK thumbprint and the
+// private key is stored encrypted.
+type SigningKey struct {
+	ID         string     `db:"signing_keys_id_pk" insert:"signing_keys_id_pk"`
+	Algorithm  string     `db:"signing_keys_algorithm" insert:"signing_keys_algorithm"`
+	PrivateKey string     `db:"signing_keys_private_key" insert:"signing_keys_private_key"`
+	State      string     `db:"signing_keys_state" insert:"signing_keys_state"`
+	CreatedAt  *time.Time `db:"signing_keys_created_at"`
+	RotatedAt  *time.Time `db:"signing_keys_rotated_at"`
+	RetiredAt  *time.Time `db:"signing_keys_retired_at"`
+}
+
+var (
+	stomSigningKeySelect = stom.MustNewStom(SigningKey{}).SetTag(selectTag)
+	stomSigningKeyInsert = stom.MustNewStom(SigningKey{}).SetTag(insertTag)
+)
+
+func (k *SigningKey) columns(pref string) []string {
+	return colNamesWithPref(stomSigningKeySelect.TagValues(), pref)
+}
+
+type SigningKeyQuery interface {
+	ListPublished(ctx context.Context) ([]*SigningKey, error)
+	Rotate(ctx context.Context, previousID string, previousState string, next *SigningKey) (bool, error)
+	RetireRotatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type signingKeyQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewSigningKeyQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) SigningKeyQuery {
+	return &signingKeyQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+// ListPublished returns the active and verify-only keys, newest first.
+func (k *signingKeyQuery) ListPublished(ctx context.Context) ([]*SigningKey, error) {
+	k.logger.Debug("Listing published signing keys")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, k.logger, k.runner)
+	if err != nil {
+		k.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var signingKeys []*SigningKey
+	qb, args, err := k.sq.Select((&SigningKey{}).columns("")...).
+		From(SigningKeysTable).
+		Where(squirrel.Eq{SigningKeysState: []string{SigningKeyActive, SigningKeyVerifyOnly}}).
+		OrderBy(SigningKeysCreatedAt + " DESC").
+		ToSql()
+	if err != nil {
+		k.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, conn, &signingKeys, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			k.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			k.logger.Warn("Failed to list signing keys", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	k.logger.Info("Signing keys listed successfully", zap.Int("count", len(signingKeys)))
+	return signingKeys, nil
+}
+
+// Rotate moves the active key previousID to previousState and inserts next as
+// the new active key in one transaction. An empty previousID is used for the
+// very first key. It reports false when another replica rotated first: the
+// previous key is no longer active, or an active key already exists.
+func (k *signingKeyQuery) Rotate(ctx context.Context, previousID string, previousState string, next *SigningKey) (bool, error) {
+	k.logger.Debug("Rotating signing key",
+		zap.String("previous_kid", previousID),
+		zap.String("previous_state", previousState),
+		zap.String("kid", next.ID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, k.logger, k.runner)
+	if err != nil {
+		k.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		k.logger.Error("Failed to begin transaction", zap.Error(err))
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if previousID != "" {
+		now := time.Now()
+		update := k.sq.Update(SigningKeysTable).
+			Set(SigningKeysState, previousState).
+			Set(SigningKeysRotatedAt, now).
+			Where(squirrel.Eq{SigningKeysID: previousID, SigningKeysState: SigningKeyActive})
+		if previousState == SigningKeyRetired {
+			update = update.Set(SigningKeysRetiredAt, now)
+		}
+		qb, args, err := update.ToSql()
+		if err != nil {
+			k.logger.Error("Failed to build query", zap.Error(err))
+			return false, fmt.Errorf("failed to build query: %w", err)
+		}
+		result, err := tx.Exec(ctx, qb, args...)
+		if err != nil {
+			k.logger.Error("Failed to demote signing key", zap.String("kid", previousID), zap.Error(err))
+			return false, fmt.Errorf("failed to execute query: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			k.logger.Warn("Signing key is no longer active", zap.String("kid", previousID))
+			return false, nil
+		}
+	}
+
+	insertMap, err := stomSigningKeyInsert.ToMap(next)
+	if err != nil {
+		k.logger.Error("Failed to map struct", zap.Error(err))
+		return false, fmt.Errorf("failed to map struct: %w", err)
+	}
+	qb, args, err := k.sq.Insert(SigningKeysTable).
+		SetMap(insertMap).
+		ToSql()
+	if err != nil {
+		k.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, qb, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23505" {
+				k.logger.Warn("Another signing key is already active", zap.String("kid", next.ID))
+				return false, nil
+			}
+			k.logger.Warn("Database error",
+				zap.String("kid", next.ID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			k.logger.Error("Failed to insert signing key", zap.String("kid", next.ID), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		k.logger.Error("Failed to commit transaction", zap.Error(err))
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	k.logger.Info("Signing key rotated successfully", zap.String("previous_kid", previousID), zap.String("kid", next.ID))
+	return true, nil
+}
+
+// RetireRotatedBefore retires verify-only keys that left the active state
+// before the given time.
+func (k *signingKeyQuery) RetireRotatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	k.logger.Debug("Retiring signing keys", zap.Time("rotated_before", before))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, k.logger, k.runner)
+	if err != nil {
+		k.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return 0, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := k.sq.Update(SigningKeysTable).
+		Set(SigningKeysState, SigningKeyRetired).
+		Set(SigningKeysRetiredAt, time.Now()).
+		Where(squirrel.Eq{SigningKeysState: SigningKeyVerifyOnly}).
+		Where(squirrel.Lt{SigningKeysRotatedAt: before}).
+		ToSql()
+	if err != nil {
+		k.logger.Error("Failed to build query", zap.Error(err))
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			k.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			k.logger.Error("Failed to retire signing keys", zap.Error(err))
+		}
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	k.logger.Info("Signing keys retired successfully", zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
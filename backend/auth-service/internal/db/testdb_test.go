@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDatabaseURLEnv names the Postgres the integration tests of this package
+// run against. They are skipped when it is not set.
+const testDatabaseURLEnv = "AUTH_TEST_DATABASE_URL"
+
+var testSQ = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+// testPool returns a pool on a fresh schema created from init.sql. The schema
+// is dropped when the test ends.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv(testDatabaseURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnv)
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("pgxpool.New() error = %v", err)
+	}
+	t.Cleanup(admin.Close)
+	schema := fmt.Sprintf("auth_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("failed to drop schema: %v", err)
+		}
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("pgxpool.ParseConfig() error = %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("pgxpool.NewWithConfig() error = %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	// init.sql starts by dropping the tables of a previous run, which do not
+	// exist in a fresh schema.
+	script, err := os.ReadFile("../../init.sql")
+	if err != nil {
+		t.Fatalf("failed to read init.sql: %v", err)
+	}
+	var statements []string
+	for _, line := range strings.Split(string(script), "\n") {
+		if !strings.HasPrefix(line, "DROP ") {
+			statements = append(statements, line)
+		}
+	}
+	if _, err := pool.Exec(ctx, strings.Join(statements, "\n")); err != nil {
+		t.Fatalf("failed to run init.sql: %v", err)
+	}
+	return pool
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/elgris/stom"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const UserStatusChangesTable = "user_status_changes"
+
+const (
+	UserStatusChangesID        = "user_status_changes_id_pk"
+	UserStatusChangesUserID    = "user_status_changes_users_id_fk"
+	UserStatusChangesFrom      = "user_status_changes_from"
+	UserStatusChangesTo        = "user_status_changes_to"
+	UserStatusChangesReason    = "user_status_changes_reason"
+	UserStatusChangesActorID   = "user_status_changes_actor_id"
+	UserStatusChangesCreatedAt = "user_status_changes_created_at"
+)
+
+// UserStatusChange is one transition of an account status. ActorID is the
+// admin who made it, nil when the service did on its own.
+type UserStatusChange struct {
+	ID        int64      `db:"user_status_changes_id_pk"`
+	UserID    int64      `db:"user_status_changes_users_id_fk" insert:"user_status_changes_users_id_fk"`
+	From      string     `db:"user_status_changes_from" insert:"user_status_changes_from"`
+	To        string     `db:"user_status_changes_to" insert:"user_status_changes_to"`
+	Reason    string     `db:"user_status_changes_reason" insert:"user_status_changes_reason"`
+	ActorID   *int64     `db:"user_status_changes_actor_id" insert:"user_status_changes_actor_id"`
+	CreatedAt *time.Time `db:"user_status_changes_created_at"`
+}
+
+var (
+	stomUserStatusChangeSelect = stom.MustNewStom(UserStatusChange{}).SetTag(selectTag)
+	stomUserStatusChangeInsert = stom.MustNewStom(UserStatusChange{}).SetTag(insertTag)
+)
+
+func (c *UserStatusChange) columns(pref string) []string {
+	return colNamesWithPref(stomUserStatusChangeSelect.TagValues(), pref)
+}
+
+// UserStatusChangeQuery reads the status history. Changes are written by
+// UserQuery.ChangeStatus together with the status itself.
+type UserStatusChangeQuery interface {
+	ListByUserID(ctx context.Context, userID int64) ([]*UserStatusChange, error)
+}
+
+type userStatusChangeQuery struct {
+	runner *pgxpool.Pool
+	sq     squirrel.StatementBuilderType
+	logger *zap.Logger
+}
+
+func NewUserStatusChangeQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) UserStatusChangeQuery {
+	return &userStatusChangeQuery{
+		runner: runner,
+		sq:     sq,
+		logger: logger,
+	}
+}
+
+func (c *userStatusChangeQuery) ListByUserID(ctx context.Context, userID int64) ([]*UserStatusChange, error) {
+	c.logger.Debug("Listing status changes by user ID", zap.Int64("user_id", userID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, c.logger, c.runner)
+	if err != nil {
+		c.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var changes []*UserStatusChange
+	qb, args, err := c.sq.Select((&UserStatusChange{}).columns("")...).
+		From(UserStatusChangesTable).
+		Where(squirrel.Eq{UserStatusChangesUserID: userID}).
+		OrderBy(UserStatusChangesID + " DESC").
+		ToSql()
+	if err != nil {
+		c.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, conn, &changes, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			c.logger.Warn("Database error",
+				zap.Int64("user_id", userID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			c.logger.Warn("Failed to list status changes", zap.Int64("user_id", userID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	c.logger.Info("Status changes listed successfully", zap.Int64("user_id", userID), zap.Int("count", len(changes)))
+	return changes, nil
+}
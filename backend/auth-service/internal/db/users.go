@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -23,9 +24,16 @@ const (
 	UsersUsername           = "users_username"
 	UsersPasswordHash       = "users_password_hash"
 	UsersEmail              = "users_email"
-	UsersRoleID             = "users_role_id_fk"
+	UsersEmailVerified      = "users_email_verified"
+	UsersRoleID             = "users_roles_id_fk"
 	UsersAccessTokenSecret  = "users_access_token_secret"
 	UsersRefreshTokenSecret = "users_refresh_token_secret"
+	UsersTOTPSecret         = "users_totp_secret"
+	UsersTOTPEnabled        = "users_totp_enabled"
+	UsersTOTPLastStep       = "users_totp_last_step"
+	UsersStatus             = "users_status"
+	UsersStatusReason       = "users_status_reason"
+	UsersStatusChangedAt    = "users_status_changed_at"
 	UsersAuthTime           = "users_auth_time"
 	UsersCreatedAt          = "users_created_at"
 	UsersUpdatedAt          = "users_updated_at"
@@ -36,21 +44,25 @@ type User struct {
 	Username           string     `db:"users_username" insert:"users_username" update:"users_username"`
 	Password           string     `db:"users_password_hash" insert:"users_password_hash" update:"users_password_hash"`
 	Email              string     `db:"users_email" insert:"users_email"`
+	EmailVerified      bool       `db:"users_email_verified" insert:"users_email_verified"`
 	RoleID             int64      `db:"users_roles_id_fk" insert:"users_roles_id_fk"`
 	AccessTokenSecret  string     `db:"users_access_token_secret" insert:"users_access_token_secret"`
 	RefreshTokenSecret string     `db:"users_refresh_token_secret" insert:"users_refresh_token_secret"`
-	AccessTokenJTI     *string    `db:"users_access_token_jti" updateAuth:"users_access_token_jti"`
-	RefreshTokenJTI    *string    `db:"users_refresh_token_jti" updateAuth:"users_refresh_token_jti"`
+	TOTPSecret         *string    `db:"users_totp_secret"`
+	TOTPEnabled        bool       `db:"users_totp_enabled"`
+	TOTPLastStep       int64      `db:"users_totp_last_step"`
+	Status             string     `db:"users_status" insert:"users_status"`
+	StatusReason       *string    `db:"users_status_reason"`
+	StatusChangedAt    *time.Time `db:"users_status_changed_at"`
 	AuthTime           *time.Time `db:"users_auth_time" insert:"users_auth_time"`
 	CreatedAt          *time.Time `db:"users_created_at"`
-	UpdatedAt          *time.Time `db:"users_updated_at" update:"users_updated_at" updateAuth:"users_updated_at"`
+	UpdatedAt          *time.Time `db:"users_updated_at" update:"users_updated_at"`
 }
 
 var (
-	stomUserSelect     = stom.MustNewStom(User{}).SetTag(selectTag)
-	stomUserInsert     = stom.MustNewStom(User{}).SetTag(insertTag)
-	stomUserUpdate     = stom.MustNewStom(User{}).SetTag(updateTag)
-	stomUserAuthUpdate = stom.MustNewStom(User{}).SetTag(updateAuthTag)
+	stomUserSelect = stom.MustNewStom(User{}).SetTag(selectTag)
+	stomUserInsert = stom.MustNewStom(User{}).SetTag(insertTag)
+	stomUserUpdate = stom.MustNewStom(User{}).SetTag(updateTag)
 )
 
 func (u *User) columns(pref string) []string {
@@ -62,25 +74,151 @@ type UserQuery interface {
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	ExistsByUsernameOrEmail(ctx context.Context, username string, email string) (bool, error)
+	ListByUsernamesOrEmails(ctx context.Context, usernames []string, emails []string) ([]*User, error)
 	Insert(ctx context.Context, user *User) (*User, error)
 	Update(ctx context.Context, user *User, id int64) (*User, error)
 	UpdateAuthTime(ctx context.Context, id int64) (*User, error)
-	UpdateLoginOrLogout(ctx context.Context, user *User, id int64) (*User, error)
+	MarkEmailVerified(ctx context.Context, id int64) (*User, error)
+	ChangeEmail(ctx context.Context, id int64, email string) (*User, error)
+	SetRole(ctx context.Context, id int64, roleID int64) (*User, error)
+	ChangeStatus(ctx context.Context, change *UserStatusChange) (*User, error)
+	SetTOTP(ctx context.Context, id int64, secret *string, enabled bool) (*User, error)
+	UseTOTPStep(ctx context.Context, id int64, step int64) (bool, error)
 	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, page *UserPage) ([]*User, error)
+	ReencryptSecrets(ctx context.Context, afterID int64, limit uint64) (*SecretReencryption, error)
+}
+
+// SecretReencryption is the outcome of one batch of ReencryptSecrets.
+type SecretReencryption struct {
+	// LastID is the ID of the last user of the batch, where the next batch
+	// continues; 0 if there were no users left.
+	LastID    int64
+	Users     int
+	Rewrapped int
+	// Failed counts the users whose secrets could not be decrypted, which
+	// are left as they are.
+	Failed int
+}
+
+// SecretCipher encrypts the token and TOTP secrets of users at rest. Users
+// are inserted with encrypted secrets and read back with plain text ones.
+// Values are bound to their column and user, so they cannot be moved to
+// another row.
+type SecretCipher interface {
+	Encrypt(plaintext, column string, userID int64) (string, error)
+	Decrypt(value, column string, userID int64) (string, error)
+	// Rewrap returns the value encrypted with the current master key in the
+	// current format.
+	Rewrap(value, column string, userID int64) (string, error)
+	// CurrentPrefix starts every value encrypted with the current master key
+	// in the current format.
+	CurrentPrefix() string
+}
+
+// Account statuses. New accounts are pending while they wait for email
+// verification and active otherwise; deleted is final.
+const (
+	UserStatusPending   = "pending"
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDeleted   = "deleted"
+)
+
+// UserSort is the order of a user listing. Every order is made total by the
+// user ID, which keyset pagination relies on.
+type UserSort int
+
+const (
+	UserSortID UserSort = iota
+	UserSortUsername
+	UserSortEmail
+	UserSortCreatedAt
+	UserSortAuthTime
+)
+
+// expr is the SQL expression the listing is ordered by. Auth time may be NULL
+// for users that never logged in; they sort as if they did at the epoch.
+func (s UserSort) expr() string {
+	switch s {
+	case UserSortUsername:
+		return UsersUsername
+	case UserSortEmail:
+		return UsersEmail
+	case UserSortCreatedAt:
+		return UsersCreatedAt
+	case UserSortAuthTime:
+		return "COALESCE(" + UsersAuthTime + ", 'epoch'::timestamp)"
+	default:
+		return UsersID
+	}
+}
+
+// UserFilter narrows a user listing. Zero fields do not filter.
+type UserFilter struct {
+	RoleID        int64
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	AuthAfter     *time.Time
+	AuthBefore    *time.Time
+	// Prefix matches the beginning of the username or the email, ignoring case.
+	Prefix string
+}
+
+// UserPage selects one page of a user listing. AfterID and AfterValue are the
+// ID and sort value of the last user of the previous page; AfterID 0 starts
+// from the beginning.
+type UserPage struct {
+	Filter     UserFilter
+	Sort       UserSort
+	Descending bool
+	AfterID    int64
+	AfterValue interface{}
+	Limit      uint64
 }
 
 type userQuery struct {
-	runner *pgxpool.Pool
-	sq     squirrel.StatementBuilderType
-	logger *zap.Logger
+	runner  *pgxpool.Pool
+	sq      squirrel.StatementBuilderType
+	secrets SecretCipher
+	logger  *zap.Logger
 }
 
-func NewUserQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, logger *zap.Logger) UserQuery {
+func NewUserQuery(runner *pgxpool.Pool, sq squirrel.StatementBuilderType, secrets SecretCipher, logger *zap.Logger) UserQuery {
 	return &userQuery{
-		runner: runner,
-		sq:     sq,
-		logger: logger,
+		runner:  runner,
+		sq:      sq,
+		secrets: secrets,
+		logger:  logger,
+	}
+}
+
+// decryptSecrets replaces the stored token and TOTP secrets of users with
+// their plain text.
+func (u *userQuery) decryptSecrets(users ...*User) error {
+	for _, user := range users {
+		accessTokenSecret, err := u.secrets.Decrypt(user.AccessTokenSecret, UsersAccessTokenSecret, user.ID)
+		if err != nil {
+			u.logger.Error("Failed to decrypt access token secret", zap.Int64("user_id", user.ID), zap.Error(err))
+			return fmt.Errorf("failed to decrypt access token secret: %w", err)
+		}
+		refreshTokenSecret, err := u.secrets.Decrypt(user.RefreshTokenSecret, UsersRefreshTokenSecret, user.ID)
+		if err != nil {
+			u.logger.Error("Failed to decrypt refresh token secret", zap.Int64("user_id", user.ID), zap.Error(err))
+			return fmt.Errorf("failed to decrypt refresh token secret: %w", err)
+		}
+		user.AccessTokenSecret, user.RefreshTokenSecret = accessTokenSecret, refreshTokenSecret
+		if user.TOTPSecret != nil {
+			totpSecret, err := u.secrets.Decrypt(*user.TOTPSecret, UsersTOTPSecret, user.ID)
+			if err != nil {
+				u.logger.Error("Failed to decrypt TOTP secret", zap.Int64("user_id", user.ID), zap.Error(err))
+				return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+			}
+			user.TOTPSecret = &totpSecret
+		}
 	}
+	return nil
 }
 
 func (u *userQuery) GetByID(ctx context.Context, id int64) (*User, error) {
@@ -122,6 +260,9 @@ func (u *userQuery) GetByID(ctx context.Context, id int64) (*User, error) {
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	if err := u.decryptSecrets(user); err != nil {
+		return nil, err
+	}
 	u.logger.Info("User fetched successfully", zap.Int64("user_id", id))
 	return user, nil
 }
@@ -165,10 +306,14 @@ func (u *userQuery) GetByUsername(ctx context.Context, username string) (*User,
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	if err := u.decryptSecrets(user); err != nil {
+		return nil, err
+	}
 	u.logger.Info("User fetched successfully", zap.String("username", username))
 	return user, nil
 }
 
+// GetByEmail looks the user up by email address, ignoring case.
 func (u *userQuery) GetByEmail(ctx context.Context, email string) (*User, error) {
 	u.logger.Debug("Fetching user by email", zap.String("email", email))
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -184,7 +329,7 @@ func (u *userQuery) GetByEmail(ctx context.Context, email string) (*User, error)
 	user := &User{}
 	qb, args, err := u.sq.Select(user.columns("")...).
 		From(UsersTable).
-		Where(squirrel.Eq{UsersEmail: email}).
+		Where(squirrel.Expr("lower("+UsersEmail+") = lower(?)", email)).
 		ToSql()
 	if err != nil {
 		u.logger.Error("Failed to build query", zap.Error(err))
@@ -208,6 +353,9 @@ func (u *userQuery) GetByEmail(ctx context.Context, email string) (*User, error)
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	if err := u.decryptSecrets(user); err != nil {
+		return nil, err
+	}
 	u.logger.Info("User fetched successfully", zap.String("email", email))
 	return user, nil
 }
@@ -232,7 +380,7 @@ func (u *userQuery) ExistsByUsernameOrEmail(ctx context.Context, username, email
 		From(UsersTable).
 		Where(squirrel.Or{
 			squirrel.Eq{UsersUsername: username},
-			squirrel.Eq{UsersEmail: email},
+			squirrel.Expr("lower("+UsersEmail+") = lower(?)", email),
 		}).
 		ToSql()
 	if err != nil {
@@ -270,8 +418,61 @@ func (u *userQuery) ExistsByUsernameOrEmail(ctx context.Context, username, email
 	return exists, nil
 }
 
+// ListByUsernamesOrEmails returns the users holding any of the usernames, or
+// any of the emails ignoring case.
+func (u *userQuery) ListByUsernamesOrEmails(ctx context.Context, usernames, emails []string) ([]*User, error) {
+	u.logger.Debug("Listing users by usernames or emails",
+		zap.Int("usernames", len(usernames)),
+		zap.Int("emails", len(emails)))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	lowerEmails := make([]string, len(emails))
+	for i, email := range emails {
+		lowerEmails[i] = strings.ToLower(email)
+	}
+	qb, args, err := u.sq.Select((&User{}).columns("")...).
+		From(UsersTable).
+		Where(squirrel.Or{
+			squirrel.Expr(UsersUsername+" = ANY(?)", usernames),
+			squirrel.Expr("lower("+UsersEmail+") = ANY(?)", lowerEmails),
+		}).
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var users []*User
+	err = pgxscan.Select(ctx, conn, &users, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Error("Failed to list users", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := u.decryptSecrets(users...); err != nil {
+		return nil, err
+	}
+	u.logger.Info("Users listed by usernames or emails", zap.Int("count", len(users)))
+	return users, nil
+}
+
 func (u *userQuery) Insert(ctx context.Context, user *User) (*User, error) {
-	u.logger.Debug("Inserting user", zap.Any("user", user))
+	u.logger.Debug("Inserting user", zap.String("username", user.Username))
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -287,6 +488,33 @@ func (u *userQuery) Insert(ctx context.Context, user *User) (*User, error) {
 		u.logger.Error("Failed to map struct", zap.Error(err))
 		return nil, fmt.Errorf("failed to map struct: %w", err)
 	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		u.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The secrets are bound to the user ID, so the ID is taken from the
+	// sequence before the row is written.
+	var id int64
+	err = tx.QueryRow(ctx, "SELECT nextval(pg_get_serial_sequence($1, $2))", UsersTable, UsersID).Scan(&id)
+	if err != nil {
+		u.logger.Error("Failed to allocate user ID", zap.Error(err))
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	insertMap[UsersID] = id
+	insertMap[UsersAccessTokenSecret], err = u.secrets.Encrypt(user.AccessTokenSecret, UsersAccessTokenSecret, id)
+	if err != nil {
+		u.logger.Error("Failed to encrypt access token secret", zap.Error(err))
+		return nil, fmt.Errorf("failed to encrypt access token secret: %w", err)
+	}
+	insertMap[UsersRefreshTokenSecret], err = u.secrets.Encrypt(user.RefreshTokenSecret, UsersRefreshTokenSecret, id)
+	if err != nil {
+		u.logger.Error("Failed to encrypt refresh token secret", zap.Error(err))
+		return nil, fmt.Errorf("failed to encrypt refresh token secret: %w", err)
+	}
 	qb, args, err := u.sq.Insert(UsersTable).
 		SetMap(insertMap).
 		Suffix("RETURNING *").
@@ -295,20 +523,32 @@ func (u *userQuery) Insert(ctx context.Context, user *User) (*User, error) {
 		u.logger.Error("Failed to build query", zap.Error(err))
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
-	err = pgxscan.Get(ctx, conn, user, qb, args...)
+
+	err = pgxscan.Get(ctx, tx, user, qb, args...)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			u.logger.Warn("Database error",
-				zap.Any("user", user),
+				zap.String("username", user.Username),
 				zap.String("pg_error_code", pgErr.Code),
 				zap.Error(err),
 			)
 		} else {
-			u.logger.Error("Failed to insert user", zap.Any("user", user), zap.Error(err))
+			u.logger.Error("Failed to insert user", zap.String("username", user.Username), zap.Error(err))
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	if err := u.decryptSecrets(user); err != nil {
+		return nil, err
+	}
+	if err := queueUserEvents(ctx, tx, u.sq, u.logger, OutboxUserRegistered, squirrel.Eq{UsersID: user.ID}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		u.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	u.logger.Info("User inserted successfully", zap.Int64("user_id", user.ID))
 	return user, nil
 }
@@ -353,6 +593,9 @@ func (u *userQuery) Update(ctx context.Context, user *User, id int64) (*User, er
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	if err := u.decryptSecrets(user); err != nil {
+		return nil, err
+	}
 	u.logger.Info("User updated successfully", zap.Int64("user_id", user.ID))
 	return user, nil
 }
@@ -402,8 +645,8 @@ func (u *userQuery) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (u *userQuery) UpdateLoginOrLogout(ctx context.Context, user *User, id int64) (*User, error) {
-	u.logger.Debug("Updating user for auth", zap.Int64("user_id", id))
+func (u *userQuery) UpdateAuthTime(ctx context.Context, id int64) (*User, error) {
+	u.logger.Debug("Updating user auth time", zap.Int64("user_id", id))
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -414,13 +657,61 @@ func (u *userQuery) UpdateLoginOrLogout(ctx context.Context, user *User, id int6
 	}
 	defer conn.Release()
 
-	updateMap, err := stomUserAuthUpdate.ToMap(user)
+	var user User
+	qb, args, err := u.sq.Update(UsersTable).
+		Set(UsersAuthTime, time.Now()).
+		Where(squirrel.Eq{UsersID: id}).
+		Suffix("RETURNING *").
+		ToSql()
 	if err != nil {
-		u.logger.Error("Failed to map struct", zap.Error(err))
-		return nil, fmt.Errorf("failed to map struct: %w", err)
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, &user, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.Int64("user_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Error("Failed to update user auth time",
+				zap.Int64("user_id", id),
+				zap.Error(err),
+			)
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := u.decryptSecrets(&user); err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("User auth time updated successfully",
+		zap.Int64("user_id", user.ID),
+		zap.Time("new_auth_time", *user.AuthTime),
+	)
+	return &user, nil
+}
+
+func (u *userQuery) MarkEmailVerified(ctx context.Context, id int64) (*User, error) {
+	u.logger.Debug("Marking user email as verified", zap.Int64("user_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
 	}
+	defer conn.Release()
+
+	var user User
 	qb, args, err := u.sq.Update(UsersTable).
-		SetMap(updateMap).
+		Set(UsersEmailVerified, true).
+		Set(UsersUpdatedAt, time.Now()).
 		Where(squirrel.Eq{UsersID: id}).
 		Suffix("RETURNING *").
 		ToSql()
@@ -428,26 +719,33 @@ func (u *userQuery) UpdateLoginOrLogout(ctx context.Context, user *User, id int6
 		u.logger.Error("Failed to build query", zap.Error(err))
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
-	err = pgxscan.Get(ctx, conn, user, qb, args...)
+
+	err = pgxscan.Get(ctx, conn, &user, qb, args...)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			u.logger.Warn("Database error",
-				zap.Int64("user_id", user.ID),
+				zap.Int64("user_id", id),
 				zap.String("pg_error_code", pgErr.Code),
 				zap.Error(err),
 			)
 		} else {
-			u.logger.Error("Failed to update user", zap.Int64("user_id", user.ID), zap.Error(err))
+			u.logger.Error("Failed to mark user email as verified", zap.Int64("user_id", id), zap.Error(err))
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
-	u.logger.Info("User updated successfully", zap.Int64("user_id", user.ID))
-	return user, nil
+	if err := u.decryptSecrets(&user); err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("User email verified successfully", zap.Int64("user_id", id))
+	return &user, nil
 }
 
-func (u *userQuery) UpdateAuthTime(ctx context.Context, id int64) (*User, error) {
-	u.logger.Debug("Updating user auth time", zap.Int64("user_id", id))
+// ChangeEmail replaces the email address of the user and marks it as not
+// verified. It returns nil when the user does not exist.
+func (u *userQuery) ChangeEmail(ctx context.Context, id int64, email string) (*User, error) {
+	u.logger.Debug("Changing user email", zap.Int64("user_id", id))
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -460,7 +758,9 @@ func (u *userQuery) UpdateAuthTime(ctx context.Context, id int64) (*User, error)
 
 	var user User
 	qb, args, err := u.sq.Update(UsersTable).
-		Set(UsersAuthTime, time.Now()).
+		Set(UsersEmail, email).
+		Set(UsersEmailVerified, false).
+		Set(UsersUpdatedAt, time.Now()).
 		Where(squirrel.Eq{UsersID: id}).
 		Suffix("RETURNING *").
 		ToSql()
@@ -469,8 +769,18 @@ func (u *userQuery) UpdateAuthTime(ctx context.Context, id int64) (*User, error)
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	err = pgxscan.Get(ctx, conn, &user, qb, args...)
+	tx, err := conn.Begin(ctx)
 	if err != nil {
+		u.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = pgxscan.Get(ctx, tx, &user, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			u.logger.Warn("Database error",
@@ -479,21 +789,513 @@ func (u *userQuery) UpdateAuthTime(ctx context.Context, id int64) (*User, error)
 				zap.Error(err),
 			)
 		} else {
-			u.logger.Error("Failed to update user auth time",
+			u.logger.Error("Failed to change user email", zap.Int64("user_id", id), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := u.decryptSecrets(&user); err != nil {
+		return nil, err
+	}
+	if err := queueUserEvents(ctx, tx, u.sq, u.logger, OutboxUserEmailChanged, squirrel.Eq{UsersID: id}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		u.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	u.logger.Info("User email changed successfully", zap.Int64("user_id", id))
+	return &user, nil
+}
+
+func (u *userQuery) SetRole(ctx context.Context, id int64, roleID int64) (*User, error) {
+	u.logger.Debug("Updating user role", zap.Int64("user_id", id), zap.Int64("role_id", roleID))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var user User
+	qb, args, err := u.sq.Update(UsersTable).
+		Set(UsersRoleID, roleID).
+		Set(UsersUpdatedAt, time.Now()).
+		Where(squirrel.Eq{UsersID: id}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		u.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = pgxscan.Get(ctx, tx, &user, qb, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
 				zap.Int64("user_id", id),
+				zap.String("pg_error_code", pgErr.Code),
 				zap.Error(err),
 			)
+		} else {
+			u.logger.Error("Failed to update user role", zap.Int64("user_id", id), zap.Error(err))
 		}
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	if err := u.decryptSecrets(&user); err != nil {
+		return nil, err
+	}
+	if err := queueUserEvents(ctx, tx, u.sq, u.logger, OutboxUserRoleChanged, squirrel.Eq{UsersID: id}); err != nil {
+		return nil, err
+	}
 
-	u.logger.Info("User auth time updated successfully",
-		zap.Int64("user_id", user.ID),
-		zap.Time("new_auth_time", *user.AuthTime),
-	)
+	if err := tx.Commit(ctx); err != nil {
+		u.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	u.logger.Info("User role updated successfully", zap.Int64("user_id", id), zap.Int64("role_id", roleID))
 	return &user, nil
 }
 
+// ChangeStatus moves the user from change.From to change.To and records the
+// change. Suspending or deleting the user also ends all their sessions. It
+// returns nil when the user is not in change.From anymore.
+func (u *userQuery) ChangeStatus(ctx context.Context, change *UserStatusChange) (*User, error) {
+	u.logger.Debug("Changing user status",
+		zap.Int64("user_id", change.UserID),
+		zap.String("from", change.From),
+		zap.String("to", change.To))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	now := time.Now()
+	updateQb, updateArgs, err := u.sq.Update(UsersTable).
+		Set(UsersStatus, change.To).
+		Set(UsersStatusReason, change.Reason).
+		Set(UsersStatusChangedAt, now).
+		Set(UsersUpdatedAt, now).
+		Where(squirrel.Eq{UsersID: change.UserID, UsersStatus: change.From}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	insertMap, err := stomUserStatusChangeInsert.ToMap(change)
+	if err != nil {
+		u.logger.Error("Failed to map struct", zap.Error(err))
+		return nil, fmt.Errorf("failed to map struct: %w", err)
+	}
+	insertQb, insertArgs, err := u.sq.Insert(UserStatusChangesTable).
+		SetMap(insertMap).
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	sessionsQb, sessionsArgs, err := u.sq.Delete(SessionsTable).
+		Where(squirrel.Eq{SessionsUserID: change.UserID}).
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		u.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var user User
+	err = pgxscan.Get(ctx, tx, &user, updateQb, updateArgs...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.Int64("user_id", change.UserID),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Error("Failed to change user status", zap.Int64("user_id", change.UserID), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := u.decryptSecrets(&user); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, insertQb, insertArgs...); err != nil {
+		u.logger.Error("Failed to record status change", zap.Int64("user_id", change.UserID), zap.Error(err))
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if change.To == UserStatusSuspended || change.To == UserStatusDeleted {
+		if _, err := tx.Exec(ctx, sessionsQb, sessionsArgs...); err != nil {
+			u.logger.Error("Failed to delete sessions", zap.Int64("user_id", change.UserID), zap.Error(err))
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+	}
+	if change.To == UserStatusDeleted {
+		if err := queueUserEvents(ctx, tx, u.sq, u.logger, OutboxUserDeleted, squirrel.Eq{UsersID: change.UserID}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		u.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	u.logger.Info("User status changed successfully",
+		zap.Int64("user_id", change.UserID),
+		zap.String("from", change.From),
+		zap.String("to", change.To))
+	return &user, nil
+}
+
+// SetTOTP stores the TOTP secret of the user encrypted. A nil secret removes
+// it.
+func (u *userQuery) SetTOTP(ctx context.Context, id int64, secret *string, enabled bool) (*User, error) {
+	u.logger.Debug("Updating user TOTP", zap.Int64("user_id", id), zap.Bool("enabled", enabled))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	var encryptedSecret *string
+	if secret != nil {
+		encrypted, err := u.secrets.Encrypt(*secret, UsersTOTPSecret, id)
+		if err != nil {
+			u.logger.Error("Failed to encrypt TOTP secret", zap.Int64("user_id", id), zap.Error(err))
+			return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+		}
+		encryptedSecret = &encrypted
+	}
+
+	var user User
+	qb, args, err := u.sq.Update(UsersTable).
+		Set(UsersTOTPSecret, encryptedSecret).
+		Set(UsersTOTPEnabled, enabled).
+		Set(UsersTOTPLastStep, 0).
+		Set(UsersUpdatedAt, time.Now()).
+		Where(squirrel.Eq{UsersID: id}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = pgxscan.Get(ctx, conn, &user, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.Int64("user_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Error("Failed to update user TOTP", zap.Int64("user_id", id), zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := u.decryptSecrets(&user); err != nil {
+		return nil, err
+	}
+
+	u.logger.Info("User TOTP updated successfully", zap.Int64("user_id", id), zap.Bool("enabled", enabled))
+	return &user, nil
+}
+
+// UseTOTPStep records that the code of the given time step was used. It
+// reports false when a code of this or a later step was already accepted, so
+// an intercepted code cannot be replayed.
+func (u *userQuery) UseTOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	u.logger.Debug("Recording used TOTP step", zap.Int64("user_id", id))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return false, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	qb, args, err := u.sq.Update(UsersTable).
+		Set(UsersTOTPLastStep, step).
+		Where(squirrel.Eq{UsersID: id}).
+		Where(squirrel.Lt{UsersTOTPLastStep: step}).
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := conn.Exec(ctx, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.Int64("user_id", id),
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Error("Failed to record TOTP step", zap.Int64("user_id", id), zap.Error(err))
+		}
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		u.logger.Warn("TOTP code already used", zap.Int64("user_id", id))
+		return false, nil
+	}
+	return true, nil
+}
+
+func (u *userQuery) List(ctx context.Context, page *UserPage) ([]*User, error) {
+	u.logger.Debug("Listing users",
+		zap.Int("sort", int(page.Sort)),
+		zap.Bool("descending", page.Descending),
+		zap.Int64("after_id", page.AfterID),
+		zap.Uint64("limit", page.Limit))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	builder := u.sq.Select((&User{}).columns("")...).
+		From(UsersTable).
+		Limit(page.Limit)
+
+	filter := page.Filter
+	if filter.RoleID != 0 {
+		builder = builder.Where(squirrel.Eq{UsersRoleID: filter.RoleID})
+	}
+	if filter.Status != "" {
+		builder = builder.Where(squirrel.Eq{UsersStatus: filter.Status})
+	}
+	if filter.CreatedAfter != nil {
+		builder = builder.Where(squirrel.GtOrEq{UsersCreatedAt: *filter.CreatedAfter})
+	}
+	if filter.CreatedBefore != nil {
+		builder = builder.Where(squirrel.Lt{UsersCreatedAt: *filter.CreatedBefore})
+	}
+	if filter.AuthAfter != nil {
+		builder = builder.Where(squirrel.GtOrEq{UsersAuthTime: *filter.AuthAfter})
+	}
+	if filter.AuthBefore != nil {
+		builder = builder.Where(squirrel.Lt{UsersAuthTime: *filter.AuthBefore})
+	}
+	if filter.Prefix != "" {
+		pattern := escapeLike(strings.ToLower(filter.Prefix)) + "%"
+		builder = builder.Where(squirrel.Or{
+			squirrel.Expr("lower("+UsersUsername+") LIKE ?", pattern),
+			squirrel.Expr("lower("+UsersEmail+") LIKE ?", pattern),
+		})
+	}
+
+	expr := page.Sort.expr()
+	direction, compare := "ASC", ">"
+	if page.Descending {
+		direction, compare = "DESC", "<"
+	}
+	if page.AfterID != 0 {
+		if page.Sort == UserSortID {
+			builder = builder.Where(UsersID+" "+compare+" ?", page.AfterID)
+		} else {
+			builder = builder.Where("("+expr+", "+UsersID+") "+compare+" (?, ?)", page.AfterValue, page.AfterID)
+		}
+	}
+	if page.Sort == UserSortID {
+		builder = builder.OrderBy(UsersID + " " + direction)
+	} else {
+		builder = builder.OrderBy(expr+" "+direction, UsersID+" "+direction)
+	}
+
+	qb, args, err := builder.ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var users []*User
+	err = pgxscan.Select(ctx, conn, &users, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Warn("Failed to list users", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if err := u.decryptSecrets(users...); err != nil {
+		return nil, err
+	}
+	u.logger.Info("Users listed successfully", zap.Int("count", len(users)))
+	return users, nil
+}
+
+// ReencryptSecrets rewraps the token and TOTP secrets of up to limit users
+// after afterID that are not encrypted with the current master key in the
+// current format, including secrets stored before encryption was introduced.
+// The users are locked while their secrets are rewritten; users locked by
+// another replica are skipped.
+func (u *userQuery) ReencryptSecrets(ctx context.Context, afterID int64, limit uint64) (*SecretReencryption, error) {
+	u.logger.Debug("Re-encrypting token secrets", zap.Int64("after_id", afterID), zap.Uint64("limit", limit))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	conn, err := acquireHealthyConn(ctx, u.logger, u.runner)
+	if err != nil {
+		u.logger.Error("Failed to acquire healthy connection", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire healthy connection: %w", err)
+	}
+	defer conn.Release()
+
+	current := escapeLike(u.secrets.CurrentPrefix()) + "%"
+	qb, args, err := u.sq.Select(UsersID, UsersAccessTokenSecret, UsersRefreshTokenSecret, UsersTOTPSecret).
+		From(UsersTable).
+		Where(squirrel.Gt{UsersID: afterID}).
+		Where(squirrel.Or{
+			squirrel.Expr(UsersAccessTokenSecret+" NOT LIKE ?", current),
+			squirrel.Expr(UsersRefreshTokenSecret+" NOT LIKE ?", current),
+			squirrel.Expr(UsersTOTPSecret+" NOT LIKE ?", current),
+		}).
+		OrderBy(UsersID).
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		u.logger.Error("Failed to build query", zap.Error(err))
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		u.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var users []*User
+	err = pgxscan.Select(ctx, tx, &users, qb, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			u.logger.Warn("Database error",
+				zap.String("pg_error_code", pgErr.Code),
+				zap.Error(err),
+			)
+		} else {
+			u.logger.Error("Failed to select token secrets", zap.Error(err))
+		}
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	result := &SecretReencryption{Users: len(users)}
+	for _, user := range users {
+		result.LastID = user.ID
+		accessTokenSecret, err := u.secrets.Rewrap(user.AccessTokenSecret, UsersAccessTokenSecret, user.ID)
+		if err != nil {
+			u.logger.Warn("Failed to re-encrypt access token secret", zap.Int64("user_id", user.ID), zap.Error(err))
+			result.Failed++
+			continue
+		}
+		refreshTokenSecret, err := u.secrets.Rewrap(user.RefreshTokenSecret, UsersRefreshTokenSecret, user.ID)
+		if err != nil {
+			u.logger.Warn("Failed to re-encrypt refresh token secret", zap.Int64("user_id", user.ID), zap.Error(err))
+			result.Failed++
+			continue
+		}
+		totpSecret := user.TOTPSecret
+		if totpSecret != nil {
+			rewrapped, err := u.secrets.Rewrap(*totpSecret, UsersTOTPSecret, user.ID)
+			if err != nil {
+				u.logger.Warn("Failed to re-encrypt TOTP secret", zap.Int64("user_id", user.ID), zap.Error(err))
+				result.Failed++
+				continue
+			}
+			totpSecret = &rewrapped
+		}
+
+		updateQb, updateArgs, err := u.sq.Update(UsersTable).
+			Set(UsersAccessTokenSecret, accessTokenSecret).
+			Set(UsersRefreshTokenSecret, refreshTokenSecret).
+			Set(UsersTOTPSecret, totpSecret).
+			Where(squirrel.Eq{UsersID: user.ID}).
+			ToSql()
+		if err != nil {
+			u.logger.Error("Failed to build query", zap.Error(err))
+			return nil, fmt.Errorf("failed to build query: %w", err)
+		}
+		if _, err := tx.Exec(ctx, updateQb, updateArgs...); err != nil {
+			u.logger.Error("Failed to update token secrets", zap.Int64("user_id", user.ID), zap.Error(err))
+			return nil, fmt.Errorf("failed to execute query: %w", err)
+		}
+		result.Rewrapped++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		u.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if result.Users > 0 {
+		u.logger.Info("Token secrets re-encrypted",
+			zap.Int("rewrapped", result.Rewrapped),
+			zap.Int("failed", result.Failed))
+	}
+	return result, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func GenerateSecretKey() (string, error) {
 	key := make([]byte, 32)
 	_, err := rand.Read(key)
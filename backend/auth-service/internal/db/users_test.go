@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// plainCipher stores the user secrets as they are.
+type plainCipher struct{}
+
+func (plainCipher) Encrypt(plaintext, column string, userID int64) (string, error) {
+	return plaintext, nil
+}
+func (plainCipher) Decrypt(value, column string, userID int64) (string, error) { return value, nil }
+func (plainCipher) Rewrap(value, column string, userID int64) (string, error)  { return value, nil }
+func (plainCipher) CurrentPrefix() string                                      { return "" }
+
+func TestUserGetByEmailIgnoresCase(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	query := NewUserQuery(pool, testSQ, plainCipher{}, zap.NewNop())
+
+	insert := func(username, email string) (*User, error) {
+		return query.Insert(ctx, &User{
+			Username:           username,
+			Password:           "hash",
+			Email:              email,
+			RoleID:             1,
+			AccessTokenSecret:  "access",
+			RefreshTokenSecret: "refresh",
+			Status:             UserStatusActive,
+		})
+	}
+	user, err := insert("jo", "Jo.Doe@Example.com")
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	for _, email := range []string{"Jo.Doe@Example.com", "jo.doe@example.com", "JO.DOE@EXAMPLE.COM"} {
+		got, err := query.GetByEmail(ctx, email)
+		if err != nil {
+			t.Fatalf("GetByEmail(%q) error = %v", email, err)
+		}
+		if got == nil || got.ID != user.ID {
+			t.Errorf("GetByEmail(%q) = %+v, want user %d", email, got, user.ID)
+		}
+	}
+	if got, err := query.GetByEmail(ctx, "jo.doe@example.org"); err != nil || got != nil {
+		t.Errorf("GetByEmail() of another address = %+v, %v, want nil", got, err)
+	}
+
+	// The address stays unique whatever its case.
+	if _, err := insert("joe", "jo.doe@example.com"); !IsUniqueViolation(err) {
+		t.Errorf("Insert() of the same address in other case error = %v, want a unique violation", err)
+	}
+}
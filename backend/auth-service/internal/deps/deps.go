@@ -1,10 +1,17 @@
 package deps
 
 import (
+	"context"
+
 	"github.com/Masterminds/squirrel"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/keys"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/logger"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/mail"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/outbox"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/passwords"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/secrets"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/server"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/service"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,28 +24,105 @@ type Dependencies struct {
 	Logger      *zap.Logger
 	AuthService *service.AuthService
 	AuthServer  *server.AuthServer
+	HTTPServer  *server.HTTPServer
+	KeyManager  *keys.Manager
+	OutboxRelay *outbox.Relay
+	Reencryptor *secrets.Reencryptor
+}
+
+// NewDB builds the queries of every table on top of the pool. Token secrets of
+// users are encrypted with the keyring, audit events are chained with
+// auditKey.
+func NewDB(pool *pgxpool.Pool, keyring *secrets.Keyring, auditKey []byte, log *zap.Logger) db.Implementation {
+	sq := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	return db.NewImplementation(
+		db.NewUserQuery(pool, sq, keyring, log),
+		db.NewRoleQuery(pool, sq, log),
+		db.NewSessionQuery(pool, sq, log),
+		db.NewPasswordResetQuery(pool, sq, log),
+		db.NewEmailVerificationQuery(pool, sq, log),
+		db.NewRecoveryCodeQuery(pool, sq, log),
+		db.NewLoginAttemptQuery(pool, sq, log),
+		db.NewSigningKeyQuery(pool, sq, log),
+		db.NewPermissionQuery(pool, sq, log),
+		db.NewUserStatusChangeQuery(pool, sq, log),
+		db.NewAuditEventQuery(pool, sq, auditKey, log),
+		db.NewOutboxEventQuery(pool, sq, log),
+		db.NewMFAChallengeQuery(pool, sq, log),
+	)
 }
 
 func ProvideDependencies(cfg config.AppConfig) (*Dependencies, error) {
 	log := logger.NewLogger()
 
+	keyring, err := secrets.NewKeyring(cfg)
+	if err != nil {
+		log.Fatal("Failed to init token secret keys", zap.Error(err))
+		return nil, err
+	}
+
 	pool, err := db.InitDB(cfg, log)
 	if err != nil {
 		log.Fatal("Failed to init db", zap.Error(err))
 		return nil, err
 	}
 
-	sq := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	deps := &Dependencies{
-		Pool: pool,
-		DB: db.NewImplementation(
-			db.NewUserQuery(pool, sq, log),
-			db.NewRoleQuery(pool, sq, log),
-		),
+		Pool:   pool,
+		DB:     NewDB(pool, keyring, cfg.AuditHMACKey, log),
 		Logger: log,
 	}
 
-	deps.AuthService = service.NewAuthService(deps.DB, log, cfg)
+	mailer, err := mail.NewMailer(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to init mailer", zap.Error(err))
+		pool.Close()
+		return nil, err
+	}
+
+	deps.KeyManager, err = keys.NewManager(context.Background(), deps.DB.SigningKeyQuery(), cfg, log)
+	if err != nil {
+		log.Fatal("Failed to init signing keys", zap.Error(err))
+		pool.Close()
+		return nil, err
+	}
+	deps.KeyManager.Start()
+
+	deps.Reencryptor = secrets.NewReencryptor(deps.DB.UserQuery(), cfg, log)
+	deps.Reencryptor.Start()
+
+	publisher, err := outbox.NewPublisher(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to init outbox publisher", zap.Error(err))
+		deps.Reencryptor.Stop()
+		deps.KeyManager.Stop()
+		pool.Close()
+		return nil, err
+	}
+	deps.OutboxRelay = outbox.NewRelay(deps.DB.OutboxEventQuery(), publisher, cfg, log)
+	deps.OutboxRelay.Start()
+
+	passwordPolicy, err := passwords.NewPolicy(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to init password policy", zap.Error(err))
+		deps.OutboxRelay.Stop()
+		deps.Reencryptor.Stop()
+		deps.KeyManager.Stop()
+		pool.Close()
+		return nil, err
+	}
+
+	passwordHasher, err := passwords.NewHasher(cfg)
+	if err != nil {
+		log.Fatal("Failed to init password hasher", zap.Error(err))
+		deps.OutboxRelay.Stop()
+		deps.Reencryptor.Stop()
+		deps.KeyManager.Stop()
+		pool.Close()
+		return nil, err
+	}
+
+	deps.AuthService = service.NewAuthService(deps.DB, mailer, deps.KeyManager, passwordPolicy, passwordHasher, log, cfg)
 
 	deps.AuthServer, err = server.NewAuthServer(deps.AuthService, log, cfg.GRPCAddr)
 	if err != nil {
@@ -47,11 +131,24 @@ func ProvideDependencies(cfg config.AppConfig) (*Dependencies, error) {
 		return nil, err
 	}
 
+	deps.HTTPServer, err = server.NewHTTPServer(deps.AuthService, log, cfg.HTTPAddr)
+	if err != nil {
+		log.Fatal("Failed to init http server", zap.Error(err))
+		deps.AuthServer.Stop()
+		pool.Close()
+		return nil, err
+	}
+
 	go func() {
 		if err := <-deps.AuthServer.ErrChan(); err != nil {
 			log.Fatal("gRPC server failed", zap.Error(err))
 		}
 	}()
+	go func() {
+		if err := <-deps.HTTPServer.ErrChan(); err != nil {
+			log.Fatal("HTTP server failed", zap.Error(err))
+		}
+	}()
 
 	log.Info("Dependencies initialized successfully")
 	return deps, nil
@@ -59,7 +156,11 @@ func ProvideDependencies(cfg config.AppConfig) (*Dependencies, error) {
 
 func (d *Dependencies) Cleanup() {
 	d.Logger.Info("Cleaning up dependencies")
+	d.HTTPServer.Stop()
 	d.AuthServer.Stop()
+	d.KeyManager.Stop()
+	d.OutboxRelay.Stop()
+	d.Reencryptor.Stop()
 	d.Logger.Sync()
 	d.Pool.Close()
 }
@@ -0,0 +1,196 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// The RSA key of the RFC 7638 section 3.1 example and its thumbprint.
+const (
+	rfc7638N          = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	rfc7638E          = "AQAB"
+	rfc7638Thumbprint = "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+)
+
+// The Ed25519 key of the RFC 8037 appendix A examples and its thumbprint.
+const (
+	rfc8037D          = "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"
+	rfc8037X          = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	rfc8037Thumbprint = "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"
+)
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func rfc7638Key(t *testing.T) *Key {
+	t.Helper()
+	public := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(decode(t, rfc7638N)),
+		E: int(new(big.Int).SetBytes(decode(t, rfc7638E)).Int64()),
+	}
+	return &Key{Algorithm: AlgorithmRS256, Public: public}
+}
+
+func rfc8037Key(t *testing.T) *Key {
+	t.Helper()
+	key, err := newKey(ed25519.NewKeyFromSeed(decode(t, rfc8037D)))
+	if err != nil {
+		t.Fatalf("newKey() error = %v", err)
+	}
+	return key
+}
+
+func TestThumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  JWK
+		want string
+	}{
+		{name: "RFC 7638 RSA", jwk: rfc7638Key(t).JWK(), want: rfc7638Thumbprint},
+		{name: "RFC 8037 Ed25519", jwk: rfc8037Key(t).JWK(), want: rfc8037Thumbprint},
+		{
+			name: "members outside the thumbprint are ignored",
+			jwk:  JWK{KeyType: "OKP", Curve: "Ed25519", X: rfc8037X, KeyID: "other", Use: "enc", Algorithm: "none"},
+			want: rfc8037Thumbprint,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thumbprint(tt.jwk); got != tt.want {
+				t.Errorf("thumbprint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWK(t *testing.T) {
+	rsaKey := rfc7638Key(t)
+	rsaKey.ID = rfc7638Thumbprint
+	edKey := rfc8037Key(t)
+
+	tests := []struct {
+		name string
+		key  *Key
+		want string
+	}{
+		{
+			name: "RSA",
+			key:  rsaKey,
+			want: `{"kty":"RSA","kid":"` + rfc7638Thumbprint + `","use":"sig","alg":"RS256","n":"` + rfc7638N + `","e":"AQAB"}`,
+		},
+		{
+			name: "Ed25519",
+			key:  edKey,
+			want: `{"kty":"OKP","kid":"` + rfc8037Thumbprint + `","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"` + rfc8037X + `"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.key.JWK())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("JWK() = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigningMethodEdDSA(t *testing.T) {
+	// RFC 8037 appendix A.4.
+	const (
+		signingString = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"
+		signature     = "hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+	)
+	key := rfc8037Key(t)
+	method := key.SigningMethod()
+	if method.Alg() != AlgorithmEdDSA {
+		t.Fatalf("SigningMethod().Alg() = %s, want %s", method.Alg(), AlgorithmEdDSA)
+	}
+
+	got, err := method.Sign(signingString, key.Private)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if got != signature {
+		t.Errorf("Sign() = %s, want %s", got, signature)
+	}
+	if err := method.Verify(signingString, signature, key.Public); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := method.Verify(signingString+"x", signature, key.Public); err != jwt.ErrSignatureInvalid {
+		t.Errorf("Verify() of a changed message error = %v, want %v", err, jwt.ErrSignatureInvalid)
+	}
+	if err := method.Verify(signingString, signature, rfc7638Key(t).Public); err != jwt.ErrInvalidKeyType {
+		t.Errorf("Verify() with an RSA key error = %v, want %v", err, jwt.ErrInvalidKeyType)
+	}
+	if _, err := method.Sign(signingString, key.Public); err != jwt.ErrInvalidKeyType {
+		t.Errorf("Sign() with a public key error = %v, want %v", err, jwt.ErrInvalidKeyType)
+	}
+}
+
+func TestMarshalAndParseKey(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := GenerateKey(algorithm)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			if key.Algorithm != algorithm || key.ID != thumbprint(key.JWK()) {
+				t.Fatalf("GenerateKey() = %s key %s, want %s key named by its thumbprint", key.Algorithm, key.ID, algorithm)
+			}
+			data, err := MarshalKey(key)
+			if err != nil {
+				t.Fatalf("MarshalKey() error = %v", err)
+			}
+			parsed, err := ParseKey(data)
+			if err != nil {
+				t.Fatalf("ParseKey() error = %v", err)
+			}
+			if parsed.ID != key.ID || parsed.Algorithm != key.Algorithm {
+				t.Errorf("ParseKey() = %s key %s, want %s key %s", parsed.Algorithm, parsed.ID, key.Algorithm, key.ID)
+			}
+		})
+	}
+
+	if _, err := GenerateKey("HS256"); err == nil {
+		t.Errorf("GenerateKey() of a symmetric algorithm succeeded")
+	}
+	if _, err := ParseKey([]byte("not pem")); err == nil {
+		t.Errorf("ParseKey() of garbage succeeded")
+	}
+	if _, err := ParseKey([]byte("-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n")); err == nil {
+		t.Errorf("ParseKey() of a public key succeeded")
+	}
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	signing := rfc8037Key(t)
+	previous := rfc7638Key(t)
+	previous.ID = rfc7638Thumbprint
+	set := NewKeySet(signing, previous)
+
+	jwks := set.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != signing.ID || jwks.Keys[1].KeyID != previous.ID {
+		t.Errorf("JWKS() = %+v, want the signing key first and then the previous one", jwks.Keys)
+	}
+	if key, ok := set.Lookup(previous.ID); !ok || key != previous {
+		t.Errorf("Lookup(%s) = %v, %v, want the previous key", previous.ID, key, ok)
+	}
+	if _, ok := set.Lookup("unknown"); ok {
+		t.Errorf("Lookup() of an unknown kid succeeded")
+	}
+}
@@ -0,0 +1,250 @@
+package keys
+
+import (
+	"context"
+	"crypto/cipher"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/secrets"
+	"go.uber.org/zap"
+)
+
+const (
+	// minReloadInterval limits how often a token with an unknown kid may
+	// trigger a reload of the key set.
+	minReloadInterval = 5 * time.Second
+	// retireGrace is added to the access token lifetime before a verify-only
+	// key is retired, to absorb clock skew between replicas.
+	retireGrace = time.Minute
+)
+
+// Manager keeps the key set in sync with the signing_keys table and rotates
+// the active key on schedule. Every replica runs one; the table is the source
+// of truth, so a rotation made by one replica reaches the others on their next
+// refresh, or as soon as they see a token signed with an unknown kid.
+type Manager struct {
+	query  db.SigningKeyQuery
+	aead   cipher.AEAD
+	config config.AppConfig
+	logger *zap.Logger
+
+	mu          sync.RWMutex
+	set         *KeySet
+	activeSince time.Time
+	lastReload  time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewManager loads the published keys and, on the very first start, creates
+// the initial active key from JWT_SIGNING_KEY_FILE or a freshly generated one.
+func NewManager(ctx context.Context, query db.SigningKeyQuery, cfg config.AppConfig, logger *zap.Logger) (*Manager, error) {
+	aead, err := secrets.NewAEAD(cfg.SigningKeyEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SIGNING_KEY_ENCRYPTION_KEY: %w", err)
+	}
+	m := &Manager{
+		query:  query,
+		aead:   aead,
+		config: cfg,
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if err := m.reload(ctx); err != nil {
+		return nil, err
+	}
+	if m.Current() == nil {
+		var key *Key
+		if cfg.JWTSigningKeyFile != "" {
+			key, err = LoadKey(cfg.JWTSigningKeyFile)
+		} else {
+			key, err = GenerateKey(cfg.JWTSigningAlgorithm)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := m.store(ctx, "", "", key); err != nil {
+			return nil, err
+		}
+		if err := m.reload(ctx); err != nil {
+			return nil, err
+		}
+		if m.Current() == nil {
+			return nil, fmt.Errorf("no active signing key")
+		}
+	}
+	return m, nil
+}
+
+// Current returns the key set as of the last reload.
+func (m *Manager) Current() *KeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.set
+}
+
+// Lookup finds a verification key by kid, reloading the key set once if the
+// kid is unknown so that keys rotated in by other replicas are picked up
+// without waiting for the next refresh.
+func (m *Manager) Lookup(ctx context.Context, kid string) (*Key, bool) {
+	m.mu.RLock()
+	key, ok := m.set.Lookup(kid)
+	stale := time.Since(m.lastReload) >= minReloadInterval
+	m.mu.RUnlock()
+	if ok || !stale {
+		return key, ok
+	}
+
+	if err := m.reload(ctx); err != nil {
+		m.logger.Warn("Failed to reload signing keys", zap.Error(err))
+		return nil, false
+	}
+	return m.Current().Lookup(kid)
+}
+
+// Rotate makes a new key active. The previous key stays valid for
+// verification unless revokePrevious is set, in which case it is retired at
+// once and every token it signed stops being accepted.
+func (m *Manager) Rotate(ctx context.Context, revokePrevious bool) (*Key, error) {
+	key, err := GenerateKey(m.config.JWTSigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	previousState := db.SigningKeyVerifyOnly
+	if revokePrevious {
+		previousState = db.SigningKeyRetired
+	}
+	ok, err := m.store(ctx, m.Current().Signing().ID, previousState, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.reload(ctx); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("signing key was rotated concurrently")
+	}
+
+	m.logger.Info("Signing key rotated",
+		zap.String("kid", key.ID),
+		zap.String("algorithm", key.Algorithm),
+		zap.Bool("revoke_previous", revokePrevious))
+	return key, nil
+}
+
+// Start runs the refresh and rotation schedule until Stop is called.
+func (m *Manager) Start() {
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.config.JWT_KEY_REFRESH_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.tick()
+			}
+		}
+	}()
+}
+
+func (m *Manager) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+func (m *Manager) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := m.reload(ctx); err != nil {
+		m.logger.Warn("Failed to reload signing keys", zap.Error(err))
+		return
+	}
+
+	m.mu.RLock()
+	due := time.Since(m.activeSince) >= m.config.JWT_KEY_ROTATION_INTERVAL
+	m.mu.RUnlock()
+	if due {
+		if _, err := m.Rotate(ctx, false); err != nil {
+			m.logger.Warn("Scheduled signing key rotation failed", zap.Error(err))
+		}
+	}
+
+	cutoff := time.Now().Add(-m.config.ACCESS_TOKEN_EXPIRES_IN - retireGrace)
+	retired, err := m.query.RetireRotatedBefore(ctx, cutoff)
+	if err != nil {
+		m.logger.Warn("Failed to retire signing keys", zap.Error(err))
+		return
+	}
+	if retired > 0 {
+		if err := m.reload(ctx); err != nil {
+			m.logger.Warn("Failed to reload signing keys", zap.Error(err))
+		}
+	}
+}
+
+func (m *Manager) store(ctx context.Context, previousID, previousState string, key *Key) (bool, error) {
+	pemKey, err := MarshalKey(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	sealed, err := secrets.Seal(m.aead, pemKey, key.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+	return m.query.Rotate(ctx, previousID, previousState, &db.SigningKey{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: sealed,
+		State:      db.SigningKeyActive,
+	})
+}
+
+func (m *Manager) reload(ctx context.Context) error {
+	rows, err := m.query.ListPublished(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	var active *Key
+	var activeSince time.Time
+	var verifyOnly []*Key
+	for _, row := range rows {
+		pemKey, err := secrets.Open(m.aead, row.PrivateKey, row.ID)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt signing key %s: %w", row.ID, err)
+		}
+		key, err := ParseKey(pemKey)
+		if err != nil {
+			return fmt.Errorf("failed to parse signing key %s: %w", row.ID, err)
+		}
+		if row.State == db.SigningKeyActive {
+			active = key
+			if row.CreatedAt != nil {
+				activeSince = *row.CreatedAt
+			}
+		} else {
+			verifyOnly = append(verifyOnly, key)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastReload = time.Now()
+	if active == nil {
+		return nil
+	}
+	m.set = NewKeySet(active, verifyOnly...)
+	m.activeSince = activeSince
+	return nil
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type logMailer struct {
+	dir    string
+	logger *zap.Logger
+}
+
+// NewLogMailer returns a Mailer for local development. Messages are written to
+// the log and, when dir is set, saved as one file per message in dir.
+func NewLogMailer(dir string, logger *zap.Logger) Mailer {
+	return &logMailer{
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.Info("Mail message",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		m.logger.Error("Failed to create mail directory", zap.String("dir", m.dir), zap.Error(err))
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, formatMessage("", msg), 0o600); err != nil {
+		m.logger.Error("Failed to write mail message", zap.String("path", path), zap.Error(err))
+		return fmt.Errorf("failed to write mail message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"go.uber.org/zap"
+)
+
+const (
+	BackendLog  = "log"
+	BackendSMTP = "smtp"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer picks the delivery backend from MAIL_BACKEND. The log backend is
+// the default so that local development works without an SMTP server.
+func NewMailer(cfg config.AppConfig, logger *zap.Logger) (Mailer, error) {
+	switch cfg.MailBackend {
+	case "", BackendLog:
+		return NewLogMailer(cfg.MailFileDir, logger), nil
+	case BackendSMTP:
+		if cfg.SMTPHost == "" || cfg.SMTPPort == "" || cfg.MailFrom == "" {
+			return nil, fmt.Errorf("SMTP_HOST, SMTP_PORT and MAIL_FROM are required for the smtp mail backend")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail backend %q", cfg.MailBackend)
+	}
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type grpcPublisher struct {
+	conn   *grpc.ClientConn
+	client pb.UserEventSinkClient
+	logger *zap.Logger
+}
+
+// NewGRPCPublisher returns a Publisher that calls UserEventSink.PublishUserEvent
+// on addr. The connection is made lazily, so a consumer that is down at start
+// only delays delivery.
+func NewGRPCPublisher(addr string, logger *zap.Logger) (Publisher, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc client: %w", err)
+	}
+	return &grpcPublisher{
+		conn:   conn,
+		client: pb.NewUserEventSinkClient(conn),
+		logger: logger,
+	}, nil
+}
+
+func (p *grpcPublisher) Publish(ctx context.Context, event Event) error {
+	p.logger.Debug("Sending outbox event", zap.Int64("event_id", event.ID), zap.String("target", p.conn.Target()))
+
+	var user pb.UserSnapshot
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(event.Payload, &user); err != nil {
+		return fmt.Errorf("failed to decode event payload: %w", err)
+	}
+	_, err := p.client.PublishUserEvent(ctx, &pb.PublishUserEventRequest{
+		Event: &pb.UserEvent{
+			Id:        event.ID,
+			Type:      event.Type,
+			CreatedAt: event.CreatedAt.Unix(),
+			User:      &user,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	return nil
+}
+
+func (p *grpcPublisher) Close() error {
+	return p.conn.Close()
+}
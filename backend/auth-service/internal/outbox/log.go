@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type logPublisher struct {
+	logger *zap.Logger
+}
+
+// NewLogPublisher returns a Publisher for local development that writes every
+// event to the log and reports it as delivered.
+func NewLogPublisher(logger *zap.Logger) Publisher {
+	return &logPublisher{logger: logger}
+}
+
+func (p *logPublisher) Publish(ctx context.Context, event Event) error {
+	p.logger.Info("Outbox event",
+		zap.Int64("event_id", event.ID),
+		zap.String("type", event.Type),
+		zap.Int64("user_id", event.UserID),
+		zap.ByteString("payload", event.Payload),
+	)
+	return nil
+}
+
+func (p *logPublisher) Close() error {
+	return nil
+}
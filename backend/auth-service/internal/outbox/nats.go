@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// EmbeddedNATSStream is the JetStream stream the embedded NATS server keeps
+// the events in. Its subjects are the event types, such as user.registered.
+const EmbeddedNATSStream = "USER_EVENTS"
+
+type natsPublisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	logger *zap.Logger
+
+	// Set only for the embedded server.
+	server      *server.Server
+	tempDataDir string
+}
+
+// NewNATSPublisher returns a Publisher that publishes every event to
+// JetStream on url, with the event type as the subject. An event counts as
+// accepted once a stream has stored it, so a stream must capture the user.>
+// subjects. The event ID is the JetStream message ID, which drops redeliveries
+// within the duplicate window of the stream. The connection is retried in the
+// background, so a server that is down at start only delays delivery.
+func NewNATSPublisher(url string, logger *zap.Logger) (Publisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("auth-service outbox"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+	return &natsPublisher{conn: conn, js: js, logger: logger}, nil
+}
+
+// NewEmbeddedNATSPublisher starts a NATS server with JetStream inside the
+// process, for local testing without a broker, and publishes to it like
+// NewNATSPublisher. The server listens on addr, so that consumers can
+// subscribe to EmbeddedNATSStream; port -1 picks a free port. The stream is
+// kept in dataDir, or in a temporary directory removed on Close when dataDir is
+// empty.
+func NewEmbeddedNATSPublisher(addr, dataDir string, logger *zap.Logger) (Publisher, error) {
+	host, portText, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded nats address: %w", err)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embedded nats port: %w", err)
+	}
+	p := &natsPublisher{logger: logger}
+	if dataDir == "" {
+		if dataDir, err = os.MkdirTemp("", "auth-outbox-nats-"); err != nil {
+			return nil, fmt.Errorf("failed to create nats data directory: %w", err)
+		}
+		p.tempDataDir = dataDir
+	}
+
+	p.server, err = server.NewServer(&server.Options{
+		ServerName: "auth-service-outbox",
+		Host:       host,
+		Port:       port,
+		JetStream:  true,
+		StoreDir:   dataDir,
+		NoSigs:     true,
+	})
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to create embedded nats server: %w", err)
+	}
+	p.server.Start()
+	if !p.server.ReadyForConnections(10 * time.Second) {
+		p.Close()
+		return nil, fmt.Errorf("embedded nats server did not start")
+	}
+
+	p.conn, err = nats.Connect(p.server.ClientURL(), nats.Name("auth-service outbox"))
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to connect to embedded nats: %w", err)
+	}
+	if p.js, err = jetstream.New(p.conn); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = p.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     EmbeddedNATSStream,
+		Subjects: []string{"user.>"},
+	})
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to create nats stream: %w", err)
+	}
+
+	logger.Info("Embedded NATS server started",
+		zap.String("url", p.server.ClientURL()),
+		zap.String("stream", EmbeddedNATSStream),
+		zap.String("data_dir", dataDir))
+	return p, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, event Event) error {
+	p.logger.Debug("Publishing outbox event", zap.Int64("event_id", event.ID), zap.String("subject", event.Type))
+
+	body, err := json.Marshal(eventBody{
+		ID:        event.ID,
+		Type:      event.Type,
+		UserID:    event.UserID,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	msg := nats.NewMsg(event.Type)
+	msg.Data = body
+	if _, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(strconv.FormatInt(event.ID, 10))); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+func (p *natsPublisher) Close() error {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	if p.server != nil {
+		p.server.Shutdown()
+		p.server.WaitForShutdown()
+	}
+	if p.tempDataDir != "" {
+		return os.RemoveAll(p.tempDataDir)
+	}
+	return nil
+}
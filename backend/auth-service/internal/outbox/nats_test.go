@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+func TestEmbeddedNATSPublisher(t *testing.T) {
+	publisher, err := NewEmbeddedNATSPublisher("127.0.0.1:-1", t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewEmbeddedNATSPublisher() error = %v", err)
+	}
+	defer publisher.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event := Event{
+		ID:        7,
+		Type:      "user.email_changed",
+		UserID:    42,
+		Payload:   json.RawMessage(`{"user_id":42,"email":"jo@example.com"}`),
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+	}
+	for i := 0; i < 2; i++ {
+		if err := publisher.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	conn, err := nats.Connect(publisher.(*natsPublisher).server.ClientURL())
+	if err != nil {
+		t.Fatalf("nats.Connect() error = %v", err)
+	}
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("jetstream.New() error = %v", err)
+	}
+	stream, err := js.Stream(ctx, EmbeddedNATSStream)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if info.State.Msgs != 1 {
+		t.Errorf("stream holds %d messages, want 1: a redelivered event must be dropped", info.State.Msgs)
+	}
+
+	msg, err := stream.GetLastMsgForSubject(ctx, event.Type)
+	if err != nil {
+		t.Fatalf("GetLastMsgForSubject() error = %v", err)
+	}
+	var body eventBody
+	if err := json.Unmarshal(msg.Data, &body); err != nil {
+		t.Fatalf("message is not JSON: %v", err)
+	}
+	if body.ID != event.ID || body.Type != event.Type || body.UserID != event.UserID || !body.CreatedAt.Equal(event.CreatedAt) {
+		t.Errorf("message = %+v, want the fields of %+v", body, event)
+	}
+	if string(body.Data) != string(event.Payload) {
+		t.Errorf("message data = %s, want %s", body.Data, event.Payload)
+	}
+}
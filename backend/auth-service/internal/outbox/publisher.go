@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"go.uber.org/zap"
+)
+
+const (
+	BackendLog     = "log"
+	BackendWebhook = "webhook"
+	BackendGRPC    = "grpc"
+	BackendNATS    = "nats"
+)
+
+// Event is an outbox event on its way to a consumer. ID is unique and stable
+// across redeliveries, so consumers can use it to drop duplicates.
+type Event struct {
+	ID        int64
+	Type      string
+	UserID    int64
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// Publisher delivers events to consumers. Publish returns nil only once the
+// consumer has accepted the event; any error makes the relay try again later.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// NewPublisher picks the delivery backend from OUTBOX_BACKEND. The log backend
+// is the default so that local development works without a consumer.
+func NewPublisher(cfg config.AppConfig, logger *zap.Logger) (Publisher, error) {
+	switch cfg.OutboxBackend {
+	case "", BackendLog:
+		return NewLogPublisher(logger), nil
+	case BackendWebhook:
+		if cfg.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the webhook outbox backend")
+		}
+		return NewWebhookPublisher(cfg.OutboxWebhookURL, cfg.OutboxWebhookSecret, logger), nil
+	case BackendGRPC:
+		if cfg.OutboxGRPCAddr == "" {
+			return nil, fmt.Errorf("OUTBOX_GRPC_ADDR is required for the grpc outbox backend")
+		}
+		return NewGRPCPublisher(cfg.OutboxGRPCAddr, logger)
+	case BackendNATS:
+		if cfg.OutboxNATSURL == "" {
+			return NewEmbeddedNATSPublisher(cfg.OutboxNATSEmbeddedAddr, cfg.OutboxNATSDataDir, logger)
+		}
+		return NewNATSPublisher(cfg.OutboxNATSURL, logger)
+	default:
+		return nil, fmt.Errorf("unknown outbox backend %q", cfg.OutboxBackend)
+	}
+}
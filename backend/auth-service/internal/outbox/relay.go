@@ -0,0 +1,164 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+)
+
+const (
+	// claimLease is how long a batch stays claimed by one relay. Events the
+	// relay has not reported on by then are picked up again, by any replica.
+	claimLease = time.Minute
+	// publishTimeout bounds a single delivery attempt.
+	publishTimeout = 10 * time.Second
+	// cleanupInterval is how often published events past the retention period
+	// are deleted.
+	cleanupInterval = time.Hour
+)
+
+// Relay moves events from the outbox table to the publisher. Every replica
+// runs one; claims keep them from delivering the same event concurrently.
+// Delivery is at least once: an event is marked published only after the
+// publisher accepted it, and a relay that stops in between leaves it to be
+// delivered again.
+type Relay struct {
+	query     db.OutboxEventQuery
+	publisher Publisher
+	config    config.AppConfig
+	logger    *zap.Logger
+
+	lastCleanup time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewRelay(query db.OutboxEventQuery, publisher Publisher, cfg config.AppConfig, logger *zap.Logger) *Relay {
+	return &Relay{
+		query:     query,
+		publisher: publisher,
+		config:    cfg,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start polls the outbox until Stop is called.
+func (r *Relay) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.config.OUTBOX_POLL_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.tick()
+			}
+		}
+	}()
+}
+
+// Stop waits for the batch in flight and closes the publisher.
+func (r *Relay) Stop() {
+	close(r.stop)
+	<-r.done
+	if err := r.publisher.Close(); err != nil {
+		r.logger.Warn("Failed to close outbox publisher", zap.Error(err))
+	}
+}
+
+func (r *Relay) tick() {
+	// A full batch means more events are probably waiting, so keep going
+	// without waiting for the next tick.
+	for r.relayBatch() == r.config.OutboxBatchSize {
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+	}
+
+	if time.Since(r.lastCleanup) >= cleanupInterval {
+		r.lastCleanup = time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := r.query.DeletePublishedBefore(ctx, time.Now().Add(-r.config.OUTBOX_RETENTION)); err != nil {
+			r.logger.Warn("Failed to delete published outbox events", zap.Error(err))
+		}
+	}
+}
+
+// relayBatch delivers one batch of due events and returns its size.
+func (r *Relay) relayBatch() int {
+	ctx, cancel := context.WithTimeout(context.Background(), claimLease)
+	defer cancel()
+
+	events, err := r.query.Claim(ctx, uint64(r.config.OutboxBatchSize), claimLease)
+	if err != nil {
+		r.logger.Warn("Failed to claim outbox events", zap.Error(err))
+		return 0
+	}
+	for _, event := range events {
+		if ctx.Err() != nil {
+			// The lease is over; the remaining events are up for grabs again.
+			break
+		}
+		r.deliver(ctx, event)
+	}
+	return len(events)
+}
+
+func (r *Relay) deliver(ctx context.Context, event *db.OutboxEvent) {
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	err := r.publisher.Publish(publishCtx, Event{
+		ID:        event.ID,
+		Type:      event.Type,
+		UserID:    event.UserID,
+		Payload:   event.Payload,
+		CreatedAt: event.CreatedAt,
+	})
+	cancel()
+
+	// The outcome is recorded even if the lease ran out meanwhile.
+	markCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err != nil {
+		retryIn := r.retryDelay(event.Attempts)
+		r.logger.Warn("Failed to publish outbox event",
+			zap.Int64("event_id", event.ID),
+			zap.String("type", event.Type),
+			zap.Int("attempts", event.Attempts),
+			zap.Duration("retry_in", retryIn),
+			zap.Error(err))
+		if err := r.query.MarkFailed(markCtx, event.ID, time.Now().Add(retryIn), err.Error()); err != nil {
+			r.logger.Warn("Failed to record outbox delivery failure", zap.Int64("event_id", event.ID), zap.Error(err))
+		}
+		return
+	}
+	if err := r.query.MarkPublished(markCtx, event.ID); err != nil {
+		// The event will be delivered again once the lease runs out.
+		r.logger.Warn("Failed to mark outbox event as published", zap.Int64("event_id", event.ID), zap.Error(err))
+		return
+	}
+	r.logger.Info("Outbox event published",
+		zap.Int64("event_id", event.ID),
+		zap.String("type", event.Type),
+		zap.Int64("user_id", event.UserID))
+}
+
+// retryDelay doubles the base delay for every failed attempt, capped at the
+// configured maximum.
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	delay := r.config.OUTBOX_RETRY_BASE
+	for i := 1; i < attempts && delay < r.config.OUTBOX_RETRY_MAX; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.OUTBOX_RETRY_MAX)
+}
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+)
+
+type fakeOutboxQuery struct {
+	db.OutboxEventQuery
+	events    []*db.OutboxEvent
+	published []int64
+	failed    map[int64]time.Time
+}
+
+func (f *fakeOutboxQuery) Claim(ctx context.Context, limit uint64, lease time.Duration) ([]*db.OutboxEvent, error) {
+	events := f.events
+	f.events = nil
+	return events, nil
+}
+
+func (f *fakeOutboxQuery) MarkPublished(ctx context.Context, id int64) error {
+	f.published = append(f.published, id)
+	return nil
+}
+
+func (f *fakeOutboxQuery) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	f.failed[id] = nextAttemptAt
+	return nil
+}
+
+type fakePublisher struct {
+	delivered []int64
+	fail      map[int64]bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event Event) error {
+	if p.fail[event.ID] {
+		return errors.New("consumer down")
+	}
+	p.delivered = append(p.delivered, event.ID)
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func TestRelayBatch(t *testing.T) {
+	query := &fakeOutboxQuery{
+		events: []*db.OutboxEvent{
+			{ID: 1, Type: db.OutboxUserRegistered, UserID: 7, Attempts: 1},
+			{ID: 2, Type: db.OutboxUserRegistered, UserID: 8, Attempts: 3},
+			{ID: 3, Type: db.OutboxUserRoleChanged, UserID: 7, Attempts: 1},
+		},
+		failed: make(map[int64]time.Time),
+	}
+	publisher := &fakePublisher{fail: map[int64]bool{2: true}}
+	cfg := config.AppConfig{OutboxBatchSize: 10, OUTBOX_RETRY_BASE: time.Second, OUTBOX_RETRY_MAX: time.Minute}
+	relay := NewRelay(query, publisher, cfg, zap.NewNop())
+
+	start := time.Now()
+	if got := relay.relayBatch(); got != 3 {
+		t.Errorf("relayBatch() = %d, want 3", got)
+	}
+	if !slices.Equal(publisher.delivered, []int64{1, 3}) {
+		t.Errorf("delivered %v, want [1 3]", publisher.delivered)
+	}
+	if !slices.Equal(query.published, []int64{1, 3}) {
+		t.Errorf("marked published %v, want [1 3]", query.published)
+	}
+	// The third attempt failed, so the next one is due after 4s.
+	next, ok := query.failed[2]
+	if !ok || len(query.failed) != 1 {
+		t.Fatalf("marked failed %v, want event 2", query.failed)
+	}
+	if next.Before(start.Add(4*time.Second)) || next.After(time.Now().Add(4*time.Second)) {
+		t.Errorf("event 2 next attempt in %v, want 4s", next.Sub(start))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	relay := &Relay{config: config.AppConfig{OUTBOX_RETRY_BASE: time.Second, OUTBOX_RETRY_MAX: time.Minute}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 6, want: 32 * time.Second},
+		{attempts: 7, want: time.Minute},
+		{attempts: 1000, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := relay.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed with
+// OUTBOX_WEBHOOK_SECRET, when a secret is configured.
+const SignatureHeader = "X-Signature-SHA256"
+
+type webhookPublisher struct {
+	url    string
+	secret []byte
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewWebhookPublisher returns a Publisher that POSTs every event as JSON to
+// url. Any 2xx response counts as accepted.
+func NewWebhookPublisher(url, secret string, logger *zap.Logger) Publisher {
+	return &webhookPublisher{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// eventBody is the JSON form of an event sent by the webhook and NATS
+// backends.
+type eventBody struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	UserID    int64           `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (p *webhookPublisher) Publish(ctx context.Context, event Event) error {
+	p.logger.Debug("Posting outbox event", zap.Int64("event_id", event.ID), zap.String("url", p.url))
+
+	body, err := json.Marshal(eventBody{
+		ID:        event.ID,
+		Type:      event.Type,
+		UserID:    event.UserID,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", event.Type)
+	if len(p.secret) > 0 {
+		mac := hmac.New(sha256.New, p.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post event: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func (p *webhookPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,178 @@
+package passwords
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+)
+
+// rfcArgon2idHash is the argon2id vector of the reference implementation's
+// test suite: "password" with salt "somesalt", t=2, m=2^16, p=1.
+const rfcArgon2idHash = "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+
+func TestArgon2idVerifyKnownAnswer(t *testing.T) {
+	a := &Argon2id{}
+	if ok, err := a.Verify(rfcArgon2idHash, "password"); err != nil || !ok {
+		t.Errorf("Verify() = %v, %v, want true", ok, err)
+	}
+	if ok, err := a.Verify(rfcArgon2idHash, "passwore"); err != nil || ok {
+		t.Errorf("Verify() with a wrong password = %v, %v, want false", ok, err)
+	}
+}
+
+func TestParseArgon2id(t *testing.T) {
+	tests := []struct {
+		name        string
+		hash        string
+		memory      uint32
+		time        uint32
+		parallelism uint8
+		saltLength  int
+		keyLength   int
+		wantErr     bool
+	}{
+		{name: "reference vector", hash: rfcArgon2idHash, memory: 65536, time: 2, parallelism: 1, saltLength: 8, keyLength: 32},
+		{name: "other parameters", hash: "$argon2id$v=19$m=19456,t=3,p=4$c29tZXNhbHRzb21lc2FsdA$AAAAAAAAAAAAAAAAAAAAAA", memory: 19456, time: 3, parallelism: 4, saltLength: 16, keyLength: 16},
+		{name: "argon2i", hash: "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "old version", hash: "$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "missing version", hash: "$argon2id$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "malformed parameters", hash: "$argon2id$v=19$m=65536;t=2;p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "zero time", hash: "$argon2id$v=19$m=65536,t=0,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "zero parallelism", hash: "$argon2id$v=19$m=65536,t=2,p=0$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "padded salt", hash: "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ=$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", wantErr: true},
+		{name: "empty key", hash: "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$", wantErr: true},
+		{name: "extra field", hash: rfcArgon2idHash + "$x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseArgon2id(tt.hash)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseArgon2id() = %+v, want an error", parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgon2id() error = %v", err)
+			}
+			if parsed.memory != tt.memory || parsed.time != tt.time || parsed.parallelism != tt.parallelism ||
+				len(parsed.salt) != tt.saltLength || len(parsed.key) != tt.keyLength {
+				t.Errorf("parseArgon2id() = m=%d t=%d p=%d salt=%d key=%d, want m=%d t=%d p=%d salt=%d key=%d",
+					parsed.memory, parsed.time, parsed.parallelism, len(parsed.salt), len(parsed.key),
+					tt.memory, tt.time, tt.parallelism, tt.saltLength, tt.keyLength)
+			}
+		})
+	}
+}
+
+func TestArgon2idHash(t *testing.T) {
+	a := &Argon2id{Memory: 64, Time: 1, Parallelism: 2}
+	hash, err := a.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=2$") {
+		t.Errorf("Hash() = %q, want the configured parameters", hash)
+	}
+	if ok, err := a.Verify(hash, "password"); err != nil || !ok {
+		t.Errorf("Verify() of a fresh hash = %v, %v, want true", ok, err)
+	}
+	if other, _ := a.Hash("password"); other == hash {
+		t.Errorf("Hash() returned the same hash twice, want a fresh salt")
+	}
+	if a.Outdated(hash) {
+		t.Errorf("Outdated() of a fresh hash = true")
+	}
+}
+
+func TestArgon2idOutdated(t *testing.T) {
+	a := &Argon2id{Memory: 65536, Time: 2, Parallelism: 1}
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{name: "same parameters", hash: "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", want: false},
+		{name: "stronger parameters", hash: "$argon2id$v=19$m=131072,t=3,p=1$c29tZXNhbHRzb21lc2FsdA$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", want: false},
+		{name: "other parallelism", hash: "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHRzb21lc2FsdA$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", want: false},
+		{name: "less memory", hash: "$argon2id$v=19$m=32768,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", want: true},
+		{name: "fewer passes", hash: "$argon2id$v=19$m=65536,t=1,p=1$c29tZXNhbHRzb21lc2FsdA$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", want: true},
+		// The reference vector has the configured parameters but an 8 byte
+		// salt, shorter than the one Hash uses.
+		{name: "short salt", hash: rfcArgon2idHash, want: true},
+		{name: "short key", hash: "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$AAAAAAAAAAAAAAAAAAAAAA", want: true},
+		{name: "malformed", hash: "$argon2id$v=19$garbage", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Outdated(tt.hash); got != tt.want {
+				t.Errorf("Outdated(%q) = %v, want %v", tt.hash, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasherVerify(t *testing.T) {
+	newHasher := func(algorithm string) *Hasher {
+		t.Helper()
+		h, err := NewHasher(config.AppConfig{
+			PasswordHashAlgorithm: algorithm,
+			Argon2Memory:          64,
+			Argon2Time:            1,
+			Argon2Parallelism:     1,
+			BcryptCost:            5,
+		})
+		if err != nil {
+			t.Fatalf("NewHasher() error = %v", err)
+		}
+		return h
+	}
+	argon2idHasher := newHasher(config.HashAlgorithmArgon2id)
+	bcryptHasher := newHasher(config.HashAlgorithmBcrypt)
+	current, err := argon2idHasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weaker, err := (&Argon2id{Memory: 32, Time: 1, Parallelism: 1}).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash := "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+
+	tests := []struct {
+		name       string
+		hasher     *Hasher
+		hash       string
+		password   string
+		wantOK     bool
+		wantRehash bool
+		wantErr    error
+	}{
+		{name: "current hash", hasher: argon2idHasher, hash: current, password: "password", wantOK: true},
+		{name: "wrong password", hasher: argon2idHasher, hash: current, password: "wrong"},
+		{name: "weaker argon2id", hasher: argon2idHasher, hash: weaker, password: "password", wantOK: true, wantRehash: true},
+		{name: "bcrypt under argon2id", hasher: argon2idHasher, hash: bcryptHash, password: "U*U", wantOK: true, wantRehash: true},
+		{name: "bcrypt under bcrypt", hasher: bcryptHasher, hash: bcryptHash, password: "U*U", wantOK: true},
+		{name: "argon2id under bcrypt", hasher: bcryptHasher, hash: current, password: "password", wantOK: true, wantRehash: true},
+		{name: "md5-crypt", hasher: argon2idHasher, hash: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", password: "password", wantOK: true, wantRehash: true},
+		{name: "wrong md5-crypt password", hasher: argon2idHasher, hash: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", password: "wrong"},
+		{name: "unknown scheme", hasher: argon2idHasher, hash: "5f4dcc3b5aa765d61d8327deb882cf99", password: "password", wantErr: ErrUnknownHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := tt.hasher.Verify(tt.hash, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("Verify() = %v, %v, want %v, %v", ok, rehash, tt.wantOK, tt.wantRehash)
+			}
+		})
+	}
+
+	if _, err := NewHasher(config.AppConfig{PasswordHashAlgorithm: "scrypt"}); err == nil {
+		t.Errorf("NewHasher() with an unknown algorithm succeeded")
+	}
+}
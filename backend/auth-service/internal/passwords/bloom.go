@@ -0,0 +1,131 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// breachedFalsePositiveRate is the share of passwords the filter wrongly
+// reports as breached.
+const breachedFalsePositiveRate = 0.001
+
+// BloomFilter is a set of SHA-1 digests that may report false positives but
+// never false negatives. It keeps a large breached-password list in a few
+// bits per entry.
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// NewBloomFilter sizes a filter for n entries at the given false positive
+// rate.
+func NewBloomFilter(n int, falsePositiveRate float64) *BloomFilter {
+	n = max(n, 1)
+	size := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Max(1, math.Round(float64(size)/float64(n)*math.Ln2)))
+	return &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// The digests are uniformly distributed already, so their halves serve as
+// the two hashes of double hashing.
+func (f *BloomFilter) positions(digest [sha1.Size]byte, fn func(uint64)) {
+	h1 := binary.LittleEndian.Uint64(digest[0:8])
+	h2 := binary.LittleEndian.Uint64(digest[8:16]) | 1
+	for i := uint64(0); i < f.hashes; i++ {
+		fn((h1 + i*h2) % f.size)
+	}
+}
+
+func (f *BloomFilter) Add(digest [sha1.Size]byte) {
+	f.positions(digest, func(pos uint64) {
+		f.bits[pos/64] |= 1 << (pos % 64)
+	})
+}
+
+func (f *BloomFilter) Contains(digest [sha1.Size]byte) bool {
+	found := true
+	f.positions(digest, func(pos uint64) {
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
+
+// LoadBreachedList reads a breached-password list into a filter. Each line
+// is either a password or the hex SHA-1 of one, optionally followed by
+// ":count" as in the Have I Been Pwned downloads. It returns the filter and
+// the number of entries.
+func LoadBreachedList(path string) (*BloomFilter, int, error) {
+	n, err := countLines(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter := NewBloomFilter(n, breachedFalsePositiveRate)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	entries := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		filter.Add(breachedEntryDigest(line))
+		entries++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return filter, entries, nil
+}
+
+// breachedEntryDigest returns the SHA-1 of the password on a line of the
+// list. A trailing ":count" is dropped from both formats; a colon that is not
+// followed by digits only is part of the password.
+func breachedEntryDigest(line string) [sha1.Size]byte {
+	if i := strings.LastIndexByte(line, ':'); i >= 0 && i < len(line)-1 && isDigits(line[i+1:]) {
+		line = line[:i]
+	}
+	var digest [sha1.Size]byte
+	if len(line) == sha1.Size*2 {
+		if _, err := hex.Decode(digest[:], []byte(line)); err == nil {
+			return digest
+		}
+	}
+	return sha1.Sum([]byte(line))
+}
+
+func countLines(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return n, nil
+}
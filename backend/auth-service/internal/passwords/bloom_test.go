@@ -0,0 +1,93 @@
+package passwords
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBreachedEntryDigest(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		password string
+	}{
+		{name: "plain text", line: "password", password: "password"},
+		{name: "plain text with count", line: "password:3861493", password: "password"},
+		{name: "hash", line: "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", password: "password"},
+		{name: "upper case hash with count", line: "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493", password: "password"},
+		{name: "colon in password", line: "pass:word", password: "pass:word"},
+		{name: "colon in password with count", line: "pass:word:12", password: "pass:word"},
+		{name: "trailing colon", line: "password:", password: "password:"},
+		{name: "not quite a hash", line: "5baa61e4c9b93f3f0682250b6cf8331b7ee68fdz", password: "5baa61e4c9b93f3f0682250b6cf8331b7ee68fdz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := breachedEntryDigest(tt.line), sha1.Sum([]byte(tt.password)); got != want {
+				t.Errorf("breachedEntryDigest(%q) = %x, want SHA-1 of %q", tt.line, got, tt.password)
+			}
+		})
+	}
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	lines := []string{
+		"123456:37359195",
+		"qwerty",
+		"",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493",
+		"7c4a8d09ca3762af61e59520943dc26494f8941b",
+		"letmein:12\r",
+	}
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, entries, err := LoadBreachedList(path)
+	if err != nil {
+		t.Fatalf("LoadBreachedList() error = %v", err)
+	}
+	if entries != 5 {
+		t.Errorf("LoadBreachedList() entries = %d, want 5", entries)
+	}
+	for _, password := range []string{"123456", "qwerty", "password", "letmein"} {
+		if !filter.Contains(sha1.Sum([]byte(password))) {
+			t.Errorf("Contains(%q) = false, want true", password)
+		}
+	}
+	for _, password := range []string{"123456:37359195", "letmein:12", "correct horse battery staple"} {
+		if filter.Contains(sha1.Sum([]byte(password))) {
+			t.Errorf("Contains(%q) = true, want false", password)
+		}
+	}
+
+	if _, _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("LoadBreachedList() of a missing file succeeded")
+	}
+}
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	const n = 10000
+	filter := NewBloomFilter(n, breachedFalsePositiveRate)
+	for i := 0; i < n; i++ {
+		filter.Add(sha1.Sum([]byte(fmt.Sprintf("in-%d", i))))
+	}
+	for i := 0; i < n; i++ {
+		if !filter.Contains(sha1.Sum([]byte(fmt.Sprintf("in-%d", i)))) {
+			t.Fatalf("Contains(in-%d) = false, want true", i)
+		}
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if filter.Contains(sha1.Sum([]byte(fmt.Sprintf("out-%d", i)))) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / n; rate > 5*breachedFalsePositiveRate {
+		t.Errorf("false positive rate = %.4f, want about %.4f", rate, breachedFalsePositiveRate)
+	}
+}
@@ -0,0 +1,76 @@
+package passwords
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Verifier checks passwords against the hashes of one scheme.
+type Verifier interface {
+	// Recognizes reports whether the hash was produced by this scheme.
+	Recognizes(hash string) bool
+	Verify(hash, password string) (bool, error)
+}
+
+// Algorithm is a scheme new passwords can be hashed with.
+type Algorithm interface {
+	Verifier
+	Hash(password string) (string, error)
+	// Outdated reports whether the hash was produced with weaker parameters
+	// than the ones the algorithm is configured with.
+	Outdated(hash string) bool
+}
+
+// Hasher hashes new passwords with the configured algorithm and verifies
+// hashes of every supported scheme, including the legacy ones of imported
+// users. Hashes are stored in the PHC string format, or in the modular crypt
+// format for bcrypt and md5-crypt, so they name their scheme and parameters.
+type Hasher struct {
+	current   Algorithm
+	verifiers []Verifier
+}
+
+func NewHasher(cfg config.AppConfig) (*Hasher, error) {
+	argon2id := &Argon2id{
+		Memory:      uint32(cfg.Argon2Memory),
+		Time:        uint32(cfg.Argon2Time),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+	}
+	bcrypt := &Bcrypt{Cost: cfg.BcryptCost}
+
+	h := &Hasher{verifiers: []Verifier{argon2id, bcrypt, &MD5Crypt{}, &SaltedSHA1{}}}
+	switch cfg.PasswordHashAlgorithm {
+	case config.HashAlgorithmArgon2id:
+		h.current = argon2id
+	case config.HashAlgorithmBcrypt:
+		h.current = bcrypt
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+	return h, nil
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify checks a password against a stored hash. rehash is set when the
+// password matches but the hash should be replaced by a fresh one, because
+// it was produced by another algorithm or with weaker parameters.
+func (h *Hasher) Verify(hash, password string) (ok, rehash bool, err error) {
+	for _, verifier := range h.verifiers {
+		if !verifier.Recognizes(hash) {
+			continue
+		}
+		ok, err := verifier.Verify(hash, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, verifier != h.current || h.current.Outdated(hash), nil
+	}
+	return false, false, ErrUnknownHash
+}
@@ -0,0 +1,172 @@
+package passwords
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hash schemes of users imported from other platforms. They are only
+// verified; the first successful login replaces them with a current hash.
+const (
+	LegacyMD5Crypt   = "md5-crypt"
+	LegacySaltedSHA1 = "salted-sha1"
+	LegacyBcrypt     = "bcrypt"
+)
+
+const (
+	md5CryptPrefix   = "$1$"
+	saltedSHA1Prefix = "$salted-sha1$"
+	cryptAlphabet    = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// ImportHash turns a hash exported by another platform into the stored form,
+// which carries its scheme as a prefix. salt is only used by salted SHA-1,
+// whose exports keep it in a column of its own.
+func ImportHash(scheme, hash, salt string) (string, error) {
+	switch scheme {
+	case LegacyMD5Crypt:
+		if _, _, err := parseMD5Crypt(hash); err != nil {
+			return "", err
+		}
+		return hash, nil
+	case LegacySaltedSHA1:
+		digest, err := hex.DecodeString(hash)
+		if err != nil || len(digest) != sha1.Size {
+			return "", fmt.Errorf("salted SHA-1 hash must be %d hex digits", sha1.Size*2)
+		}
+		return saltedSHA1Prefix + base64.RawStdEncoding.EncodeToString([]byte(salt)) + "$" + hex.EncodeToString(digest), nil
+	case LegacyBcrypt:
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil || !(&Bcrypt{}).Recognizes(hash) {
+			return "", fmt.Errorf("malformed bcrypt hash")
+		}
+		return hash, nil
+	default:
+		return "", fmt.Errorf("unknown hash scheme %q", scheme)
+	}
+}
+
+// MD5Crypt verifies md5-crypt hashes like $1$<salt>$<hash>, as produced by
+// crypt(3) on Linux and FreeBSD.
+type MD5Crypt struct{}
+
+func (m *MD5Crypt) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, md5CryptPrefix)
+}
+
+func (m *MD5Crypt) Verify(hash, password string) (bool, error) {
+	salt, _, err := parseMD5Crypt(hash)
+	if err != nil {
+		return false, err
+	}
+	computed := md5Crypt([]byte(password), []byte(salt))
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(hash)) == 1, nil
+}
+
+func parseMD5Crypt(hash string) (salt, checksum string, err error) {
+	rest, ok := strings.CutPrefix(hash, md5CryptPrefix)
+	if !ok {
+		return "", "", fmt.Errorf("malformed md5-crypt hash")
+	}
+	salt, checksum, ok = strings.Cut(rest, "$")
+	if !ok || len(salt) > 8 || len(checksum) != 22 {
+		return "", "", fmt.Errorf("malformed md5-crypt hash")
+	}
+	return salt, checksum, nil
+}
+
+// md5Crypt is Poul-Henning Kamp's algorithm, which stirs the password and
+// the salt through a thousand rounds of MD5.
+func md5Crypt(password, salt []byte) string {
+	alternate := md5.New()
+	alternate.Write(password)
+	alternate.Write(salt)
+	alternate.Write(password)
+	alternateSum := alternate.Sum(nil)
+
+	digest := md5.New()
+	digest.Write(password)
+	digest.Write([]byte(md5CryptPrefix))
+	digest.Write(salt)
+	for n := len(password); n > 0; n -= md5.Size {
+		digest.Write(alternateSum[:min(n, md5.Size)])
+	}
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			digest.Write([]byte{0})
+		} else {
+			digest.Write(password[:1])
+		}
+	}
+	sum := digest.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		round := md5.New()
+		if i&1 != 0 {
+			round.Write(password)
+		} else {
+			round.Write(sum)
+		}
+		if i%3 != 0 {
+			round.Write(salt)
+		}
+		if i%7 != 0 {
+			round.Write(password)
+		}
+		if i&1 != 0 {
+			round.Write(sum)
+		} else {
+			round.Write(password)
+		}
+		sum = round.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString(md5CryptPrefix)
+	out.Write(salt)
+	out.WriteByte('$')
+	for _, group := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		encodeCrypt(&out, uint(sum[group[0]])<<16|uint(sum[group[1]])<<8|uint(sum[group[2]]), 4)
+	}
+	encodeCrypt(&out, uint(sum[11]), 2)
+	return out.String()
+}
+
+func encodeCrypt(out *strings.Builder, value uint, n int) {
+	for ; n > 0; n-- {
+		out.WriteByte(cryptAlphabet[value&0x3f])
+		value >>= 6
+	}
+}
+
+// SaltedSHA1 verifies hashes of the form $salted-sha1$<salt>$<digest>, where
+// the digest is the hex SHA-1 of the salt followed by the password and the
+// salt is base64 encoded.
+type SaltedSHA1 struct{}
+
+func (s *SaltedSHA1) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, saltedSHA1Prefix)
+}
+
+func (s *SaltedSHA1) Verify(hash, password string) (bool, error) {
+	encodedSalt, encodedDigest, ok := strings.Cut(strings.TrimPrefix(hash, saltedSHA1Prefix), "$")
+	if !ok {
+		return false, fmt.Errorf("malformed salted SHA-1 hash")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return false, fmt.Errorf("malformed salted SHA-1 salt: %w", err)
+	}
+	digest, err := hex.DecodeString(encodedDigest)
+	if err != nil || len(digest) != sha1.Size {
+		return false, fmt.Errorf("malformed salted SHA-1 digest")
+	}
+	computed := sha1.Sum(append(salt, password...))
+	return subtle.ConstantTimeCompare(computed[:], digest) == 1, nil
+}
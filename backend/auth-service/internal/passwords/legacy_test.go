@@ -0,0 +1,150 @@
+package passwords
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestMD5CryptVerify(t *testing.T) {
+	// Vectors produced by openssl passwd -1.
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "openssl saltsalt", hash: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", password: "password", want: true},
+		{name: "openssl mixed case salt", hash: "$1$3azHgidD$SrJPt7B.9rekpmwJwtON31", password: "password", want: true},
+		{name: "short salt and empty password", hash: "$1$ab$rn6aQS/o7141mj179E/zA.", password: "", want: true},
+		{name: "password longer than a digest", hash: "$1$12345678$yLppq.aqtfjKiej5RWDLq/", password: "a much longer password than sixteen bytes", want: true},
+		{name: "wrong password", hash: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", password: "Password"},
+		{name: "tampered checksum", hash: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK.", password: "password"},
+		{name: "salt too long", hash: "$1$saltsalts$qjXMvbEw8oaL.CzflDtaK/", password: "password", wantErr: true},
+		{name: "short checksum", hash: "$1$saltsalt$qjXMvbEw8oaL", password: "password", wantErr: true},
+		{name: "no checksum", hash: "$1$saltsalt", password: "password", wantErr: true},
+	}
+	m := &MD5Crypt{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !m.Recognizes(tt.hash) {
+				t.Fatalf("Recognizes(%q) = false", tt.hash)
+			}
+			got, err := m.Verify(tt.hash, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaltedSHA1Verify(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("NaCl"))
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+		wantErr  bool
+	}{
+		// SHA-1("NaCl" + "password")
+		{name: "salted", hash: "$salted-sha1$" + salt + "$e329d4054aff39056c09041993fe859a861d272f", password: "password", want: true},
+		// SHA-1("password")
+		{name: "empty salt", hash: "$salted-sha1$$5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", password: "password", want: true},
+		{name: "wrong password", hash: "$salted-sha1$" + salt + "$e329d4054aff39056c09041993fe859a861d272f", password: "passwort"},
+		{name: "salt not applied", hash: "$salted-sha1$" + salt + "$5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", password: "password"},
+		{name: "no digest", hash: "$salted-sha1$" + salt, password: "password", wantErr: true},
+		{name: "invalid salt", hash: "$salted-sha1$!!$e329d4054aff39056c09041993fe859a861d272f", password: "password", wantErr: true},
+		{name: "short digest", hash: "$salted-sha1$" + salt + "$e329d4054aff", password: "password", wantErr: true},
+	}
+	s := &SaltedSHA1{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Verify(tt.hash, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBcryptVerify(t *testing.T) {
+	// The "U*U" vector of the OpenBSD bcrypt test suite.
+	const hash = "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "2a", hash: hash, password: "U*U", want: true},
+		{name: "2b", hash: "$2b$" + strings.TrimPrefix(hash, "$2a$"), password: "U*U", want: true},
+		{name: "2y", hash: "$2y$" + strings.TrimPrefix(hash, "$2a$"), password: "U*U", want: true},
+		{name: "wrong password", hash: hash, password: "U*V"},
+		{name: "truncated", hash: hash[:40], password: "U*U", wantErr: true},
+	}
+	b := &Bcrypt{Cost: 10}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !b.Recognizes(tt.hash) {
+				t.Fatalf("Recognizes(%q) = false", tt.hash)
+			}
+			got, err := b.Verify(tt.hash, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !b.Outdated(hash) {
+		t.Errorf("Outdated() of a cost 5 hash = false with cost 10")
+	}
+}
+
+func TestImportHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		hash    string
+		salt    string
+		want    string
+		wantErr bool
+	}{
+		{name: "md5-crypt", scheme: LegacyMD5Crypt, hash: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", want: "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+		{name: "malformed md5-crypt", scheme: LegacyMD5Crypt, hash: "$1$saltsalt", wantErr: true},
+		{name: "salted SHA-1", scheme: LegacySaltedSHA1, hash: "E329D4054AFF39056C09041993FE859A861D272F", salt: "NaCl", want: "$salted-sha1$TmFDbA$e329d4054aff39056c09041993fe859a861d272f"},
+		{name: "malformed salted SHA-1", scheme: LegacySaltedSHA1, hash: "e329d4054aff", salt: "NaCl", wantErr: true},
+		{name: "bcrypt", scheme: LegacyBcrypt, hash: "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", want: "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"},
+		{name: "malformed bcrypt", scheme: LegacyBcrypt, hash: "$2a$05$short", wantErr: true},
+		{name: "unknown scheme", scheme: "plain", hash: "password", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ImportHash(tt.scheme, tt.hash, tt.salt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportHash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ImportHash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	imported, err := ImportHash(LegacySaltedSHA1, "e329d4054aff39056c09041993fe859a861d272f", "NaCl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := (&SaltedSHA1{}).Verify(imported, "password"); err != nil || !ok {
+		t.Errorf("Verify() of an imported salted SHA-1 hash = %v, %v, want true", ok, err)
+	}
+}
@@ -0,0 +1,110 @@
+package passwords
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"go.uber.org/zap"
+)
+
+// Violation codes. The frontend maps them to localized messages.
+const (
+	ViolationTooShort = "PASSWORD_TOO_SHORT"
+	ViolationTooLong  = "PASSWORD_TOO_LONG"
+	ViolationTooWeak  = "PASSWORD_TOO_WEAK"
+	ViolationBreached = "PASSWORD_BREACHED"
+)
+
+// Violation is one rule a password breaks.
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// Policy decides whether a password is acceptable: long enough, short
+// enough for the hash algorithm, hard enough to guess and not part of a known
+// breach.
+type Policy struct {
+	minLength   int
+	maxLength   int
+	maxBytes    int // 0 when the hash algorithm takes any length
+	minStrength int
+	estimator   *Estimator
+	breached    *BloomFilter
+}
+
+func NewPolicy(cfg config.AppConfig, logger *zap.Logger) (*Policy, error) {
+	estimator, err := NewEstimator(cfg.PasswordDictionaryDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load password dictionaries: %w", err)
+	}
+	if cfg.PasswordDictionaryDir != "" {
+		logger.Info("Password dictionaries loaded",
+			zap.String("dir", cfg.PasswordDictionaryDir),
+			zap.Int("words", len(estimator.ranks)))
+	}
+	policy := &Policy{
+		minLength:   cfg.PasswordMinLength,
+		maxLength:   cfg.PasswordMaxLength,
+		minStrength: cfg.PasswordMinStrength,
+		estimator:   estimator,
+	}
+	if cfg.PasswordHashAlgorithm == config.HashAlgorithmBcrypt {
+		policy.maxBytes = bcryptMaxBytes
+	}
+
+	if cfg.PasswordBreachedListFile != "" {
+		started := time.Now()
+		breached, entries, err := LoadBreachedList(cfg.PasswordBreachedListFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+		logger.Info("Breached password list loaded",
+			zap.String("file", cfg.PasswordBreachedListFile),
+			zap.Int("entries", entries),
+			zap.Duration("took", time.Since(started)))
+	}
+	return policy, nil
+}
+
+// Check returns every rule the password breaks, or nil if it is acceptable.
+// userInputs are the username, email address and similar words that make a
+// password easy to guess for this user.
+func (p *Policy) Check(password string, userInputs ...string) []Violation {
+	var violations []Violation
+	length := len([]rune(password))
+	tooLong := length > p.maxLength || (p.maxBytes > 0 && len(password) > p.maxBytes)
+	if length < p.minLength {
+		violations = append(violations, Violation{
+			Code:    ViolationTooShort,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.minLength),
+		})
+	}
+	if tooLong {
+		message := fmt.Sprintf("password must be at most %d characters long", p.maxLength)
+		if p.maxBytes > 0 {
+			message = fmt.Sprintf("password must be at most %d characters and %d bytes long", p.maxLength, p.maxBytes)
+		}
+		violations = append(violations, Violation{Code: ViolationTooLong, Message: message})
+	}
+	if p.breached != nil && p.breached.Contains(sha1.Sum([]byte(password))) {
+		violations = append(violations, Violation{
+			Code:    ViolationBreached,
+			Message: "password has appeared in a data breach",
+		})
+	}
+	if tooLong {
+		// The estimate takes quadratic time in the length of the password.
+		return violations
+	}
+	if estimate := p.estimator.Estimate(password, userInputs...); estimate.Score < p.minStrength {
+		violations = append(violations, Violation{
+			Code:    ViolationTooWeak,
+			Message: "password is too easy to guess: " + estimate.Hint,
+		})
+	}
+	return violations
+}
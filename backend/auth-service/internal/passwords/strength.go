@@ -0,0 +1,416 @@
+package passwords
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Strength scores, from trivially guessable to very hard to guess. They
+// follow the thresholds of zxcvbn, on the base 10 logarithm of the estimated
+// number of guesses.
+const (
+	StrengthVeryWeak = iota
+	StrengthWeak
+	StrengthFair
+	StrengthStrong
+	StrengthVeryStrong
+)
+
+var strengthThresholds = []float64{3, 6, 8, 10}
+
+// Hints describing the pattern that made a password weak.
+const (
+	hintCommonWord = "avoid common words, names and passwords"
+	hintUserInput  = "avoid your username and email address"
+	hintSequence   = "avoid sequences like abc or 123"
+	hintRepeat     = "avoid repeated characters"
+	hintKeyboard   = "avoid keyboard patterns like qwerty"
+	hintDate       = "avoid dates and years"
+	hintLength     = "add more characters"
+)
+
+// The dictionaries hold words ordered from most to least common: common
+// passwords and the five languages of the app. The embedded lists only cover
+// the most common words; full frequency lists are loaded from
+// PASSWORD_DICTIONARY_DIR.
+//
+//go:embed dictionaries/*.txt
+var dictionaryFiles embed.FS
+
+var dictionaryNames = []string{"passwords", "en", "ru", "it", "es", "mn"}
+
+// keyboardRows are the rows of the QWERTY and ЙЦУКЕН layouts. Substrings of
+// them, forwards or backwards, are keyboard patterns.
+var keyboardRows = []string{
+	"1234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+	"йцукенгшщзхъ",
+	"фывапролджэ",
+	"ячсмитьбю",
+}
+
+// qwertyToCyrillic maps the keys of the QWERTY layout to the letters of the
+// ЙЦУКЕН layout, which turns "gfhjkm" back into "пароль".
+var qwertyToCyrillic = buildLayoutMap(
+	"qwertyuiop[]asdfghjkl;'zxcvbnm,.`",
+	"йцукенгшщзхъфывапролджэячсмитьбюё",
+)
+
+var leetSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '3': 'e', '6': 'g', '1': 'i', '!': 'i',
+	'0': 'o', '5': 's', '$': 's', '7': 't', '2': 'z',
+}
+
+// Estimator estimates how many guesses an attacker who tries common words,
+// names and patterns first needs to find a password. It is a simplified
+// zxcvbn: the password is split into the cheapest sequence of dictionary
+// words, sequences, repeats, keyboard patterns, years and brute-forced
+// characters, and the guesses of the parts are multiplied.
+type Estimator struct {
+	ranks map[string]int
+}
+
+// NewEstimator loads the dictionaries. A file named like a dictionary, such as
+// en.txt, in dir replaces the embedded list; an empty dir uses only the
+// embedded lists.
+func NewEstimator(dir string) (*Estimator, error) {
+	e := &Estimator{ranks: make(map[string]int)}
+	for _, name := range dictionaryNames {
+		file, err := openDictionary(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		err = e.addDictionary(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dictionary %s: %w", name, err)
+		}
+	}
+	return e, nil
+}
+
+func openDictionary(dir, name string) (io.ReadCloser, error) {
+	if dir != "" {
+		file, err := os.Open(filepath.Join(dir, name+".txt"))
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to open dictionary %s: %w", name, err)
+		}
+	}
+	return dictionaryFiles.Open("dictionaries/" + name + ".txt")
+}
+
+// addDictionary ranks the words of a list by their line. A word in several
+// lists keeps its best rank.
+func (e *Estimator) addDictionary(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	rank := 0
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" {
+			continue
+		}
+		rank++
+		if current, ok := e.ranks[word]; !ok || rank < current {
+			e.ranks[word] = rank
+		}
+	}
+	return scanner.Err()
+}
+
+// Estimate is the outcome of Estimator.Estimate.
+type Estimate struct {
+	// Guesses is the base 10 logarithm of the estimated number of guesses.
+	Guesses float64
+	Score   int
+	// Hint names the pattern that contributed most to a weak score.
+	Hint string
+}
+
+type match struct {
+	start, end int // runes [start, end)
+	guesses    float64
+	hint       string
+}
+
+// Estimate rates a password. userInputs are words an attacker targeting the
+// user would try first, such as the username and the email address.
+func (e *Estimator) Estimate(password string, userInputs ...string) Estimate {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Estimate{Score: StrengthVeryWeak, Hint: hintLength}
+	}
+
+	var matches []match
+	matches = append(matches, e.dictionaryMatches(runes, userRanks(userInputs))...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, repeatMatches(runes)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	// best[i] is the cheapest way, in log10 guesses, to cover runes[:i].
+	n := len(runes)
+	best := make([]float64, n+1)
+	via := make([]*match, n+1)
+	for i := 1; i <= n; i++ {
+		// Brute force is extended character by character; patterns replace a
+		// whole span and cost a little for being one of several.
+		best[i] = best[i-1] + math.Log10(bruteforceCardinality(runes[i-1]))
+		for k := range matches {
+			m := &matches[k]
+			if m.end != i {
+				continue
+			}
+			cost := best[m.start] + math.Log10(m.guesses)
+			if m.start > 0 {
+				cost += math.Log10(2)
+			}
+			if cost < best[i] {
+				best[i], via[i] = cost, m
+			}
+		}
+	}
+
+	estimate := Estimate{Guesses: best[n]}
+	for _, threshold := range strengthThresholds {
+		if estimate.Guesses >= threshold {
+			estimate.Score++
+		}
+	}
+	if estimate.Score < StrengthVeryStrong {
+		estimate.Hint = weakestHint(via)
+	}
+	return estimate
+}
+
+// weakestHint walks the chosen decomposition back and returns the hint of
+// the pattern covering most of the password.
+func weakestHint(via []*match) string {
+	hint, covered := hintLength, 0
+	for i := len(via) - 1; i > 0; {
+		m := via[i]
+		if m == nil {
+			i--
+			continue
+		}
+		if length := m.end - m.start; length > covered {
+			hint, covered = m.hint, length
+		}
+		i = m.start
+	}
+	return hint
+}
+
+func (e *Estimator) dictionaryMatches(runes []rune, userRanks map[string]int) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	unleeted := make([]rune, len(lower))
+	for i, r := range lower {
+		if sub, ok := leetSubstitutions[r]; ok {
+			unleeted[i] = sub
+		} else {
+			unleeted[i] = r
+		}
+	}
+	layout := make([]rune, len(lower))
+	for i, r := range lower {
+		if sub, ok := qwertyToCyrillic[r]; ok {
+			layout[i] = sub
+		} else {
+			layout[i] = r
+		}
+	}
+
+	var matches []match
+	for i := 0; i < len(runes); i++ {
+		for j := i + 3; j <= len(runes); j++ {
+			variants := []struct {
+				word   string
+				factor float64
+			}{
+				{string(lower[i:j]), 1},
+				{string(unleeted[i:j]), 2},
+				{reverse(lower[i:j]), 2},
+				{string(layout[i:j]), 2},
+			}
+			factor := caseFactor(runes[i:j])
+			for _, variant := range variants {
+				if rank, ok := userRanks[variant.word]; ok {
+					matches = append(matches, match{i, j, float64(rank) * variant.factor * factor, hintUserInput})
+					break
+				}
+				if rank, ok := e.ranks[variant.word]; ok {
+					matches = append(matches, match{i, j, float64(rank) * variant.factor * factor, hintCommonWord})
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// caseFactor accounts for the capitalisations an attacker tries: none, all,
+// or the first letter are cheap, anything else costs more.
+func caseFactor(word []rune) float64 {
+	upper := 0
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case upper == len(word) || (upper == 1 && unicode.IsUpper(word[0])):
+		return 2
+	default:
+		return math.Pow(2, float64(min(upper, len(word)-upper)+1))
+	}
+}
+
+// userRanks splits the user inputs into words, each of which is as cheap to
+// guess as the most common password.
+func userRanks(inputs []string) map[string]int {
+	ranks := make(map[string]int)
+	for _, input := range inputs {
+		input = strings.ToLower(input)
+		words := strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range append(words, input) {
+			if len([]rune(word)) >= 3 {
+				if _, ok := ranks[word]; !ok {
+					ranks[word] = len(ranks) + 1
+				}
+			}
+		}
+	}
+	return ranks
+}
+
+// sequenceMatches finds runs like "abcd", "4321" or "гдеё" whose code points
+// step by one.
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes)-2; {
+		delta := runes[i+1] - runes[i]
+		j := i + 1
+		if delta == 1 || delta == -1 {
+			for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+				j++
+			}
+		}
+		if length := j - i + 1; length >= 3 {
+			base := 26.0
+			switch first := unicode.ToLower(runes[i]); {
+			case first == 'a' || first == 'z' || first == '0' || first == '1' || first == '9':
+				base = 4
+			case unicode.IsDigit(first):
+				base = 10
+			}
+			matches = append(matches, match{i, j + 1, base * float64(length), hintSequence})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// repeatMatches finds runs of one character, like "aaaa".
+func repeatMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[i] {
+			j++
+		}
+		if length := j - i + 1; length >= 3 {
+			matches = append(matches, match{i, j + 1, bruteforceCardinality(runes[i]) * float64(length), hintRepeat})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+// keyboardMatches finds runs of four or more neighbouring keys of one row.
+func keyboardMatches(runes []rune) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+	var matches []match
+	for i := 0; i < len(lower); i++ {
+		for j := i + 4; j <= len(lower); j++ {
+			part := string(lower[i:j])
+			reversed := reverse(lower[i:j])
+			for _, row := range keyboardRows {
+				if strings.Contains(row, part) || strings.Contains(row, reversed) {
+					matches = append(matches, match{i, j, 40 * float64(j-i), hintKeyboard})
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// yearMatches finds years between 1900 and 2099, which are cheap to guess.
+func yearMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i+4 <= len(runes); i++ {
+		year := string(runes[i : i+4])
+		if (strings.HasPrefix(year, "19") || strings.HasPrefix(year, "20")) && isDigits(year) {
+			matches = append(matches, match{i, i + 4, 200, hintDate})
+		}
+	}
+	return matches
+}
+
+// bruteforceCardinality is the size of the character class a brute force
+// attacker has to try for r.
+func bruteforceCardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case r < unicode.MaxASCII && unicode.IsLetter(r):
+		return 26
+	default:
+		return 33
+	}
+}
+
+func buildLayoutMap(from, to string) map[rune]rune {
+	fromRunes, toRunes := []rune(from), []rune(to)
+	layout := make(map[rune]rune, len(fromRunes))
+	for i, r := range fromRunes {
+		layout[r] = toRunes[i]
+	}
+	return layout
+}
+
+func reverse(runes []rune) string {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return string(reversed)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
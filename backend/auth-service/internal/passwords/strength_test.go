@@ -0,0 +1,42 @@
+package passwords
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewEstimatorDictionaryDir(t *testing.T) {
+	embedded, err := NewEstimator("")
+	if err != nil {
+		t.Fatalf("NewEstimator() error = %v", err)
+	}
+	if _, ok := embedded.ranks["lantern"]; ok {
+		t.Fatalf("embedded dictionaries already contain %q", "lantern")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.txt"), []byte("the\n\nLantern\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEstimator(dir)
+	if err != nil {
+		t.Fatalf("NewEstimator() error = %v", err)
+	}
+	if got := e.ranks["lantern"]; got != 2 {
+		t.Errorf("rank of %q = %d, want 2", "lantern", got)
+	}
+	if _, ok := e.ranks["пароль"]; !ok {
+		t.Errorf("a dictionary missing from the directory did not fall back to the embedded list")
+	}
+	if got, want := e.Estimate("lantern").Score, StrengthVeryWeak; got != want {
+		t.Errorf("Estimate(%q).Score = %d, want %d", "lantern", got, want)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "ru.txt"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEstimator(dir); err == nil {
+		t.Errorf("NewEstimator() with an unreadable dictionary succeeded")
+	}
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	aead, err := NewAEAD(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	if err != nil {
+		t.Fatalf("NewAEAD() error = %v", err)
+	}
+	sealed, err := Seal(aead, []byte("private key"), "kid-1")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if got, err := Open(aead, sealed, "kid-1"); err != nil || string(got) != "private key" {
+		t.Errorf("Open() = %q, %v, want %q", got, err, "private key")
+	}
+	if _, err := Open(aead, sealed, "kid-2"); err == nil {
+		t.Errorf("Open() with other associated data succeeded")
+	}
+	if _, err := Open(aead, "AAAA", "kid-1"); err == nil {
+		t.Errorf("Open() of a short ciphertext succeeded")
+	}
+	if other, _ := Seal(aead, []byte("private key"), "kid-1"); other == sealed {
+		t.Errorf("Seal() returned the same value twice, want a fresh nonce")
+	}
+
+	for _, key := range []string{"", "!!!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := NewAEAD(key); err == nil {
+			t.Errorf("NewAEAD(%q) succeeded", key)
+		}
+	}
+}
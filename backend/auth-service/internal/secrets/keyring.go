@@ -0,0 +1,257 @@
+// Package secrets encrypts the per-user token and TOTP secrets at rest. Every
+// value is sealed with its own data key, which is in turn sealed with a master
+// key; the stored value names the master key, so master keys can be rotated
+// by rewrapping the data keys without touching the data itself. The AES-GCM
+// helpers underneath also encrypt the signing keys.
+package secrets
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+)
+
+// envelopePrefix starts every encrypted value. The data of such values is
+// bound to both the column and the user they belong to.
+const envelopePrefix = "enc2:"
+
+// legacyEnvelopePrefix starts values whose data is bound to the column only.
+// Like values without any prefix, which were stored before encryption was
+// introduced and are plain text, they are rewrapped into the current format.
+const legacyEnvelopePrefix = "enc1:"
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Keyring holds the master keys. New values are sealed with the current key;
+// the others are kept to open values sealed before a rotation.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	current string
+	// strict refuses plain text and legacy values, which are not bound to a
+	// user and could be copied between users by someone with write access to
+	// the database.
+	strict bool
+}
+
+// NewKeyring loads the master keys from TOKEN_SECRET_KEYS or from the file
+// named by TOKEN_SECRET_KEYS_FILE. Keys are written as "id:base64 key", comma
+// separated in the variable and one per line in the file, where lines
+// starting with "#" are comments. TOKEN_SECRET_KEY_ID picks the current key
+// and may be left out when there is only one. TOKEN_SECRET_REQUIRE_ENCRYPTION
+// should be turned on once a re-encryption pass has rewrapped every value.
+func NewKeyring(cfg config.AppConfig) (*Keyring, error) {
+	entries := strings.Split(cfg.TokenSecretKeys, ",")
+	if cfg.TokenSecretKeysFile != "" {
+		var err error
+		entries, err = readKeysFile(cfg.TokenSecretKeysFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	k := &Keyring{
+		keys:   make(map[string]cipher.AEAD),
+		strict: cfg.TokenSecretRequireEncryption,
+	}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encodedKey, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("token secret key %q is not written as id:key", truncate(entry))
+		}
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("token secret key ID %q may only contain letters, digits, dots, underscores and hyphens", id)
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("token secret key %q is listed twice", id)
+		}
+		aead, err := NewAEAD(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("token secret key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+		if len(k.keys) == 1 {
+			k.current = id
+		}
+	}
+
+	switch {
+	case len(k.keys) == 0:
+		return nil, fmt.Errorf("no token secret keys configured")
+	case cfg.TokenSecretKeyID != "":
+		if _, ok := k.keys[cfg.TokenSecretKeyID]; !ok {
+			return nil, fmt.Errorf("TOKEN_SECRET_KEY_ID %q is not among the token secret keys", cfg.TokenSecretKeyID)
+		}
+		k.current = cfg.TokenSecretKeyID
+	case len(k.keys) > 1:
+		return nil, fmt.Errorf("TOKEN_SECRET_KEY_ID must be set when there are several token secret keys")
+	}
+	return k, nil
+}
+
+func readKeysFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token secret keys file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token secret keys file: %w", err)
+	}
+	return entries, nil
+}
+
+// truncate keeps key material out of error messages.
+func truncate(entry string) string {
+	if len(entry) > 8 {
+		return entry[:8] + "..."
+	}
+	return entry
+}
+
+// CurrentKeyID returns the ID of the key new values are sealed with.
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// CurrentPrefix returns the prefix of values sealed with the current key in
+// the current format. Stored values without it still need to be rewrapped.
+func (k *Keyring) CurrentPrefix() string {
+	return envelopePrefix + k.current + ":"
+}
+
+// Encrypt seals plaintext under a fresh data key. The data is bound to
+// column and userID, so a value cannot be moved to another column or user
+// unnoticed.
+func (k *Keyring) Encrypt(plaintext, column string, userID int64) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := aeadFromKey(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealedData, err := Seal(dataAEAD, []byte(plaintext), dataAD(column, userID))
+	if err != nil {
+		return "", err
+	}
+	sealedKey, err := Seal(k.keys[k.current], dataKey, k.current)
+	if err != nil {
+		return "", err
+	}
+	return k.CurrentPrefix() + sealedKey + ":" + sealedData, nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Unless encryption is required,
+// legacy values are opened too and values stored before encryption was
+// introduced are returned as they are.
+func (k *Keyring) Decrypt(value, column string, userID int64) (string, error) {
+	plaintext, err := k.decrypt(value, column, userID)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap returns the value sealed with the current key in the current format.
+// For current values only the data key is sealed again; legacy and plain text
+// values are encrypted anew.
+func (k *Keyring) Rewrap(value, column string, userID int64) (string, error) {
+	if strings.HasPrefix(value, k.CurrentPrefix()) {
+		return value, nil
+	}
+	if !strings.HasPrefix(value, envelopePrefix) {
+		plaintext, err := k.decrypt(value, column, userID)
+		if err != nil {
+			return "", err
+		}
+		return k.Encrypt(string(plaintext), column, userID)
+	}
+	dataKey, sealedData, err := k.openKey(value, envelopePrefix)
+	if err != nil {
+		return "", err
+	}
+	// Make sure the data opens before the old key can no longer open it.
+	dataAEAD, err := aeadFromKey(dataKey)
+	if err != nil {
+		return "", err
+	}
+	if _, err := Open(dataAEAD, sealedData, dataAD(column, userID)); err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	sealedKey, err := Seal(k.keys[k.current], dataKey, k.current)
+	if err != nil {
+		return "", err
+	}
+	return k.CurrentPrefix() + sealedKey + ":" + sealedData, nil
+}
+
+func (k *Keyring) decrypt(value, column string, userID int64) ([]byte, error) {
+	prefix, ad := envelopePrefix, dataAD(column, userID)
+	switch {
+	case strings.HasPrefix(value, envelopePrefix):
+	case k.strict:
+		return nil, fmt.Errorf("value is not encrypted in the current format")
+	case strings.HasPrefix(value, legacyEnvelopePrefix):
+		prefix, ad = legacyEnvelopePrefix, column
+	default:
+		return []byte(value), nil
+	}
+	dataKey, sealedData, err := k.openKey(value, prefix)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := aeadFromKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := Open(dataAEAD, sealedData, ad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// dataAD binds the data of a value to its column and user.
+func dataAD(column string, userID int64) string {
+	return column + ":" + strconv.FormatInt(userID, 10)
+}
+
+// openKey splits an encrypted value and opens its data key.
+func (k *Keyring) openKey(value, prefix string) ([]byte, string, error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return nil, "", fmt.Errorf("malformed encrypted value")
+	}
+	id, sealedKey, sealedData := parts[0], parts[1], parts[2]
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, "", fmt.Errorf("value is encrypted with unknown key %q", id)
+	}
+	dataKey, err := Open(aead, sealedKey, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	return dataKey, sealedData, nil
+}
@@ -0,0 +1,199 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+)
+
+const column = "users_access_token_secret"
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), 32)))
+}
+
+func newTestKeyring(t *testing.T, cfg config.AppConfig) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return k
+}
+
+// legacyEncrypt seals a value the way values were sealed before they were
+// bound to their user.
+func legacyEncrypt(t *testing.T, k *Keyring, plaintext, column string) string {
+	t.Helper()
+	dataKey := []byte(strings.Repeat("d", 32))
+	dataAEAD, err := aeadFromKey(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedData, err := Seal(dataAEAD, []byte(plaintext), column)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedKey, err := Seal(k.keys[k.current], dataKey, k.current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return legacyEnvelopePrefix + k.current + ":" + sealedKey + ":" + sealedData
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.AppConfig
+		current string
+		wantErr bool
+	}{
+		{name: "single key", cfg: config.AppConfig{TokenSecretKeys: "k1:" + testKey('a')}, current: "k1"},
+		{name: "current key picked", cfg: config.AppConfig{TokenSecretKeys: "k1:" + testKey('a') + ", k2:" + testKey('b'), TokenSecretKeyID: "k2"}, current: "k2"},
+		{name: "no keys", cfg: config.AppConfig{TokenSecretKeys: " , "}, wantErr: true},
+		{name: "missing separator", cfg: config.AppConfig{TokenSecretKeys: testKey('a')}, wantErr: true},
+		{name: "invalid key ID", cfg: config.AppConfig{TokenSecretKeys: "k 1:" + testKey('a')}, wantErr: true},
+		{name: "duplicate key ID", cfg: config.AppConfig{TokenSecretKeys: "k1:" + testKey('a') + ",k1:" + testKey('b')}, wantErr: true},
+		{name: "short key", cfg: config.AppConfig{TokenSecretKeys: "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))}, wantErr: true},
+		{name: "invalid base64", cfg: config.AppConfig{TokenSecretKeys: "k1:!!!"}, wantErr: true},
+		{name: "several keys without current", cfg: config.AppConfig{TokenSecretKeys: "k1:" + testKey('a') + ",k2:" + testKey('b')}, wantErr: true},
+		{name: "unknown current key", cfg: config.AppConfig{TokenSecretKeys: "k1:" + testKey('a'), TokenSecretKeyID: "k2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := NewKeyring(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewKeyring() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewKeyring() error = %v", err)
+			}
+			if got := k.CurrentKeyID(); got != tt.current {
+				t.Errorf("CurrentKeyID() = %q, want %q", got, tt.current)
+			}
+		})
+	}
+}
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	k := newTestKeyring(t, config.AppConfig{TokenSecretKeys: "k1:" + testKey('a')})
+	sealed, err := k.Encrypt("secret", column, 42)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(sealed, k.CurrentPrefix()) {
+		t.Fatalf("Encrypt() = %q, want prefix %q", sealed, k.CurrentPrefix())
+	}
+	if strings.Contains(sealed, "secret") {
+		t.Fatalf("Encrypt() = %q contains the plaintext", sealed)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		column  string
+		userID  int64
+		want    string
+		wantErr bool
+	}{
+		{name: "same column and user", value: sealed, column: column, userID: 42, want: "secret"},
+		{name: "other user", value: sealed, column: column, userID: 43, wantErr: true},
+		{name: "other column", value: sealed, column: "users_refresh_token_secret", userID: 42, wantErr: true},
+		{name: "tampered data", value: sealed[:len(sealed)-4] + "AAA=", column: column, userID: 42, wantErr: true},
+		{name: "malformed", value: envelopePrefix + "k1:abc", column: column, userID: 42, wantErr: true},
+		{name: "unknown key", value: strings.Replace(sealed, "k1", "k9", 1), column: column, userID: 42, wantErr: true},
+		{name: "plain text", value: "legacy", column: column, userID: 42, want: "legacy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := k.Decrypt(tt.value, tt.column, tt.userID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Decrypt() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Decrypt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyringLegacyValues(t *testing.T) {
+	cfg := config.AppConfig{TokenSecretKeys: "k1:" + testKey('a')}
+	k := newTestKeyring(t, cfg)
+	legacy := legacyEncrypt(t, k, "secret", column)
+
+	if got, err := k.Decrypt(legacy, column, 42); err != nil || got != "secret" {
+		t.Fatalf("Decrypt(legacy) = %q, %v, want %q", got, err, "secret")
+	}
+	for _, value := range []string{legacy, "secret"} {
+		rewrapped, err := k.Rewrap(value, column, 42)
+		if err != nil {
+			t.Fatalf("Rewrap(%q) error = %v", value, err)
+		}
+		if !strings.HasPrefix(rewrapped, k.CurrentPrefix()) {
+			t.Fatalf("Rewrap(%q) = %q, want prefix %q", value, rewrapped, k.CurrentPrefix())
+		}
+		if _, err := k.Decrypt(rewrapped, column, 43); err == nil {
+			t.Errorf("Rewrap(%q) result opens for another user", value)
+		}
+	}
+
+	cfg.TokenSecretRequireEncryption = true
+	strict := newTestKeyring(t, cfg)
+	for _, value := range []string{legacy, "secret"} {
+		if got, err := strict.Decrypt(value, column, 42); err == nil {
+			t.Errorf("strict Decrypt(%q) = %q, want an error", value, got)
+		}
+		if got, err := strict.Rewrap(value, column, 42); err == nil {
+			t.Errorf("strict Rewrap(%q) = %q, want an error", value, got)
+		}
+	}
+}
+
+func TestKeyringRewrap(t *testing.T) {
+	old := newTestKeyring(t, config.AppConfig{TokenSecretKeys: "k1:" + testKey('a')})
+	sealed, err := old.Encrypt("secret", column, 42)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	rotated := newTestKeyring(t, config.AppConfig{
+		TokenSecretKeys:  "k1:" + testKey('a') + ",k2:" + testKey('b'),
+		TokenSecretKeyID: "k2",
+	})
+	if same, err := old.Rewrap(sealed, column, 42); err != nil || same != sealed {
+		t.Fatalf("Rewrap() with the current key = %q, %v, want the value unchanged", same, err)
+	}
+	if _, err := rotated.Rewrap(sealed, column, 43); err == nil {
+		t.Fatalf("Rewrap() for another user succeeded")
+	}
+	rewrapped, err := rotated.Rewrap(sealed, column, 42)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+	if !strings.HasPrefix(rewrapped, rotated.CurrentPrefix()) {
+		t.Fatalf("Rewrap() = %q, want prefix %q", rewrapped, rotated.CurrentPrefix())
+	}
+	if oldData, newData := sealed[strings.LastIndex(sealed, ":"):], rewrapped[strings.LastIndex(rewrapped, ":"):]; oldData != newData {
+		t.Errorf("Rewrap() sealed the data again, want only the data key rewrapped")
+	}
+
+	onlyNew := newTestKeyring(t, config.AppConfig{TokenSecretKeys: "k2:" + testKey('b')})
+	if got, err := onlyNew.Decrypt(rewrapped, column, 42); err != nil || got != "secret" {
+		t.Errorf("Decrypt() after removing the old key = %q, %v, want %q", got, err, "secret")
+	}
+	if _, err := onlyNew.Decrypt(sealed, column, 42); err == nil {
+		t.Errorf("Decrypt() of a value sealed with a removed key succeeded")
+	}
+}
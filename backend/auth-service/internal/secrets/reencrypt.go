@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+)
+
+// Reencryptor rewraps the token and TOTP secrets of every user that are not
+// encrypted with the current master key: once at start and then periodically.
+// After a rotation the previous key can be removed once a pass reports no
+// secrets left behind. Secrets stored before encryption was introduced, and secrets
+// not yet bound to their user, are encrypted anew by the same pass.
+type Reencryptor struct {
+	query  db.UserQuery
+	config config.AppConfig
+	logger *zap.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewReencryptor(query db.UserQuery, cfg config.AppConfig, logger *zap.Logger) *Reencryptor {
+	return &Reencryptor{
+		query:  query,
+		config: cfg,
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs a pass right away and then one every interval until Stop is
+// called.
+func (r *Reencryptor) Start() {
+	go func() {
+		defer close(r.done)
+		r.pass()
+		ticker := time.NewTicker(r.config.TOKEN_SECRET_REENCRYPT_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.pass()
+			}
+		}
+	}()
+}
+
+// Stop waits for the batch in flight.
+func (r *Reencryptor) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+// pass walks the users in batches. Users locked by a concurrent transaction
+// are skipped and picked up by the next pass.
+func (r *Reencryptor) pass() {
+	var afterID int64
+	rewrapped, failed := 0, 0
+	for {
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+
+		result, err := r.query.ReencryptSecrets(context.Background(), afterID, uint64(r.config.TokenSecretReencryptBatchSize))
+		if err != nil {
+			r.logger.Warn("Failed to re-encrypt token secrets", zap.Int64("after_id", afterID), zap.Error(err))
+			return
+		}
+		rewrapped += result.Rewrapped
+		failed += result.Failed
+		if result.Users < r.config.TokenSecretReencryptBatchSize {
+			break
+		}
+		afterID = result.LastID
+	}
+
+	if failed > 0 {
+		r.logger.Error("Some token secrets could not be re-encrypted",
+			zap.Int("rewrapped", rewrapped),
+			zap.Int("failed", failed))
+	} else if rewrapped > 0 {
+		r.logger.Info("Token secret re-encryption pass finished", zap.Int("rewrapped", rewrapped))
+	}
+}
@@ -25,7 +25,11 @@ func NewAuthServer(svc *service.AuthService, logger *zap.Logger, addr string) (*
 		return nil, err
 	}
 
-	grpcServer := grpc.NewServer()
+	validator, err := newRequestValidator()
+	if err != nil {
+		return nil, err
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(validationInterceptor(validator, logger)))
 	s := &AuthServer{
 		grpcServer: grpcServer,
 		errChan:    make(chan error, 1),
@@ -52,24 +56,168 @@ func (s *AuthServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Login
 
 func (s *AuthServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
 	s.logger.Debug("Validating token", zap.String("token_type", req.TokenType))
-	_, err := s.service.ValidateToken(ctx, req.Token, req.TokenType)
+	resp, err := s.service.ValidateToken(ctx, req)
 	if err != nil {
 		s.logger.Error("Token validation failed", zap.Error(err))
 		return nil, err
 	}
-	s.logger.Info("Token validated successfully")
-	return &pb.ValidateTokenResponse{}, nil
+	s.logger.Info("Token validated successfully", zap.Int64("user_id", resp.UserId))
+	return resp, nil
+}
+
+func (s *AuthServer) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	return s.service.Refresh(ctx, req)
 }
 
 func (s *AuthServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
-	s.logger.Debug("Logging out user", zap.Int64("user_id", req.UserId))
-	err := s.service.Logout(ctx, req.UserId)
+	s.logger.Debug("Logging out", zap.Int64("user_id", req.UserId), zap.String("scope", req.Scope.String()))
+	resp, err := s.service.Logout(ctx, req)
 	if err != nil {
 		s.logger.Error("Logout failed", zap.Error(err))
 		return nil, err
 	}
 	s.logger.Info("Logout successful", zap.Int64("user_id", req.UserId))
-	return &pb.LogoutResponse{}, nil
+	return resp, nil
+}
+
+func (s *AuthServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	return s.service.ListSessions(ctx, req)
+}
+
+func (s *AuthServer) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	return s.service.RevokeSession(ctx, req)
+}
+
+func (s *AuthServer) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	return s.service.RequestPasswordReset(ctx, req)
+}
+
+func (s *AuthServer) ConfirmPasswordReset(ctx context.Context, req *pb.ConfirmPasswordResetRequest) (*pb.ConfirmPasswordResetResponse, error) {
+	return s.service.ConfirmPasswordReset(ctx, req)
+}
+
+func (s *AuthServer) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	return s.service.VerifyEmail(ctx, req)
+}
+
+func (s *AuthServer) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	return s.service.ResendVerification(ctx, req)
+}
+
+func (s *AuthServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	return s.service.ChangePassword(ctx, req)
+}
+
+func (s *AuthServer) ChangeEmail(ctx context.Context, req *pb.ChangeEmailRequest) (*pb.ChangeEmailResponse, error) {
+	return s.service.ChangeEmail(ctx, req)
+}
+
+func (s *AuthServer) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	return s.service.EnrollTOTP(ctx, req)
+}
+
+func (s *AuthServer) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	return s.service.ConfirmTOTP(ctx, req)
+}
+
+func (s *AuthServer) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	return s.service.DisableTOTP(ctx, req)
+}
+
+func (s *AuthServer) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.VerifyMFAResponse, error) {
+	return s.service.VerifyMFA(ctx, req)
+}
+
+func (s *AuthServer) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	return s.service.UnlockAccount(ctx, req)
+}
+
+func (s *AuthServer) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	return s.service.GetJWKS(ctx, req)
+}
+
+func (s *AuthServer) RotateSigningKey(ctx context.Context, req *pb.RotateSigningKeyRequest) (*pb.RotateSigningKeyResponse, error) {
+	return s.service.RotateSigningKey(ctx, req)
+}
+
+func (s *AuthServer) CreatePermission(ctx context.Context, req *pb.CreatePermissionRequest) (*pb.CreatePermissionResponse, error) {
+	return s.service.CreatePermission(ctx, req)
+}
+
+func (s *AuthServer) DeletePermission(ctx context.Context, req *pb.DeletePermissionRequest) (*pb.DeletePermissionResponse, error) {
+	return s.service.DeletePermission(ctx, req)
+}
+
+func (s *AuthServer) ListPermissions(ctx context.Context, req *pb.ListPermissionsRequest) (*pb.ListPermissionsResponse, error) {
+	return s.service.ListPermissions(ctx, req)
+}
+
+func (s *AuthServer) GrantPermission(ctx context.Context, req *pb.GrantPermissionRequest) (*pb.GrantPermissionResponse, error) {
+	return s.service.GrantPermission(ctx, req)
+}
+
+func (s *AuthServer) RevokePermission(ctx context.Context, req *pb.RevokePermissionRequest) (*pb.RevokePermissionResponse, error) {
+	return s.service.RevokePermission(ctx, req)
+}
+
+func (s *AuthServer) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	return s.service.CheckPermission(ctx, req)
+}
+
+func (s *AuthServer) CreateRole(ctx context.Context, req *pb.CreateRoleRequest) (*pb.CreateRoleResponse, error) {
+	return s.service.CreateRole(ctx, req)
+}
+
+func (s *AuthServer) UpdateRole(ctx context.Context, req *pb.UpdateRoleRequest) (*pb.UpdateRoleResponse, error) {
+	return s.service.UpdateRole(ctx, req)
+}
+
+func (s *AuthServer) DeleteRole(ctx context.Context, req *pb.DeleteRoleRequest) (*pb.DeleteRoleResponse, error) {
+	return s.service.DeleteRole(ctx, req)
+}
+
+func (s *AuthServer) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	return s.service.ListRoles(ctx, req)
+}
+
+func (s *AuthServer) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.AssignRoleResponse, error) {
+	return s.service.AssignRole(ctx, req)
+}
+
+func (s *AuthServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	return s.service.ListUsers(ctx, req)
+}
+
+func (s *AuthServer) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	return s.service.SearchUsers(ctx, req)
+}
+
+func (s *AuthServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	return s.service.GetUser(ctx, req)
+}
+
+func (s *AuthServer) SuspendUser(ctx context.Context, req *pb.SuspendUserRequest) (*pb.SuspendUserResponse, error) {
+	return s.service.SuspendUser(ctx, req)
+}
+
+func (s *AuthServer) ReinstateUser(ctx context.Context, req *pb.ReinstateUserRequest) (*pb.ReinstateUserResponse, error) {
+	return s.service.ReinstateUser(ctx, req)
+}
+
+func (s *AuthServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	return s.service.DeleteUser(ctx, req)
+}
+
+func (s *AuthServer) QueryAuditEvents(ctx context.Context, req *pb.QueryAuditEventsRequest) (*pb.QueryAuditEventsResponse, error) {
+	return s.service.QueryAuditEvents(ctx, req)
+}
+
+func (s *AuthServer) VerifyAuditLog(ctx context.Context, req *pb.VerifyAuditLogRequest) (*pb.VerifyAuditLogResponse, error) {
+	return s.service.VerifyAuditLog(ctx, req)
+}
+
+func (s *AuthServer) ImportUsers(ctx context.Context, req *pb.ImportUsersRequest) (*pb.ImportUsersResponse, error) {
+	return s.service.ImportUsers(ctx, req)
 }
 
 func (s *AuthServer) ErrChan() chan error {
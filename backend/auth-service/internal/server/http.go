@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const JWKSPath = "/.well-known/jwks.json"
+
+// HTTPServer serves the endpoints that have to be plain HTTP, such as the
+// JWKS consumed by Envoy's jwt_authn filter.
+type HTTPServer struct {
+	httpServer *http.Server
+	errChan    chan error
+	logger     *zap.Logger
+	service    *service.AuthService
+}
+
+func NewHTTPServer(svc *service.AuthService, logger *zap.Logger, addr string) (*HTTPServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &HTTPServer{
+		errChan: make(chan error, 1),
+		logger:  logger,
+		service: svc,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+JWKSPath, s.jwks)
+	s.httpServer = &http.Server{Handler: mux}
+
+	go func() {
+		err := s.httpServer.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		s.errChan <- err
+	}()
+
+	return s, nil
+}
+
+func (s *HTTPServer) jwks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(s.service.JWKS()); err != nil {
+		s.logger.Warn("Failed to write JWKS", zap.Error(err))
+	}
+}
+
+func (s *HTTPServer) ErrChan() chan error {
+	return s.errChan
+}
+
+func (s *HTTPServer) Stop() {
+	s.httpServer.Close()
+}
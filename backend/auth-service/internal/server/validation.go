@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	v "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/validation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Limits of request fields. maxUsernameLength follows the users_username
+// column and maxEmailBytes the longest address SMTP allows; the rest only keep
+// requests to a sensible size.
+const (
+	maxUsernameLength     = 100
+	maxEmailBytes         = 254
+	maxPasswordBytes      = 1024
+	maxTokenBytes         = 8192
+	maxResetTokenBytes    = 256
+	maxRecoveryCodeLength = 64
+	maxNameLength         = 100
+	maxDescriptionLength  = 1000
+	maxReasonLength       = 1000
+	maxPageTokenBytes     = 1024
+	maxAuditTypes         = 50
+)
+
+var (
+	// Login tells usernames and emails apart by the "@", so usernames are
+	// limited to letters of any script, digits, dots, underscores and hyphens.
+	usernamePattern  = regexp.MustCompile(`^[\p{L}\p{N}._-]+$`)
+	totpCodePattern  = regexp.MustCompile(`^[0-9]{6}$`)
+	auditHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+func password() []v.Check {
+	return []v.Check{v.Required(), v.MaxBytes(maxPasswordBytes)}
+}
+
+func email() []v.Check {
+	return []v.Check{v.Required(), v.MaxBytes(maxEmailBytes), v.Email()}
+}
+
+func token(maxBytes int) []v.Check {
+	return []v.Check{v.Required(), v.MaxBytes(maxBytes)}
+}
+
+func userID() []v.Check {
+	return []v.Check{v.Required(), v.Min(1)}
+}
+
+func userListing() []v.FieldRules {
+	return []v.FieldRules{
+		v.Field("filter.role", v.MaxLen(maxNameLength)),
+		v.Field("filter.status", v.In(db.UserStatusPending, db.UserStatusActive, db.UserStatusSuspended, db.UserStatusDeleted)),
+		v.Field("sort_by", v.DefinedEnum()),
+		v.Field("page_size", v.Min(0)),
+		v.Field("page_token", v.MaxBytes(maxPageTokenBytes)),
+	}
+}
+
+// authRequestRules declares the rules of every AuthService request. Checks
+// that need the database or the caller, such as whether a role exists, and
+// the naming rules of roles and permissions stay in the service.
+func authRequestRules() []v.MessageRules {
+	return []v.MessageRules{
+		v.For(&pb.RegisterRequest{},
+			v.Field("username", v.Required(), v.MinLen(3), v.MaxLen(maxUsernameLength),
+				v.Pattern(usernamePattern, "may only contain letters, digits, dots, underscores and hyphens")),
+			v.Field("password", password()...),
+			v.Field("email", email()...),
+		),
+		v.For(&pb.LoginRequest{},
+			v.Field("username", v.MaxLen(maxUsernameLength)),
+			v.Field("password", password()...),
+			v.Field("identifier", v.MaxBytes(maxEmailBytes)),
+		),
+		v.For(&pb.ValidateTokenRequest{},
+			v.Field("token", token(maxTokenBytes)...),
+			v.Field("token_type", v.MaxLen(maxNameLength)),
+			v.Field("audience", v.MaxLen(maxNameLength)),
+			v.Field("issuer", v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.RefreshRequest{},
+			v.Field("refresh_token", token(maxTokenBytes)...),
+		),
+		v.For(&pb.LogoutRequest{},
+			v.Field("user_id", v.Min(0)),
+			v.Field("scope", v.DefinedEnum()),
+		),
+		v.For(&pb.ListSessionsRequest{}),
+		v.For(&pb.RevokeSessionRequest{},
+			v.Field("session_id", v.Required(), v.UUID()),
+		),
+		v.For(&pb.RequestPasswordResetRequest{},
+			v.Field("email", email()...),
+		),
+		v.For(&pb.ConfirmPasswordResetRequest{},
+			v.Field("token", token(maxResetTokenBytes)...),
+			v.Field("new_password", password()...),
+		),
+		v.For(&pb.VerifyEmailRequest{},
+			v.Field("token", token(maxResetTokenBytes)...),
+		),
+		v.For(&pb.ResendVerificationRequest{},
+			v.Field("email", email()...),
+		),
+		v.For(&pb.ChangePasswordRequest{},
+			v.Field("current_password", password()...),
+			v.Field("new_password", password()...),
+		),
+		v.For(&pb.ChangeEmailRequest{},
+			v.Field("password", password()...),
+			v.Field("new_email", email()...),
+		),
+		v.For(&pb.EnrollTOTPRequest{},
+			v.Field("password", password()...),
+		),
+		v.For(&pb.ConfirmTOTPRequest{},
+			v.Field("code", v.Required(), v.Pattern(totpCodePattern, "must consist of 6 digits")),
+		),
+		v.For(&pb.DisableTOTPRequest{},
+			v.Field("password", password()...),
+			v.Field("code", v.Required(), v.Pattern(totpCodePattern, "must consist of 6 digits")),
+		),
+		v.For(&pb.VerifyMFARequest{},
+			v.Field("mfa_token", token(maxTokenBytes)...),
+			v.Field("code", v.Pattern(totpCodePattern, "must consist of 6 digits")),
+			v.Field("recovery_code", v.MaxLen(maxRecoveryCodeLength)),
+		),
+		v.For(&pb.UnlockAccountRequest{},
+			v.Field("user_id", v.Min(0)),
+			v.Field("ip_address", v.IPAddress()),
+		),
+		v.For(&pb.GetJWKSRequest{}),
+		v.For(&pb.RotateSigningKeyRequest{}),
+		v.For(&pb.CreatePermissionRequest{},
+			v.Field("name", v.Required(), v.MaxLen(maxNameLength)),
+			v.Field("description", v.MaxLen(maxDescriptionLength)),
+		),
+		v.For(&pb.DeletePermissionRequest{},
+			v.Field("name", v.Required(), v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.ListPermissionsRequest{},
+			v.Field("role", v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.GrantPermissionRequest{},
+			v.Field("role", v.Required(), v.MaxLen(maxNameLength)),
+			v.Field("permission", v.Required(), v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.RevokePermissionRequest{},
+			v.Field("role", v.Required(), v.MaxLen(maxNameLength)),
+			v.Field("permission", v.Required(), v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.CheckPermissionRequest{},
+			v.Field("token", token(maxTokenBytes)...),
+			v.Field("permission", v.Required(), v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.CreateRoleRequest{},
+			v.Field("role", v.Required()),
+			v.Field("role.name", v.Required(), v.MaxLen(maxNameLength)),
+			v.Field("role.code", v.Min(0)),
+			v.Field("role.description", v.MaxLen(maxDescriptionLength)),
+		),
+		v.For(&pb.UpdateRoleRequest{},
+			v.Field("name", v.Required(), v.MaxLen(maxNameLength)),
+			v.Field("role", v.Required()),
+			v.Field("role.name", v.MaxLen(maxNameLength)),
+			v.Field("role.code", v.Min(0)),
+			v.Field("role.description", v.MaxLen(maxDescriptionLength)),
+		),
+		v.For(&pb.DeleteRoleRequest{},
+			v.Field("name", v.Required(), v.MaxLen(maxNameLength)),
+			v.Field("reassign_to", v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.ListRolesRequest{}),
+		v.For(&pb.AssignRoleRequest{},
+			v.Field("user_id", userID()...),
+			v.Field("role", v.Required(), v.MaxLen(maxNameLength)),
+		),
+		v.For(&pb.ListUsersRequest{}, userListing()...),
+		v.For(&pb.SearchUsersRequest{}, append(userListing(),
+			v.Field("query", v.Required(), v.MaxBytes(maxEmailBytes)),
+		)...),
+		v.For(&pb.GetUserRequest{},
+			v.Field("user_id", userID()...),
+		),
+		v.For(&pb.SuspendUserRequest{},
+			v.Field("user_id", userID()...),
+			v.Field("reason", v.Required(), v.MaxLen(maxReasonLength)),
+		),
+		v.For(&pb.ReinstateUserRequest{},
+			v.Field("user_id", userID()...),
+			v.Field("reason", v.MaxLen(maxReasonLength)),
+		),
+		v.For(&pb.DeleteUserRequest{},
+			v.Field("user_id", userID()...),
+			v.Field("reason", v.MaxLen(maxReasonLength)),
+		),
+		v.For(&pb.QueryAuditEventsRequest{},
+			v.Field("types", v.MaxItems(maxAuditTypes), v.Items(v.MaxLen(maxNameLength))),
+			v.Field("actor_id", v.Min(0)),
+			v.Field("subject_id", v.Min(0)),
+			v.Field("from", v.Min(0)),
+			v.Field("to", v.Min(0)),
+			v.Field("page_size", v.Min(0)),
+			v.Field("page_token", v.MaxBytes(maxPageTokenBytes)),
+		),
+		v.For(&pb.VerifyAuditLogRequest{},
+			v.Field("head_id", v.Min(0)),
+			v.Field("head_hash", v.Pattern(auditHashPattern, "must consist of 64 lowercase hex digits")),
+		),
+		v.For(&pb.ImportUsersRequest{},
+			v.Field("format", v.DefinedEnum()),
+			v.Field("data", v.Required()),
+		),
+	}
+}
+
+// newRequestValidator builds the validator of AuthService requests and makes
+// sure no RPC was left without rules.
+func newRequestValidator() (*v.Validator, error) {
+	validator, err := v.NewValidator(authRequestRules()...)
+	if err != nil {
+		return nil, err
+	}
+	methods := pb.File_proto_sso_proto.Services().ByName("AuthService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		if input := methods.Get(i).Input(); !validator.Declared(input) {
+			return nil, fmt.Errorf("no validation rules declared for %s", input.FullName())
+		}
+	}
+	return validator, nil
+}
+
+// validationInterceptor rejects invalid requests before they reach the
+// service.
+func validationInterceptor(validator *v.Validator, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if message, ok := req.(proto.Message); ok {
+			if err := validator.Validate(message); err != nil {
+				logger.Debug("Invalid request", zap.String("method", info.FullMethod), zap.Error(err))
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
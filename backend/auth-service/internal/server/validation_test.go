@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	v "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/validation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAuthRequestRules(t *testing.T) {
+	validator, err := newRequestValidator()
+	if err != nil {
+		t.Fatalf("newRequestValidator() error = %v", err)
+	}
+	const sessionID = "5f0c6d1e-3b7a-4c1d-9e2f-8a6b4c3d2e1f"
+	auditHash := strings.Repeat("0f", 32)
+
+	tests := []struct {
+		name    string
+		request proto.Message
+		want    []string // "field:reason" of every violation
+	}{
+		{name: "valid registration", request: &pb.RegisterRequest{Username: "jörg_1.x", Password: "secret", Email: "jorg@example.com"}},
+		{name: "empty registration", request: &pb.RegisterRequest{}, want: []string{"username:" + v.ReasonRequired, "password:" + v.ReasonRequired, "email:" + v.ReasonRequired}},
+		{name: "short username", request: &pb.RegisterRequest{Username: "jo", Password: "secret", Email: "jo@example.com"}, want: []string{"username:" + v.ReasonTooShort}},
+		{name: "username with an at", request: &pb.RegisterRequest{Username: "jo@example.com", Password: "secret", Email: "jo@example.com"}, want: []string{"username:" + v.ReasonInvalidFormat}},
+		{name: "long username", request: &pb.RegisterRequest{Username: strings.Repeat("a", maxUsernameLength+1), Password: "secret", Email: "jo@example.com"}, want: []string{"username:" + v.ReasonTooLong}},
+		{name: "long password", request: &pb.RegisterRequest{Username: "joe", Password: strings.Repeat("a", maxPasswordBytes+1), Email: "jo@example.com"}, want: []string{"password:" + v.ReasonTooLong}},
+		{name: "long email", request: &pb.RegisterRequest{Username: "joe", Password: "secret", Email: strings.Repeat("a", maxEmailBytes) + "@example.com"}, want: []string{"email:" + v.ReasonTooLong}},
+		{name: "login by email", request: &pb.LoginRequest{Password: "secret", Identifier: "jo@example.com"}},
+		{name: "login without password", request: &pb.LoginRequest{Username: "joe"}, want: []string{"password:" + v.ReasonRequired}},
+		{name: "long token", request: &pb.ValidateTokenRequest{Token: strings.Repeat("a", maxTokenBytes+1)}, want: []string{"token:" + v.ReasonTooLong}},
+		{name: "logout of everyone", request: &pb.LogoutRequest{}},
+		{name: "unknown logout scope", request: &pb.LogoutRequest{Scope: 42}, want: []string{"scope:" + v.ReasonUnknownValue}},
+		{name: "negative logout user", request: &pb.LogoutRequest{UserId: -1}, want: []string{"user_id:" + v.ReasonOutOfRange}},
+		{name: "revoke session", request: &pb.RevokeSessionRequest{SessionId: sessionID}},
+		{name: "revoke malformed session", request: &pb.RevokeSessionRequest{SessionId: "42"}, want: []string{"session_id:" + v.ReasonInvalidFormat}},
+		{name: "change email to a malformed address", request: &pb.ChangeEmailRequest{Password: "secret", NewEmail: "jo"}, want: []string{"new_email:" + v.ReasonInvalidFormat}},
+		{name: "enroll TOTP without password", request: &pb.EnrollTOTPRequest{}, want: []string{"password:" + v.ReasonRequired}},
+		{name: "confirm TOTP", request: &pb.ConfirmTOTPRequest{Code: "012345"}},
+		{name: "short TOTP code", request: &pb.ConfirmTOTPRequest{Code: "12345"}, want: []string{"code:" + v.ReasonInvalidFormat}},
+		{name: "MFA with a recovery code", request: &pb.VerifyMFARequest{MfaToken: "token", RecoveryCode: "abcde-fghjk"}},
+		{name: "unlock an address", request: &pb.UnlockAccountRequest{IpAddress: "2001:db8::1"}},
+		{name: "unlock a malformed address", request: &pb.UnlockAccountRequest{IpAddress: "10.0.0.256"}, want: []string{"ip_address:" + v.ReasonInvalidFormat}},
+		{name: "create role without one", request: &pb.CreateRoleRequest{}, want: []string{"role:" + v.ReasonRequired, "role.name:" + v.ReasonRequired}},
+		{name: "create role", request: &pb.CreateRoleRequest{Role: &pb.Role{Name: "editor", Code: 2}}},
+		{name: "assign role to nobody", request: &pb.AssignRoleRequest{Role: "editor"}, want: []string{"user_id:" + v.ReasonRequired}},
+		{name: "list users by status", request: &pb.ListUsersRequest{Filter: &pb.UserFilter{Status: "active"}}},
+		{name: "list users by unknown status", request: &pb.ListUsersRequest{Filter: &pb.UserFilter{Status: "banned"}}, want: []string{"filter.status:" + v.ReasonUnknownValue}},
+		{name: "search without query", request: &pb.SearchUsersRequest{PageSize: -1}, want: []string{"page_size:" + v.ReasonOutOfRange, "query:" + v.ReasonRequired}},
+		{name: "suspend without reason", request: &pb.SuspendUserRequest{UserId: 7}, want: []string{"reason:" + v.ReasonRequired}},
+		{name: "too many audit types", request: &pb.QueryAuditEventsRequest{Types: make([]string, maxAuditTypes+1)}, want: []string{"types:" + v.ReasonTooManyItems}},
+		{name: "long audit type", request: &pb.QueryAuditEventsRequest{Types: []string{"user.login", strings.Repeat("a", maxNameLength+1)}}, want: []string{"types[1]:" + v.ReasonTooLong}},
+		{name: "long page token", request: &pb.QueryAuditEventsRequest{PageToken: strings.Repeat("a", maxPageTokenBytes+1)}, want: []string{"page_token:" + v.ReasonTooLong}},
+		{name: "verify from a known head", request: &pb.VerifyAuditLogRequest{HeadId: 12, HeadHash: auditHash}},
+		{name: "uppercase head hash", request: &pb.VerifyAuditLogRequest{HeadId: 12, HeadHash: strings.ToUpper(auditHash)}, want: []string{"head_hash:" + v.ReasonInvalidFormat}},
+		{name: "import without data", request: &pb.ImportUsersRequest{Format: pb.ImportFormat_IMPORT_FORMAT_CSV}, want: []string{"data:" + v.ReasonRequired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range validator.Violations(tt.request) {
+				got = append(got, violation.Field+":"+violation.Reason)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Violations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationInterceptor(t *testing.T) {
+	validator, err := newRequestValidator()
+	if err != nil {
+		t.Fatalf("newRequestValidator() error = %v", err)
+	}
+	interceptor := validationInterceptor(validator, zap.NewNop())
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Refresh_FullMethodName}
+	called := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return &pb.RefreshResponse{}, nil
+	}
+
+	if _, err := interceptor(context.Background(), &pb.RefreshRequest{}, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("interceptor() of an invalid request code = %s, want %s", status.Code(err), codes.InvalidArgument)
+	}
+	if called {
+		t.Errorf("interceptor() called the handler with an invalid request")
+	}
+	if _, err := interceptor(context.Background(), &pb.RefreshRequest{RefreshToken: "token"}, info, handler); err != nil || !called {
+		t.Errorf("interceptor() of a valid request = %v, handler called %v", err, called)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"strconv"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Audit event types.
+const (
+	AuditUserRegistered     = "user.registered"
+	AuditLogin              = "auth.login"
+	AuditMFAVerified        = "auth.mfa_verified"
+	AuditRefreshTokenReused = "auth.refresh_token_reused"
+	AuditLogout             = "auth.logout"
+	AuditSessionRevoked     = "auth.session_revoked"
+	AuditPasswordChanged    = "user.password_changed"
+	AuditPasswordReset      = "user.password_reset"
+	AuditEmailVerified      = "user.email_verified"
+	AuditEmailChanged       = "user.email_changed"
+	AuditTOTPEnabled        = "user.totp_enabled"
+	AuditTOTPDisabled       = "user.totp_disabled"
+	AuditAccountUnlocked    = "admin.account_unlocked"
+	AuditUserStatusChanged  = "admin.user_status_changed"
+	AuditRoleCreated        = "admin.role_created"
+	AuditRoleUpdated        = "admin.role_updated"
+	AuditRoleDeleted        = "admin.role_deleted"
+	AuditRoleAssigned       = "admin.role_assigned"
+	AuditPermissionCreated  = "admin.permission_created"
+	AuditPermissionDeleted  = "admin.permission_deleted"
+	AuditPermissionGranted  = "admin.permission_granted"
+	AuditPermissionRevoked  = "admin.permission_revoked"
+	AuditSigningKeyRotated  = "admin.signing_key_rotated"
+	AuditUsersImported      = "admin.users_imported"
+)
+
+const (
+	defaultAuditPageSize       = 100
+	maxAuditPageSize           = 1000
+	auditVerificationBatchSize = 1000
+)
+
+var (
+	errLoginFailed        = status.Error(codes.Unauthenticated, "invalid credentials")
+	errRefreshTokenReused = status.Error(codes.Unauthenticated, "refresh token reuse detected")
+)
+
+// auditRecord describes an event for recordAudit. A nil err makes it a
+// success; otherwise the outcome is failure and the reason defaults to the
+// error message.
+type auditRecord struct {
+	eventType string
+	actorID   int64
+	subjectID int64
+	reason    string
+	err       error
+}
+
+// recordAudit appends an event to the audit log. A failure to write it is
+// logged but does not fail the audited action.
+func (s *AuthService) recordAudit(ctx context.Context, record auditRecord) {
+	ip, userAgent := s.clientInfo(ctx)
+	event := &db.AuditEvent{
+		Type:      record.eventType,
+		ActorID:   optionalID(record.actorID),
+		SubjectID: optionalID(record.subjectID),
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Outcome:   db.AuditOutcomeSuccess,
+		Reason:    record.reason,
+	}
+	if record.err != nil {
+		event.Outcome = db.AuditOutcomeFailure
+		if event.Reason == "" {
+			event.Reason = status.Convert(record.err).Message()
+		}
+	}
+	if _, err := s.db.AuditEventQuery().Append(ctx, event); err != nil {
+		s.logger.Error("Failed to record audit event",
+			zap.String("type", record.eventType),
+			zap.Int64("actor_id", record.actorID),
+			zap.Int64("subject_id", record.subjectID),
+			zap.Error(err))
+	}
+}
+
+func (s *AuthService) QueryAuditEvents(ctx context.Context, req *pb.QueryAuditEventsRequest) (*pb.QueryAuditEventsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionAuditRead)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Querying audit events", zap.Int64("admin_id", caller.ID), zap.Strings("types", req.Types))
+
+	filter := &db.AuditEventFilter{
+		Types:     req.Types,
+		ActorID:   req.ActorId,
+		SubjectID: req.SubjectId,
+		From:      unixFilter(req.From),
+		To:        unixFilter(req.To),
+		Limit:     defaultAuditPageSize,
+	}
+	switch {
+	case req.PageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page size must not be negative")
+	case req.PageSize > maxAuditPageSize:
+		filter.Limit = maxAuditPageSize
+	case req.PageSize > 0:
+		filter.Limit = uint64(req.PageSize)
+	}
+	if req.PageToken != "" {
+		beforeID, err := strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		filter.BeforeID = beforeID
+	}
+	limit := filter.Limit
+	// One extra row tells whether there is a next page.
+	filter.Limit++
+
+	events, err := s.db.AuditEventQuery().List(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list audit events", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list audit events")
+	}
+	resp := &pb.QueryAuditEventsResponse{}
+	if uint64(len(events)) > limit {
+		events = events[:limit]
+		resp.NextPageToken = strconv.FormatInt(events[len(events)-1].ID, 10)
+	}
+	resp.Events = make([]*pb.AuditEvent, 0, len(events))
+	for _, event := range events {
+		resp.Events = append(resp.Events, auditEventToProto(event))
+	}
+	return resp, nil
+}
+
+// VerifyAuditLog walks the whole chain and reports the first event whose
+// hash or link to its predecessor does not match. With a head from an earlier
+// verification it also reports a chain that no longer reaches that head,
+// which is how events removed from the end are noticed.
+func (s *AuthService) VerifyAuditLog(ctx context.Context, req *pb.VerifyAuditLogRequest) (*pb.VerifyAuditLogResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionAuditRead)
+	if err != nil {
+		return nil, err
+	}
+	if (req.HeadId == 0) != (req.HeadHash == "") {
+		return nil, status.Error(codes.InvalidArgument, "head_id and head_hash must be set together")
+	}
+	s.logger.Debug("Verifying audit log", zap.Int64("admin_id", caller.ID))
+
+	resp := &pb.VerifyAuditLogResponse{Valid: true}
+	prevHash := db.AuditGenesisHash
+	headReached := req.HeadId == 0
+	var afterID int64
+	for {
+		events, err := s.db.AuditEventQuery().ListChain(ctx, afterID, auditVerificationBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to list audit chain", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to list audit events")
+		}
+		for _, event := range events {
+			resp.CheckedEvents++
+			if event.PrevHash != prevHash || !hmac.Equal([]byte(event.ComputeHash(s.config.AuditHMACKey)), []byte(event.Hash)) {
+				s.logger.Warn("Audit chain broken", zap.Int64("event_id", event.ID))
+				resp.Valid = false
+				resp.FirstInvalidId = event.ID
+				return resp, nil
+			}
+			if event.ID == req.HeadId {
+				if event.Hash != req.HeadHash {
+					s.logger.Warn("Audit chain does not match the known head", zap.Int64("event_id", event.ID))
+					resp.Valid = false
+					resp.FirstInvalidId = event.ID
+					return resp, nil
+				}
+				headReached = true
+			}
+			prevHash = event.Hash
+			afterID = event.ID
+			resp.HeadId, resp.HeadHash = event.ID, event.Hash
+		}
+		if len(events) < auditVerificationBatchSize {
+			break
+		}
+	}
+	if !headReached {
+		s.logger.Warn("Audit chain does not reach the known head", zap.Int64("head_id", req.HeadId))
+		resp.Valid = false
+		resp.FirstInvalidId = req.HeadId
+		return resp, nil
+	}
+
+	s.logger.Info("Audit log verified", zap.Int64("admin_id", caller.ID), zap.Int64("events", resp.CheckedEvents))
+	return resp, nil
+}
+
+func optionalID(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func auditEventToProto(event *db.AuditEvent) *pb.AuditEvent {
+	res := &pb.AuditEvent{
+		Id:        event.ID,
+		Type:      event.Type,
+		IpAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Outcome:   event.Outcome,
+		Reason:    event.Reason,
+		CreatedAt: event.CreatedAt.UnixMicro(),
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+	}
+	if event.ActorID != nil {
+		res.ActorId = *event.ActorID
+	}
+	if event.SubjectID != nil {
+		res.SubjectId = *event.SubjectID
+	}
+	return res
+}
@@ -2,34 +2,49 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"strings"
 	"time"
 
 	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
 	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/keys"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/mail"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/passwords"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 const DefaultRoleName = "user"
 
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+	MFATokenType     = "mfa"
+)
+
 type AuthService struct {
 	pb.UnimplementedAuthServiceServer
-	db     db.Implementation
-	logger *zap.Logger
-	config config.AppConfig
+	db        db.Implementation
+	mailer    mail.Mailer
+	keys      *keys.Manager
+	passwords *passwords.Policy
+	hasher    *passwords.Hasher
+	logger    *zap.Logger
+	config    config.AppConfig
 }
 
-func NewAuthService(db db.Implementation, logger *zap.Logger, cfg config.AppConfig) *AuthService {
+func NewAuthService(db db.Implementation, mailer mail.Mailer, keyManager *keys.Manager, passwordPolicy *passwords.Policy, hasher *passwords.Hasher, logger *zap.Logger, cfg config.AppConfig) *AuthService {
 	return &AuthService{
-		db:     db,
-		logger: logger,
-		config: cfg,
+		db:        db,
+		mailer:    mailer,
+		keys:      keyManager,
+		passwords: passwordPolicy,
+		hasher:    hasher,
+		logger:    logger,
+		config:    cfg,
 	}
 }
 
@@ -38,19 +53,30 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	exists, err := s.db.UserQuery().ExistsByUsernameOrEmail(ctx, req.Username, req.Email)
+	username := strings.TrimSpace(req.Username)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	// Login tells usernames and emails apart by the "@".
+	if strings.Contains(username, "@") {
+		return nil, status.Error(codes.InvalidArgument, "username must not contain @")
+	}
+
+	if err := s.checkPasswordPolicy("password", req.Password, username, email); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.db.UserQuery().ExistsByUsernameOrEmail(ctx, username, email)
 	if err != nil {
 		s.logger.Error("Failed to check uniqueness", zap.Error(err))
 		return nil, status.Error(codes.Internal, "failed to check uniqueness")
 	}
 	if exists {
 		s.logger.Warn("Username or email already exists",
-			zap.String("username", req.Username),
-			zap.String("email", req.Email))
+			zap.String("username", username),
+			zap.String("email", email))
 		return nil, status.Error(codes.AlreadyExists, "username or email already exists")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		s.logger.Error("Failed to hash password", zap.Error(err))
 		return nil, status.Error(codes.Internal, "failed to hash password")
@@ -72,13 +98,19 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		return nil, status.Error(codes.Internal, "failed to get default role ID")
 	}
 	newUser := &db.User{
-		Username:           req.Username,
-		Password:           string(hashedPassword),
-		Email:              req.Email,
+		Username:           username,
+		Password:           hashedPassword,
+		Email:              email,
 		RoleID:             DefaultRoleID,
+		Status:             db.UserStatusActive,
 		AccessTokenSecret:  accessTokenSecret,
 		RefreshTokenSecret: refreshTokenSecret,
 	}
+	// In required mode the account only becomes usable once the email is
+	// verified.
+	if s.config.EmailVerificationMode == config.EmailVerificationRequired {
+		newUser.Status = db.UserStatusPending
+	}
 
 	_, err = s.db.UserQuery().Insert(ctx, newUser)
 	if err != nil {
@@ -86,28 +118,76 @@ func (s *AuthService) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		return nil, status.Error(codes.Internal, "failed to insert user")
 	}
 
-	s.logger.Info("User registered successfully", zap.String("username", req.Username))
+	if s.config.EmailVerificationMode != config.EmailVerificationOff {
+		if err := s.sendVerification(ctx, newUser); err != nil {
+			// The account exists already; the user can ask for another mail.
+			s.logger.Warn("Failed to send verification after registration", zap.Int64("user_id", newUser.ID))
+		}
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditUserRegistered, subjectID: newUser.ID})
+	s.logger.Info("User registered successfully", zap.String("username", username))
 	return &pb.RegisterResponse{}, nil
 }
 
 func (s *AuthService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	s.logger.Debug("Logging in user", zap.String("username", req.Username))
+	identifier := req.Identifier
+	if identifier == "" {
+		identifier = req.Username
+	}
+	identifier, isEmail := normalizeIdentifier(identifier)
+	s.logger.Debug("Logging in user", zap.String("identifier", identifier))
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	user, err := s.db.UserQuery().GetByUsername(ctx, req.Username)
+	user, err := s.userByIdentifier(ctx, identifier, isEmail)
 	if err != nil {
-		s.logger.Error("Failed to fetch user", zap.Error(err))
-		return nil, status.Error(codes.Internal, "failed to fetch user")
+		return nil, err
 	}
-	if user == nil {
-		s.logger.Warn("User not found", zap.String("username", req.Username))
-		return nil, status.Error(codes.NotFound, "user not found")
+	// Failures are counted per account whichever identifier was used, so
+	// alternating between username and email does not buy extra attempts.
+	lockKey := identifier
+	if user != nil {
+		lockKey = user.Username
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		s.logger.Warn("Invalid password", zap.String("username", req.Username))
-		return nil, status.Error(codes.Unauthenticated, "invalid password")
+	ip, _ := s.clientInfo(ctx)
+	if err := s.checkLoginLock(ctx, lockKey, ip); err != nil {
+		s.recordAudit(ctx, auditRecord{eventType: AuditLogin, err: err})
+		return nil, err
+	}
+
+	if user == nil || user.Status == db.UserStatusDeleted {
+		s.logger.Warn("User not found", zap.String("identifier", identifier))
+		// The attempted name is not recorded: users now and then type their
+		// password into the username field.
+		s.recordAudit(ctx, auditRecord{eventType: AuditLogin, reason: "unknown user", err: errLoginFailed})
+		return nil, s.loginFailed(ctx, lockKey, ip, status.Error(codes.NotFound, "user not found"))
+	}
+
+	ok, rehash := s.checkPassword(user, req.Password)
+	if !ok {
+		s.logger.Warn("Invalid password", zap.Int64("user_id", user.ID))
+		s.recordAudit(ctx, auditRecord{eventType: AuditLogin, subjectID: user.ID, reason: "invalid password", err: errLoginFailed})
+		return nil, s.loginFailed(ctx, lockKey, ip, status.Error(codes.Unauthenticated, "invalid password"))
+	}
+	if rehash {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	if err := s.accountStatusError(user); err != nil {
+		s.recordAudit(ctx, auditRecord{eventType: AuditLogin, subjectID: user.ID, err: err})
+		return nil, err
+	}
+
+	// Registration leaves unverified accounts pending, but accounts that were
+	// active before verification became required, such as imported ones, are
+	// refused here.
+	if s.config.EmailVerificationMode == config.EmailVerificationRequired && !user.EmailVerified {
+		s.logger.Warn("Email not verified", zap.Int64("user_id", user.ID))
+		err := status.Error(codes.FailedPrecondition, "email not verified")
+		s.recordAudit(ctx, auditRecord{eventType: AuditLogin, subjectID: user.ID, reason: "email not verified", err: err})
+		return nil, err
 	}
 
 	role, err := s.db.RoleQuery().GetByID(ctx, user.RoleID)
@@ -120,149 +200,267 @@ func (s *AuthService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 		return nil, status.Error(codes.NotFound, "role not found")
 	}
 
-	accessJTI := uuid.New().String()
-	refreshJTI := uuid.New().String()
+	// With a second factor pending the failure counter stays as it is until
+	// VerifyMFA or ConfirmTOTP completes the login.
+	if user.TOTPEnabled || role.MFARequired {
+		return s.mfaChallenge(ctx, user, !user.TOTPEnabled)
+	}
+
+	s.loginSucceeded(ctx, lockKey)
+	accessToken, refreshToken, err := s.startSession(ctx, user, role)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditLogin, subjectID: user.ID})
+	s.logger.Info("User logged in successfully", zap.Int64("user_id", user.ID), zap.String("username", user.Username))
+	return &pb.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
 
-	accessToken, err := s.generateJWT(user.ID, "access", role.Name, s.config.ACCESS_TOKEN_EXPIRES_IN, []byte(user.AccessTokenSecret), accessJTI)
+// userByIdentifier finds the user a login identifier refers to, or nil.
+// Usernames containing "@" are refused at registration, but older accounts
+// may have one, so an email that matches nobody is tried as a username too.
+// Emails are compared ignoring case, usernames are not.
+func (s *AuthService) userByIdentifier(ctx context.Context, identifier string, isEmail bool) (*db.User, error) {
+	var user *db.User
+	var err error
+	if isEmail {
+		user, err = s.db.UserQuery().GetByEmail(ctx, strings.ToLower(identifier))
+	}
+	if err == nil && user == nil {
+		user, err = s.db.UserQuery().GetByUsername(ctx, identifier)
+	}
 	if err != nil {
-		s.logger.Error("Failed to generate access token", zap.Error(err))
-		return nil, status.Error(codes.Internal, "failed to generate access token")
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
 	}
-	refreshToken, err := s.generateJWT(user.ID, "refresh", role.Name, s.config.REFRESH_TOKEN_EXPIRES_IN, []byte(user.RefreshTokenSecret), refreshJTI)
+	return user, nil
+}
+
+// normalizeIdentifier trims a login identifier and tells whether it is an
+// email address.
+func normalizeIdentifier(identifier string) (string, bool) {
+	identifier = strings.TrimSpace(identifier)
+	return identifier, strings.Contains(identifier, "@")
+}
+
+// startSession opens a new session for a fully authenticated user and issues
+// its first token pair.
+func (s *AuthService) startSession(ctx context.Context, user *db.User, role *db.Role) (string, string, error) {
+	ip, userAgent := s.clientInfo(ctx)
+	session := &db.Session{
+		ID:              uuid.New().String(),
+		UserID:          user.ID,
+		AccessTokenJTI:  uuid.New().String(),
+		RefreshTokenJTI: uuid.New().String(),
+		UserAgent:       userAgent,
+		IPAddress:       ip,
+		ExpiresAt:       time.Now().Add(s.config.REFRESH_TOKEN_EXPIRES_IN),
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(ctx, user, role, session)
 	if err != nil {
-		s.logger.Error("Failed to generate refresh token", zap.Error(err))
-		return nil, status.Error(codes.Internal, "failed to generate refresh token")
+		return "", "", err
 	}
 
-	user.AccessTokenJTI = &accessJTI
-	user.RefreshTokenJTI = &refreshJTI
-	_, err = s.db.UserQuery().UpdateLoginOrLogout(ctx, user, user.ID)
+	_, err = s.db.SessionQuery().Insert(ctx, session)
+	if err != nil {
+		s.logger.Error("Failed to create session", zap.Error(err))
+		return "", "", status.Error(codes.Internal, "failed to create session")
+	}
 	_, err = s.db.UserQuery().UpdateAuthTime(ctx, user.ID)
 	if err != nil {
-		s.logger.Error("Failed to update token JTI", zap.Error(err))
-		return nil, status.Error(codes.Internal, "failed to update token JTI")
+		s.logger.Error("Failed to update auth time", zap.Error(err))
+		return "", "", status.Error(codes.Internal, "failed to update auth time")
 	}
 
-	s.logger.Info("User logged in successfully", zap.Int64("user_id", user.ID), zap.String("username", req.Username))
-	return &pb.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}, nil
+	s.logger.Info("Session started", zap.Int64("user_id", user.ID), zap.String("session_id", session.ID))
+	return accessToken, refreshToken, nil
 }
 
-func (s *AuthService) Logout(ctx context.Context, userID int64) error {
+func (s *AuthService) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	s.logger.Debug("Refreshing tokens")
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	user, err := s.db.UserQuery().GetByID(ctx, userID)
+	user, claims, err := s.parseToken(ctx, req.RefreshToken, RefreshTokenType, s.ownExpectation())
 	if err != nil {
-		s.logger.Error("Failed to fetch user", zap.Error(err))
-		return status.Error(codes.Internal, "failed to fetch user")
+		s.logger.Warn("Failed to parse refresh token", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
 	}
-	if user == nil {
-		s.logger.Warn("User not found", zap.Int64("user_id", userID))
-		return status.Error(codes.NotFound, "user not found")
+	if err := s.accountStatusError(user); err != nil {
+		return nil, err
 	}
 
-	user.AccessTokenJTI = nil
-	user.RefreshTokenJTI = nil
-	_, err = s.db.UserQuery().UpdateLoginOrLogout(ctx, user, user.ID)
+	if claims.SessionID == "" {
+		s.logger.Warn("Refresh token without session", zap.Int64("user_id", user.ID))
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+	session, err := s.db.SessionQuery().GetByID(ctx, claims.SessionID)
 	if err != nil {
-		s.logger.Error("Failed to update token JTI", zap.Error(err))
-		return status.Error(codes.Internal, "failed to update token JTI")
+		s.logger.Error("Failed to fetch session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch session")
+	}
+	if session == nil || session.UserID != user.ID {
+		s.logger.Warn("Refresh token revoked", zap.Int64("user_id", user.ID))
+		return nil, status.Error(codes.Unauthenticated, "token revoked (user logged out)")
+	}
+	if session.RefreshTokenJTI != claims.ID {
+		s.revokeOnReuse(ctx, session)
+		return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected")
 	}
 
-	s.logger.Info("User logged out successfully", zap.Int64("user_id", userID))
-	return nil
+	role, err := s.db.RoleQuery().GetByID(ctx, user.RoleID)
+	if err != nil {
+		s.logger.Error("Failed to fetch role", zap.Error(err), zap.Int64("role_id", user.RoleID))
+		return nil, status.Error(codes.Internal, "failed to fetch role")
+	}
+	if role == nil {
+		s.logger.Warn("Role not found", zap.Int64("role_id", user.RoleID))
+		return nil, status.Error(codes.NotFound, "role not found")
+	}
+
+	oldRefreshJTI := session.RefreshTokenJTI
+	session.AccessTokenJTI = uuid.New().String()
+	session.RefreshTokenJTI = uuid.New().String()
+	session.ExpiresAt = time.Now().Add(s.config.REFRESH_TOKEN_EXPIRES_IN)
+
+	accessToken, refreshToken, err := s.issueTokens(ctx, user, role, session)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.db.SessionQuery().Rotate(ctx, session.ID, oldRefreshJTI, session.AccessTokenJTI, session.RefreshTokenJTI, session.ExpiresAt)
+	if err != nil {
+		s.logger.Error("Failed to rotate session tokens", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to rotate session tokens")
+	}
+	if rotated == nil {
+		// A concurrent request presented the same refresh token first.
+		s.revokeOnReuse(ctx, session)
+		return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected")
+	}
+
+	s.logger.Info("Tokens refreshed successfully", zap.Int64("user_id", user.ID), zap.String("session_id", session.ID))
+	return &pb.RefreshResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revokeOnReuse treats presentation of an already rotated refresh token as
+// token theft and revokes the whole session it belongs to.
+func (s *AuthService) revokeOnReuse(ctx context.Context, session *db.Session) {
+	s.logger.Warn("Refresh token reuse detected, revoking session",
+		zap.Int64("user_id", session.UserID),
+		zap.String("session_id", session.ID))
+	if err := s.db.SessionQuery().Delete(ctx, session.ID); err != nil {
+		s.logger.Error("Failed to revoke session", zap.String("session_id", session.ID), zap.Error(err))
+	}
+	s.recordAudit(ctx, auditRecord{
+		eventType: AuditRefreshTokenReused,
+		subjectID: session.UserID,
+		reason:    "session " + session.ID + " revoked",
+		err:       errRefreshTokenReused,
+	})
 }
 
-func (s *AuthService) ValidateToken(ctx context.Context, tokenString string, tokenType string) (int64, error) {
+func (s *AuthService) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return nil, fmt.Errorf("invalid token claims")
-		}
-		userIDFloat, ok := claims["sub"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("invalid user ID in token")
-		}
-		userID := int64(userIDFloat)
+	user, session, err := s.authenticateAny(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		claimedTokenType, ok := claims["type"].(string)
-		if !ok || claimedTokenType != tokenType {
-			return nil, fmt.Errorf("invalid token type: expected %s, got %s", tokenType, claimedTokenType)
+	if req.UserId != 0 && req.UserId != user.ID {
+		if err := s.requirePermission(ctx, user, PermissionUsersAdmin); err != nil {
+			return nil, err
 		}
+		return s.logoutUser(ctx, user.ID, req.UserId)
+	}
 
-		claimedJTI, ok := claims["jti"].(string)
-		if !ok {
-			return nil, fmt.Errorf("invalid jti in token")
-		}
+	if req.Scope == pb.LogoutScope_LOGOUT_SCOPE_ALL {
+		return s.logoutUser(ctx, user.ID, user.ID)
+	}
 
-		user, err := s.db.UserQuery().GetByID(ctx, userID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch user: %w", err)
-		}
-		if user == nil {
-			return nil, fmt.Errorf("user not found")
-		}
+	if err := s.db.SessionQuery().Delete(ctx, session.ID); err != nil {
+		s.logger.Error("Failed to revoke session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
 
-		if tokenType == "access" {
-			if user.AccessTokenJTI == nil || *user.AccessTokenJTI == "" {
-				return nil, fmt.Errorf("token revoked (user logged out)")
-			}
-			if *user.AccessTokenJTI != claimedJTI {
-				return nil, fmt.Errorf("invalid access token jti")
-			}
-			return []byte(user.AccessTokenSecret), nil
-		}
-		if user.RefreshTokenJTI == nil || *user.RefreshTokenJTI == "" {
-			return nil, fmt.Errorf("token revoked (user logged out)")
-		}
-		if *user.RefreshTokenJTI != claimedJTI {
-			return nil, fmt.Errorf("invalid refresh token jti")
-		}
-		return []byte(user.RefreshTokenSecret), nil
-	})
+	s.recordAudit(ctx, auditRecord{eventType: AuditLogout, actorID: user.ID, subjectID: user.ID, reason: "session " + session.ID})
+	s.logger.Info("User logged out of session", zap.Int64("user_id", user.ID), zap.String("session_id", session.ID))
+	return &pb.LogoutResponse{}, nil
+}
 
+// logoutUser revokes every session of the user on behalf of actorID.
+func (s *AuthService) logoutUser(ctx context.Context, actorID int64, userID int64) (*pb.LogoutResponse, error) {
+	user, err := s.db.UserQuery().GetByID(ctx, userID)
 	if err != nil {
-		s.logger.Error("Failed to parse token", zap.Error(err))
-		return 0, status.Error(codes.Unauthenticated, "invalid token")
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
 	}
-
-	if !token.Valid {
-		s.logger.Warn("Invalid token", zap.String("token_type", tokenType))
-		return 0, status.Error(codes.Unauthenticated, "token expired or invalid")
+	if user == nil {
+		s.logger.Warn("User not found", zap.Int64("user_id", userID))
+		return nil, status.Error(codes.NotFound, "user not found")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, status.Error(codes.Unauthenticated, "invalid token claims")
-	}
-	userIDFloat, ok := claims["sub"].(float64)
-	if !ok {
-		return 0, status.Error(codes.Unauthenticated, "invalid user ID in token")
+	_, err = s.db.SessionQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to revoke sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
 	}
-	userID := int64(userIDFloat)
 
-	s.logger.Info("Token validated successfully", zap.Int64("user_id", userID), zap.String("token_type", tokenType))
-	return userID, nil
+	s.recordAudit(ctx, auditRecord{eventType: AuditLogout, actorID: actorID, subjectID: user.ID, reason: "all sessions"})
+	s.logger.Info("User logged out everywhere", zap.Int64("user_id", userID))
+	return &pb.LogoutResponse{}, nil
 }
 
-func (s *AuthService) generateJWT(userID int64, tokenType string, roleName string, expiresIn time.Duration, secretKey []byte, jti string) (string, error) {
-	claims := jwt.MapClaims{
-		"sub":  userID,
-		"type": tokenType,
-		"role": roleName,
-		"exp":  time.Now().Add(expiresIn).Unix(),
-		"iat":  time.Now().Unix(),
-		"jti":  jti,
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
+// ValidateToken verifies the token and describes it, in the spirit of RFC 7662
+// introspection: who the caller is, under which role and session, and when the
+// token was issued and expires.
+// The caller may name the issuer and audience it expects; by default the
+// token must be issued by and for this service.
+func (s *AuthService) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tokenType := req.TokenType
+	expect := s.ownExpectation()
+	if req.Issuer != "" {
+		expect.issuer = req.Issuer
+	}
+	if req.Audience != "" {
+		expect.audience = req.Audience
+	}
+	user, session, claims, err := s.verifyTokenClaims(ctx, req.Token, tokenType, expect)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.userRole(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := s.rolePermissions(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Token validated successfully", zap.Int64("user_id", user.ID), zap.String("token_type", tokenType))
+	return &pb.ValidateTokenResponse{
+		UserId:      user.ID,
+		Username:    user.Username,
+		Role:        role.Name,
+		Scopes:      strings.Fields(claims.Scope),
+		SessionId:   session.ID,
+		IssuedAt:    claims.IssuedAt,
+		ExpiresAt:   claims.ExpiresAt,
+		AuthTime:    claims.AuthTime,
+		Permissions: permissions,
+	}, nil
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	session, _, refreshToken := openTestSession(t, s, fake, user)
+
+	res, err := s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if res.RefreshToken == refreshToken {
+		t.Errorf("Refresh() returned the presented refresh token")
+	}
+	rotated := fake.sessions.get(session.ID)
+	if rotated == nil || rotated.RefreshTokenJTI == session.RefreshTokenJTI || rotated.AccessTokenJTI == session.AccessTokenJTI {
+		t.Fatalf("Refresh() did not rotate the JTIs of the session")
+	}
+	if _, _, err := s.verifyToken(context.Background(), res.AccessToken, AccessTokenType); err != nil {
+		t.Errorf("new access token is not accepted: %v", err)
+	}
+
+	if _, err := s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: res.RefreshToken}); err != nil {
+		t.Errorf("Refresh() with the rotated refresh token error = %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	session, _, stolen := openTestSession(t, s, fake, user)
+
+	res, err := s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: stolen})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	_, err = s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: stolen})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Refresh() with a rotated refresh token error = %v, want %s", err, codes.Unauthenticated)
+	}
+	if fake.sessions.get(session.ID) != nil {
+		t.Errorf("reuse of a refresh token did not revoke the session")
+	}
+	events := fake.audit.find(AuditRefreshTokenReused)
+	if len(events) != 1 || events[0].Outcome != db.AuditOutcomeFailure || *events[0].SubjectID != user.ID {
+		t.Errorf("audit events of the reuse = %+v, want one failure about user %d", events, user.ID)
+	}
+
+	// The legitimate holder loses the session too.
+	if _, err := s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: res.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Refresh() after the session was revoked error = %v, want %s", err, codes.Unauthenticated)
+	}
+}
+
+func TestRefreshConcurrentReuseRevokesSession(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	session, _, refreshToken := openTestSession(t, s, fake, user)
+
+	// Another request rotates the session between the JTI check and the
+	// rotation of this one.
+	fake.sessions.beforeRotate = func() {
+		fake.sessions.beforeRotate = nil
+		if _, err := s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: refreshToken}); err != nil {
+			t.Errorf("concurrent Refresh() error = %v", err)
+		}
+	}
+
+	_, err := s.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: refreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Refresh() that lost the race error = %v, want %s", err, codes.Unauthenticated)
+	}
+	if fake.sessions.get(session.ID) != nil {
+		t.Errorf("a refresh token presented twice did not revoke the session")
+	}
+	if len(fake.audit.find(AuditRefreshTokenReused)) != 1 {
+		t.Errorf("reuse was not audited")
+	}
+}
+
+func TestLoginIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		want       codes.Code
+	}{
+		{name: "username", identifier: "user1"},
+		{name: "email", identifier: "user1@example.com"},
+		{name: "email in other case", identifier: "  User1@Example.COM "},
+		{name: "username in other case", identifier: "User1", want: codes.NotFound},
+		{name: "unknown email", identifier: "user2@example.com", want: codes.NotFound},
+		{name: "legacy username with an at", identifier: "old@name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newTestService(t)
+			addTestUser(t, s, fake, 1, "correct horse battery")
+			legacy := addTestUser(t, s, fake, 3, "correct horse battery")
+			legacy.Username = "old@name"
+
+			res, err := s.Login(context.Background(), &pb.LoginRequest{Identifier: tt.identifier, Password: "correct horse battery"})
+			if status.Code(err) != tt.want {
+				t.Fatalf("Login() error = %v, want %s", err, tt.want)
+			}
+			if tt.want == codes.OK && res.AccessToken == "" {
+				t.Errorf("Login() returned no access token")
+			}
+		})
+	}
+}
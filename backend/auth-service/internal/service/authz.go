@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userRole fetches the role currently assigned to the user.
+func (s *AuthService) userRole(ctx context.Context, user *db.User) (*db.Role, error) {
+	role, err := s.db.RoleQuery().GetByID(ctx, user.RoleID)
+	if err != nil {
+		s.logger.Error("Failed to fetch role", zap.Error(err), zap.Int64("role_id", user.RoleID))
+		return nil, status.Error(codes.Internal, "failed to fetch role")
+	}
+	if role == nil {
+		s.logger.Warn("Role not found", zap.Int64("role_id", user.RoleID))
+		return nil, status.Error(codes.NotFound, "role not found")
+	}
+	return role, nil
+}
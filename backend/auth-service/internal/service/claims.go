@@ -0,0 +1,17 @@
+package service
+
+import (
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/pkg/authn"
+)
+
+// expectation is what a token must have been issued by and for.
+type expectation struct {
+	issuer   string
+	audience string
+}
+
+func (e expectation) validate(claims *authn.Claims, leeway time.Duration, now time.Time) error {
+	return claims.Validate(e.issuer, e.audience, leeway, now)
+}
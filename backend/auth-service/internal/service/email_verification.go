@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/mail"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	s.logger.Debug("Verifying email")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	verification, err := s.db.EmailVerificationQuery().GetByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		s.logger.Error("Failed to fetch verification token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch verification token")
+	}
+	if verification == nil || verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		s.logger.Warn("Invalid or expired verification token")
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired verification token")
+	}
+
+	used, err := s.db.EmailVerificationQuery().MarkUsed(ctx, verification.ID)
+	if err != nil {
+		s.logger.Error("Failed to consume verification token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to consume verification token")
+	}
+	if !used {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired verification token")
+	}
+
+	user, err := s.db.UserQuery().MarkEmailVerified(ctx, verification.UserID)
+	if err != nil {
+		s.logger.Error("Failed to mark email as verified", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to mark email as verified")
+	}
+	if user.Status == db.UserStatusPending {
+		if _, err := s.changeStatus(ctx, user.ID, db.UserStatusActive, "email verified", nil); err != nil {
+			return nil, err
+		}
+	}
+	_, err = s.db.EmailVerificationQuery().DeleteByUserID(ctx, verification.UserID)
+	if err != nil {
+		s.logger.Warn("Failed to delete outstanding verification tokens", zap.Int64("user_id", verification.UserID), zap.Error(err))
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditEmailVerified, subjectID: verification.UserID})
+	s.logger.Info("Email verified successfully", zap.Int64("user_id", verification.UserID))
+	return &pb.VerifyEmailResponse{}, nil
+}
+
+// ResendVerification mails a fresh verification token. Like
+// RequestPasswordReset it does not reveal whether the email is registered.
+func (s *AuthService) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	s.logger.Debug("Resending verification email", zap.String("email", req.Email))
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	if s.config.EmailVerificationMode == config.EmailVerificationOff {
+		return nil, status.Error(codes.FailedPrecondition, "email verification is disabled")
+	}
+
+	user, err := s.db.UserQuery().GetByEmail(ctx, req.Email)
+	if err != nil {
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
+	}
+	if user == nil || user.EmailVerified {
+		s.logger.Warn("Verification requested for unknown or verified email", zap.String("email", req.Email))
+		return &pb.ResendVerificationResponse{}, nil
+	}
+
+	latest, err := s.db.EmailVerificationQuery().GetLatestByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to fetch latest verification", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch latest verification")
+	}
+	if latest != nil && latest.CreatedAt != nil && time.Since(*latest.CreatedAt) < s.config.EMAIL_VERIFICATION_RESEND_INTERVAL {
+		s.logger.Warn("Verification resend throttled", zap.Int64("user_id", user.ID))
+		return nil, status.Error(codes.ResourceExhausted, "verification email was sent recently, try again later")
+	}
+
+	if err := s.sendVerification(ctx, user); err != nil {
+		return nil, err
+	}
+	return &pb.ResendVerificationResponse{}, nil
+}
+
+// ChangeEmail moves the caller to a new email address. The address counts as
+// unverified until the token mailed to it is confirmed, and tokens mailed to
+// the old address are revoked.
+func (s *AuthService) ChangeEmail(ctx context.Context, req *pb.ChangeEmailRequest) (*pb.ChangeEmailResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	user, _, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Changing email", zap.Int64("user_id", user.ID))
+
+	if err := s.reauthenticate(ctx, user, req.Password); err != nil {
+		s.recordAudit(ctx, auditRecord{eventType: AuditEmailChanged, actorID: user.ID, subjectID: user.ID, err: err})
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	if strings.EqualFold(email, user.Email) {
+		return nil, status.Error(codes.InvalidArgument, "new email must differ from the current one")
+	}
+
+	existing, err := s.db.UserQuery().GetByEmail(ctx, email)
+	if err != nil {
+		s.logger.Error("Failed to check uniqueness", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to check uniqueness")
+	}
+	if existing != nil {
+		s.logger.Warn("Email already exists", zap.Int64("user_id", user.ID), zap.String("email", email))
+		return nil, status.Error(codes.AlreadyExists, "email already exists")
+	}
+
+	updated, err := s.db.UserQuery().ChangeEmail(ctx, user.ID, email)
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "email already exists")
+		}
+		s.logger.Error("Failed to change email", zap.Int64("user_id", user.ID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to change email")
+	}
+	if updated == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	_, err = s.db.EmailVerificationQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Warn("Failed to delete outstanding verification tokens", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+	_, err = s.db.PasswordResetQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Warn("Failed to delete outstanding reset tokens", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditEmailChanged, actorID: user.ID, subjectID: user.ID})
+	s.logger.Info("Email changed successfully", zap.Int64("user_id", user.ID))
+
+	if s.config.EmailVerificationMode != config.EmailVerificationOff {
+		if err := s.sendVerification(ctx, updated); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.ChangeEmailResponse{}, nil
+}
+
+// sendVerification stores a new verification token for the user and mails it.
+func (s *AuthService) sendVerification(ctx context.Context, user *db.User) error {
+	token, err := db.GenerateSecretKey()
+	if err != nil {
+		s.logger.Error("Failed to generate verification token", zap.Error(err))
+		return status.Error(codes.Internal, "failed to generate verification token")
+	}
+	_, err = s.db.EmailVerificationQuery().Insert(ctx, &db.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.config.EMAIL_VERIFICATION_EXPIRES_IN),
+	})
+	if err != nil {
+		s.logger.Error("Failed to store verification token", zap.Error(err))
+		return status.Error(codes.Internal, "failed to store verification token")
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body:    s.verificationBody(user.Username, token),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send verification email", zap.Int64("user_id", user.ID), zap.Error(err))
+		return status.Error(codes.Internal, "failed to send verification email")
+	}
+
+	s.logger.Info("Verification email sent", zap.Int64("user_id", user.ID))
+	return nil
+}
+
+func (s *AuthService) verificationBody(username string, token string) string {
+	body := fmt.Sprintf("Hello, %s!\n\nPlease confirm that this email belongs to you.\n", username)
+	if s.config.EmailVerificationURL != "" {
+		body += fmt.Sprintf("Open the link below:\n\n%s?%s\n",
+			s.config.EmailVerificationURL, url.Values{"token": {token}}.Encode())
+	} else {
+		body += fmt.Sprintf("Use this code:\n\n%s\n", token)
+	}
+	body += fmt.Sprintf("\nThe code expires in %s. If you did not register, ignore this message.\n", s.config.EMAIL_VERIFICATION_EXPIRES_IN)
+	return body
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/keys"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/passwords"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const testUserRoleID = 1
+
+// fakeDB keeps the tables the service tests touch in memory. The embedded
+// interfaces are nil: a query a test did not expect panics.
+type fakeDB struct {
+	db.Implementation
+	users          *fakeUsers
+	roles          *fakeRoles
+	sessions       *fakeSessions
+	audit          *fakeAudit
+	signingKeys    *fakeSigningKeys
+	loginAttempts  *fakeLoginAttempts
+	passwordResets *fakePasswordResets
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		users:          &fakeUsers{users: make(map[int64]*db.User)},
+		roles:          &fakeRoles{roles: map[int64]*db.Role{testUserRoleID: {ID: testUserRoleID, Code: 1, Name: DefaultRoleName}}},
+		sessions:       &fakeSessions{sessions: make(map[string]*db.Session)},
+		audit:          &fakeAudit{},
+		signingKeys:    &fakeSigningKeys{},
+		loginAttempts:  &fakeLoginAttempts{attempts: make(map[loginScope]*db.LoginAttempt)},
+		passwordResets: &fakePasswordResets{},
+	}
+}
+
+func (f *fakeDB) UserQuery() db.UserQuery                   { return f.users }
+func (f *fakeDB) RoleQuery() db.RoleQuery                   { return f.roles }
+func (f *fakeDB) SessionQuery() db.SessionQuery             { return f.sessions }
+func (f *fakeDB) AuditEventQuery() db.AuditEventQuery       { return f.audit }
+func (f *fakeDB) SigningKeyQuery() db.SigningKeyQuery       { return f.signingKeys }
+func (f *fakeDB) LoginAttemptQuery() db.LoginAttemptQuery   { return f.loginAttempts }
+func (f *fakeDB) PasswordResetQuery() db.PasswordResetQuery { return f.passwordResets }
+
+type fakeUsers struct {
+	db.UserQuery
+	mu    sync.Mutex
+	users map[int64]*db.User
+}
+
+func (f *fakeUsers) GetByID(ctx context.Context, id int64) (*db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, ok := f.users[id]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// GetByEmail compares ignoring case, like the lower() index of the real query.
+func (f *fakeUsers) GetByEmail(ctx context.Context, email string) (*db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeUsers) GetByUsername(ctx context.Context, username string) (*db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if user.Username == username {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeUsers) Update(ctx context.Context, user *db.User, id int64) (*db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *user
+	f.users[id] = &copied
+	return user, nil
+}
+
+func (f *fakeUsers) UpdateAuthTime(ctx context.Context, id int64) (*db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return nil, nil
+	}
+	now := time.Now()
+	user.AuthTime = &now
+	copied := *user
+	return &copied, nil
+}
+
+// ChangeStatus only updates a user that is still in change.From, like the
+// conditional update of the real query.
+func (f *fakeUsers) ChangeStatus(ctx context.Context, change *db.UserStatusChange) (*db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[change.UserID]
+	if !ok || user.Status != change.From {
+		return nil, nil
+	}
+	user.Status = change.To
+	copied := *user
+	return &copied, nil
+}
+
+type fakeRoles struct {
+	db.RoleQuery
+	roles map[int64]*db.Role
+}
+
+func (f *fakeRoles) GetByID(ctx context.Context, id int64) (*db.Role, error) {
+	if role, ok := f.roles[id]; ok {
+		copied := *role
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+type fakeSessions struct {
+	db.SessionQuery
+	mu       sync.Mutex
+	sessions map[string]*db.Session
+	// beforeRotate runs inside Rotate, before the stored JTI is compared, to
+	// let a test interleave a concurrent request.
+	beforeRotate func()
+}
+
+func (f *fakeSessions) get(id string) *db.Session {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if session, ok := f.sessions[id]; ok {
+		copied := *session
+		return &copied
+	}
+	return nil
+}
+
+func (f *fakeSessions) GetByID(ctx context.Context, id string) (*db.Session, error) {
+	return f.get(id), nil
+}
+
+func (f *fakeSessions) Insert(ctx context.Context, session *db.Session) (*db.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	copied := *session
+	copied.CreatedAt, copied.LastUsedAt = &now, &now
+	f.sessions[session.ID] = &copied
+	returned := copied
+	return &returned, nil
+}
+
+func (f *fakeSessions) Rotate(ctx context.Context, id string, oldRefreshJTI string, accessJTI string, refreshJTI string, expiresAt time.Time) (*db.Session, error) {
+	if f.beforeRotate != nil {
+		f.beforeRotate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok || session.RefreshTokenJTI != oldRefreshJTI {
+		return nil, nil
+	}
+	now := time.Now()
+	session.AccessTokenJTI, session.RefreshTokenJTI = accessJTI, refreshJTI
+	session.LastUsedAt, session.ExpiresAt = &now, expiresAt
+	copied := *session
+	return &copied, nil
+}
+
+func (f *fakeSessions) Touch(ctx context.Context, id string, usedBefore time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if session, ok := f.sessions[id]; ok && (session.LastUsedAt == nil || session.LastUsedAt.Before(usedBefore)) {
+		now := time.Now()
+		session.LastUsedAt = &now
+	}
+	return nil
+}
+
+func (f *fakeSessions) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.sessions[id]; !ok {
+		return fmt.Errorf("no session found with id %s", id)
+	}
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeSessions) DeleteOthers(ctx context.Context, userID int64, keepID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deleted int64
+	for id, session := range f.sessions {
+		if session.UserID == userID && id != keepID {
+			delete(f.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type fakeLoginAttempts struct {
+	db.LoginAttemptQuery
+	mu       sync.Mutex
+	attempts map[loginScope]*db.LoginAttempt
+}
+
+func (f *fakeLoginAttempts) Get(ctx context.Context, scope, key string) (*db.LoginAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if attempt, ok := f.attempts[loginScope{name: scope, key: key}]; ok {
+		copied := *attempt
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeLoginAttempts) RegisterFailure(ctx context.Context, scope, key string, window time.Duration) (*db.LoginAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	attempt, ok := f.attempts[loginScope{name: scope, key: key}]
+	if !ok {
+		attempt = &db.LoginAttempt{Scope: scope, Key: key}
+		f.attempts[loginScope{name: scope, key: key}] = attempt
+	}
+	if attempt.LastFailureAt.Before(now.Add(-window)) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	copied := *attempt
+	return &copied, nil
+}
+
+func (f *fakeLoginAttempts) Lock(ctx context.Context, scope, key string, until time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if attempt, ok := f.attempts[loginScope{name: scope, key: key}]; ok {
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+func (f *fakeLoginAttempts) Delete(ctx context.Context, scope, key string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.attempts[loginScope{name: scope, key: key}]; !ok {
+		return 0, nil
+	}
+	delete(f.attempts, loginScope{name: scope, key: key})
+	return 1, nil
+}
+
+type fakePasswordResets struct {
+	db.PasswordResetQuery
+	deletedFor []int64
+}
+
+func (f *fakePasswordResets) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	f.deletedFor = append(f.deletedFor, userID)
+	return 0, nil
+}
+
+type fakeAudit struct {
+	db.AuditEventQuery
+	mu     sync.Mutex
+	events []*db.AuditEvent
+}
+
+func (f *fakeAudit) Append(ctx context.Context, event *db.AuditEvent) (*db.AuditEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	return event, nil
+}
+
+// find returns the recorded events of a type.
+func (f *fakeAudit) find(eventType string) []*db.AuditEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var found []*db.AuditEvent
+	for _, event := range f.events {
+		if event.Type == eventType {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+type fakeSigningKeys struct {
+	db.SigningKeyQuery
+	mu   sync.Mutex
+	keys []*db.SigningKey
+}
+
+func (f *fakeSigningKeys) ListPublished(ctx context.Context) ([]*db.SigningKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*db.SigningKey(nil), f.keys...), nil
+}
+
+func (f *fakeSigningKeys) Rotate(ctx context.Context, previousID string, previousState string, next *db.SigningKey) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	next.CreatedAt = &now
+	f.keys = append(f.keys, next)
+	return true, nil
+}
+
+// newTestService returns a service over fake tables with cheap password
+// hashing and a fresh signing key.
+func newTestService(t *testing.T) (*AuthService, *fakeDB) {
+	t.Helper()
+	cfg := config.AppConfig{
+		ACCESS_TOKEN_EXPIRES_IN:  15 * time.Minute,
+		REFRESH_TOKEN_EXPIRES_IN: 24 * time.Hour,
+		SESSION_TOUCH_INTERVAL:   time.Minute,
+		PasswordMinLength:        8,
+		PasswordMaxLength:        64,
+		PasswordHashAlgorithm:    config.HashAlgorithmBcrypt,
+		BcryptCost:               4,
+		EmailVerificationMode:    config.EmailVerificationOff,
+		JWTIssuer:                "auth-service",
+		JWT_LEEWAY:               30 * time.Second,
+		JWTSigningAlgorithm:      keys.AlgorithmEdDSA,
+		SigningKeyEncryptionKey:  base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))),
+		LoginMaxAccountFailures:  3,
+		LoginMaxIPFailures:       10,
+		LOGIN_FAILURE_WINDOW:     15 * time.Minute,
+		LOGIN_LOCKOUT_BASE:       time.Minute,
+		LOGIN_LOCKOUT_MAX:        time.Hour,
+	}
+	logger := zap.NewNop()
+	fake := newFakeDB()
+
+	keyManager, err := keys.NewManager(context.Background(), fake.SigningKeyQuery(), cfg, logger)
+	if err != nil {
+		t.Fatalf("keys.NewManager() error = %v", err)
+	}
+	hasher, err := passwords.NewHasher(cfg)
+	if err != nil {
+		t.Fatalf("passwords.NewHasher() error = %v", err)
+	}
+	policy, err := passwords.NewPolicy(cfg, logger)
+	if err != nil {
+		t.Fatalf("passwords.NewPolicy() error = %v", err)
+	}
+	return NewAuthService(fake, nil, keyManager, policy, hasher, logger, cfg), fake
+}
+
+// addTestUser stores an active user with the given password.
+func addTestUser(t *testing.T, s *AuthService, fake *fakeDB, id int64, password string) *db.User {
+	t.Helper()
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	user := &db.User{
+		ID:                 id,
+		Username:           fmt.Sprintf("user%d", id),
+		Password:           hash,
+		Email:              fmt.Sprintf("user%d@example.com", id),
+		RoleID:             testUserRoleID,
+		AccessTokenSecret:  uuid.NewString(),
+		RefreshTokenSecret: uuid.NewString(),
+		Status:             db.UserStatusActive,
+	}
+	fake.users.users[id] = user
+	return user
+}
+
+// openTestSession stores a new session of the user and returns its access and
+// refresh tokens.
+func openTestSession(t *testing.T, s *AuthService, fake *fakeDB, user *db.User) (*db.Session, string, string) {
+	t.Helper()
+	session, _ := fake.sessions.Insert(context.Background(), &db.Session{
+		ID:              uuid.NewString(),
+		UserID:          user.ID,
+		AccessTokenJTI:  uuid.NewString(),
+		RefreshTokenJTI: uuid.NewString(),
+		ExpiresAt:       time.Now().Add(s.config.REFRESH_TOKEN_EXPIRES_IN),
+	})
+	role, _ := fake.roles.GetByID(context.Background(), user.RoleID)
+	accessToken, refreshToken, err := s.issueTokens(context.Background(), user, role, session)
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	return session, accessToken, refreshToken
+}
+
+// withBearer returns an incoming context carrying the token.
+func withBearer(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
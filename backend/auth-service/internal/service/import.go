@@ -0,0 +1,92 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/userimport"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ImportUsers imports the users of another platform from an export sent in
+// the request. Large exports exceed the gRPC message limit and are imported
+// with the import-users command instead.
+func (s *AuthService) ImportUsers(ctx context.Context, req *pb.ImportUsersRequest) (*pb.ImportUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Importing users", zap.Int64("admin_id", caller.ID), zap.Bool("dry_run", req.DryRun))
+
+	format := userimport.FormatCSV
+	if req.Format == pb.ImportFormat_IMPORT_FORMAT_JSONL {
+		format = userimport.FormatJSONL
+	}
+	records, err := userimport.Read(bytes.NewReader(req.Data), format)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse import: %v", err)
+	}
+	role, err := s.roleByName(ctx, DefaultRoleName)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := userimport.NewImporter(s.db, s.logger).Import(ctx, records, userimport.Options{
+		RoleID: role.ID,
+		DryRun: req.DryRun,
+	})
+	if err != nil {
+		s.logger.Error("Failed to import users", zap.Int("imported", report.Imported), zap.Error(err))
+		if !req.DryRun {
+			s.recordAudit(ctx, auditRecord{eventType: AuditUsersImported, actorID: caller.ID, reason: importSummary(report), err: err})
+		}
+		return nil, status.Errorf(codes.Internal, "failed to import users after %d were imported", report.Imported)
+	}
+
+	if !req.DryRun {
+		s.recordAudit(ctx, auditRecord{eventType: AuditUsersImported, actorID: caller.ID, reason: importSummary(report)})
+	}
+	s.logger.Info("Users imported",
+		zap.Int64("admin_id", caller.ID),
+		zap.Bool("dry_run", req.DryRun),
+		zap.Int("imported", report.Imported))
+	return importReportToProto(report), nil
+}
+
+func importSummary(report *userimport.Report) string {
+	return fmt.Sprintf("imported %d of %d users", report.Imported, report.Total)
+}
+
+func importReportToProto(report *userimport.Report) *pb.ImportUsersResponse {
+	resp := &pb.ImportUsersResponse{
+		Total:     int32(report.Total),
+		Imported:  int32(report.Imported),
+		Conflicts: make([]*pb.ImportConflict, 0, len(report.Conflicts)),
+		Invalid:   make([]*pb.InvalidImportRecord, 0, len(report.Invalid)),
+	}
+	for _, c := range report.Conflicts {
+		resp.Conflicts = append(resp.Conflicts, &pb.ImportConflict{
+			Line:           int32(c.Line),
+			Username:       c.Username,
+			Email:          c.Email,
+			Field:          c.Field,
+			Reason:         c.Reason,
+			ExistingUserId: c.ExistingUserID,
+		})
+	}
+	for _, invalid := range report.Invalid {
+		resp.Invalid = append(resp.Invalid, &pb.InvalidImportRecord{
+			Line:   int32(invalid.Line),
+			Reason: invalid.Reason,
+		})
+	}
+	return resp
+}
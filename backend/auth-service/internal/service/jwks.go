@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/keys"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	return &pb.GetJWKSResponse{Keys: jwksToProto(s.JWKS())}, nil
+}
+
+func (s *AuthService) RotateSigningKey(ctx context.Context, req *pb.RotateSigningKeyRequest) (*pb.RotateSigningKeyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionKeysRotate)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Warn("Emergency signing key rotation requested",
+		zap.Int64("admin_id", caller.ID),
+		zap.Bool("revoke_previous", req.RevokePrevious))
+
+	key, err := s.keys.Rotate(ctx, req.RevokePrevious)
+	if err != nil {
+		s.logger.Error("Failed to rotate signing key", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to rotate signing key")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditSigningKeyRotated, actorID: caller.ID, reason: "kid " + key.ID})
+	s.logger.Info("Signing key rotated", zap.Int64("admin_id", caller.ID), zap.String("kid", key.ID))
+	return &pb.RotateSigningKeyResponse{
+		Kid:  key.ID,
+		Keys: jwksToProto(s.JWKS()),
+	}, nil
+}
+
+// JWKS returns the public keys access tokens can be verified with.
+func (s *AuthService) JWKS() keys.JWKS {
+	return s.keys.Current().JWKS()
+}
+
+func jwksToProto(set keys.JWKS) []*pb.JWK {
+	res := make([]*pb.JWK, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		res = append(res, &pb.JWK{
+			Kty: key.KeyType,
+			Kid: key.KeyID,
+			Use: key.Use,
+			Alg: key.Algorithm,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Curve,
+			X:   key.X,
+		})
+	}
+	return res
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ReasonAccountLocked is the ErrorInfo reason of the error Login returns while
+// an account or a client address is locked out. The error also carries a
+// RetryInfo with the remaining lockout time.
+const (
+	ReasonAccountLocked = "ACCOUNT_LOCKED"
+	errorDomain         = "auth-service"
+)
+
+func (s *AuthService) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Unlocking account",
+		zap.Int64("admin_id", caller.ID),
+		zap.Int64("user_id", req.UserId),
+		zap.String("ip", req.IpAddress))
+
+	if req.UserId == 0 && req.IpAddress == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id or ip address is required")
+	}
+
+	if req.UserId != 0 {
+		user, err := s.db.UserQuery().GetByID(ctx, req.UserId)
+		if err != nil {
+			s.logger.Error("Failed to fetch user", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to fetch user")
+		}
+		if user == nil {
+			s.logger.Warn("User not found", zap.Int64("user_id", req.UserId))
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		if _, err := s.db.LoginAttemptQuery().Delete(ctx, db.LoginAttemptScopeAccount, user.Username); err != nil {
+			s.logger.Error("Failed to unlock account", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to unlock account")
+		}
+	}
+	if req.IpAddress != "" {
+		if _, err := s.db.LoginAttemptQuery().Delete(ctx, db.LoginAttemptScopeIP, req.IpAddress); err != nil {
+			s.logger.Error("Failed to unlock address", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to unlock address")
+		}
+	}
+
+	s.recordAudit(ctx, auditRecord{
+		eventType: AuditAccountUnlocked,
+		actorID:   caller.ID,
+		subjectID: req.UserId,
+		reason:    req.IpAddress,
+	})
+	s.logger.Info("Account unlocked",
+		zap.Int64("admin_id", caller.ID),
+		zap.Int64("user_id", req.UserId),
+		zap.String("ip", req.IpAddress))
+	return &pb.UnlockAccountResponse{}, nil
+}
+
+// checkLoginLock refuses the login while either the account or the client
+// address is locked out.
+func (s *AuthService) checkLoginLock(ctx context.Context, username, ip string) error {
+	now := time.Now()
+	for _, scope := range loginScopes(username, ip) {
+		attempt, err := s.db.LoginAttemptQuery().Get(ctx, scope.name, scope.key)
+		if err != nil {
+			s.logger.Error("Failed to fetch login attempts", zap.Error(err))
+			return status.Error(codes.Internal, "failed to fetch login attempts")
+		}
+		if attempt != nil && attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			s.logger.Warn("Login locked",
+				zap.String("scope", scope.name),
+				zap.String("key", scope.key),
+				zap.Time("locked_until", *attempt.LockedUntil))
+			return s.accountLockedError(attempt.LockedUntil.Sub(now))
+		}
+	}
+	return nil
+}
+
+// loginFailed counts a failed login for the account and the client address,
+// locks them out once the configured threshold is reached and returns the
+// error to report to the caller.
+func (s *AuthService) loginFailed(ctx context.Context, username, ip string, cause error) error {
+	var lockout time.Duration
+	for _, scope := range loginScopes(username, ip) {
+		attempt, err := s.db.LoginAttemptQuery().RegisterFailure(ctx, scope.name, scope.key, s.config.LOGIN_FAILURE_WINDOW)
+		if err != nil {
+			s.logger.Error("Failed to register failed login", zap.Error(err))
+			continue
+		}
+
+		threshold := s.config.LoginMaxAccountFailures
+		if scope.name == db.LoginAttemptScopeIP {
+			threshold = s.config.LoginMaxIPFailures
+		}
+		if threshold <= 0 || attempt.Failures < threshold {
+			continue
+		}
+
+		duration := s.lockoutDuration(attempt.Failures - threshold)
+		if err := s.db.LoginAttemptQuery().Lock(ctx, scope.name, scope.key, time.Now().Add(duration)); err != nil {
+			s.logger.Error("Failed to lock login", zap.Error(err))
+			continue
+		}
+		s.logger.Warn("Login locked out",
+			zap.String("scope", scope.name),
+			zap.String("key", scope.key),
+			zap.Int("failures", attempt.Failures),
+			zap.Duration("duration", duration))
+		lockout = max(lockout, duration)
+	}
+
+	if lockout > 0 {
+		return s.accountLockedError(lockout)
+	}
+	return cause
+}
+
+// reauthenticate checks the password of an already authenticated user before
+// a sensitive change. Wrong passwords count towards the lockout like at
+// login, so a stolen access token does not allow guessing the password.
+func (s *AuthService) reauthenticate(ctx context.Context, user *db.User, password string) error {
+	ip, _ := s.clientInfo(ctx)
+	if err := s.checkLoginLock(ctx, user.Username, ip); err != nil {
+		return err
+	}
+	if ok, _ := s.checkPassword(user, password); !ok {
+		s.logger.Warn("Invalid password", zap.Int64("user_id", user.ID))
+		return s.loginFailed(ctx, user.Username, ip, status.Error(codes.Unauthenticated, "invalid password"))
+	}
+	return nil
+}
+
+// loginSucceeded clears the failure counter of the account. The counter of
+// the client address is left alone so that one known password cannot be used
+// to reset it.
+func (s *AuthService) loginSucceeded(ctx context.Context, username string) {
+	if _, err := s.db.LoginAttemptQuery().Delete(ctx, db.LoginAttemptScopeAccount, username); err != nil {
+		s.logger.Warn("Failed to reset login attempts", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// lockoutDuration doubles the base lockout for every failure past the
+// threshold, capped at the configured maximum.
+func (s *AuthService) lockoutDuration(excess int) time.Duration {
+	duration := s.config.LOGIN_LOCKOUT_BASE
+	for i := 0; i < excess && duration < s.config.LOGIN_LOCKOUT_MAX; i++ {
+		duration *= 2
+	}
+	return min(duration, s.config.LOGIN_LOCKOUT_MAX)
+}
+
+func (s *AuthService) accountLockedError(retryAfter time.Duration) error {
+	retryAfter = retryAfter.Round(time.Second)
+	st, err := status.New(codes.ResourceExhausted, "account locked").WithDetails(
+		&errdetails.ErrorInfo{
+			Reason: ReasonAccountLocked,
+			Domain: errorDomain,
+			Metadata: map[string]string{
+				"retry_after": strconv.FormatInt(int64(retryAfter/time.Second), 10),
+			},
+		},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		s.logger.Error("Failed to attach error details", zap.Error(err))
+		return status.Error(codes.ResourceExhausted, "account locked")
+	}
+	return st.Err()
+}
+
+type loginScope struct {
+	name string
+	key  string
+}
+
+func loginScopes(username, ip string) []loginScope {
+	scopes := []loginScope{{name: db.LoginAttemptScopeAccount, key: username}}
+	if ip != "" {
+		scopes = append(scopes, loginScope{name: db.LoginAttemptScopeIP, key: ip})
+	}
+	return scopes
+}
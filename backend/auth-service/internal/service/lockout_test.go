@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	s := &AuthService{config: config.AppConfig{LOGIN_LOCKOUT_BASE: time.Minute, LOGIN_LOCKOUT_MAX: time.Hour}}
+	tests := []struct {
+		excess int
+		want   time.Duration
+	}{
+		{excess: 0, want: time.Minute},
+		{excess: 1, want: 2 * time.Minute},
+		{excess: 5, want: 32 * time.Minute},
+		{excess: 6, want: time.Hour},
+		{excess: 1000, want: time.Hour},
+	}
+	for _, tt := range tests {
+		if got := s.lockoutDuration(tt.excess); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.excess, got, tt.want)
+		}
+	}
+}
+
+func TestLoginFailedLocksWithBackoff(t *testing.T) {
+	s, fake := newTestService(t)
+	ctx := context.Background()
+	cause := status.Error(codes.Unauthenticated, "invalid credentials")
+
+	for i := 1; i < s.config.LoginMaxAccountFailures; i++ {
+		if err := s.loginFailed(ctx, "joe", "192.0.2.1", cause); err != cause {
+			t.Fatalf("loginFailed() after %d failures = %v, want %v", i, err, cause)
+		}
+	}
+	if err := s.checkLoginLock(ctx, "joe", "192.0.2.1"); err != nil {
+		t.Fatalf("checkLoginLock() below the threshold = %v", err)
+	}
+
+	// Every failure past the threshold doubles the lockout.
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		if got := retryDelay(t, s.loginFailed(ctx, "joe", "192.0.2.1", cause)); got != want {
+			t.Errorf("loginFailed() retry delay = %v, want %v", got, want)
+		}
+	}
+	if got := retryDelay(t, s.checkLoginLock(ctx, "joe", "192.0.2.2")); got <= 0 || got > 4*time.Minute {
+		t.Errorf("checkLoginLock() of the locked account retry delay = %v, want at most %v", got, 4*time.Minute)
+	}
+	if err := s.checkLoginLock(ctx, "jane", "192.0.2.1"); err != nil {
+		t.Errorf("checkLoginLock() of another account from the same address = %v", err)
+	}
+
+	s.loginSucceeded(ctx, "joe")
+	if attempt, _ := fake.loginAttempts.Get(ctx, db.LoginAttemptScopeAccount, "joe"); attempt != nil {
+		t.Errorf("loginSucceeded() kept the account counter %+v", attempt)
+	}
+	if attempt, _ := fake.loginAttempts.Get(ctx, db.LoginAttemptScopeIP, "192.0.2.1"); attempt == nil || attempt.Failures != 5 {
+		t.Errorf("loginSucceeded() reset the address counter to %+v, want 5 failures", attempt)
+	}
+}
+
+func TestReauthenticateCountsTowardsLockout(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	secret := "sealed secret"
+	fake.users.users[user.ID].TOTPEnabled = true
+	fake.users.users[user.ID].TOTPSecret = &secret
+	_, accessToken, _ := openTestSession(t, s, fake, user)
+	ctx := withBearer(accessToken)
+
+	for i := 1; i < s.config.LoginMaxAccountFailures; i++ {
+		_, err := s.DisableTOTP(ctx, &pb.DisableTOTPRequest{Password: "wrong horse battery"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("DisableTOTP() with a wrong password error = %v, want %s", err, codes.Unauthenticated)
+		}
+	}
+	_, err := s.ChangePassword(ctx, &pb.ChangePasswordRequest{CurrentPassword: "wrong horse battery", NewPassword: "purple monkey dishwasher"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("ChangePassword() past the threshold error = %v, want %s", err, codes.ResourceExhausted)
+	}
+	// The right password is refused too while the account is locked.
+	_, err = s.ChangePassword(ctx, &pb.ChangePasswordRequest{CurrentPassword: "correct horse battery", NewPassword: "purple monkey dishwasher"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("ChangePassword() while locked error = %v, want %s", err, codes.ResourceExhausted)
+	}
+}
+
+// retryDelay returns the RetryInfo of an account locked error.
+func retryDelay(t *testing.T, err error) time.Duration {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("error = %v, want %s", err, codes.ResourceExhausted)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.RetryDelay.AsDuration()
+		}
+	}
+	t.Fatalf("error %v carries no RetryInfo", err)
+	return 0
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// clientInfo extracts the client address and user agent of the request.
+func (s *AuthService) clientInfo(ctx context.Context) (string, string) {
+	var peerAddr, userAgent string
+	var forwardedFor []string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		forwardedFor = md.Get("x-forwarded-for")
+		if values := md.Get("user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+	return clientIP(peerAddr, forwardedFor, s.config.TrustedProxies), userAgent
+}
+
+// clientIP returns the address of the client behind trusted proxies. The
+// x-forwarded-for header is only believed when the peer is a trusted proxy,
+// and then read from the right: every proxy appends the address it got the
+// request from, so the first address that is not a trusted proxy is the
+// client. Entries left of it were written by the client and are ignored.
+func clientIP(peerAddr string, forwardedFor []string, trusted []netip.Prefix) string {
+	ip := peerAddr
+	if host, _, err := net.SplitHostPort(peerAddr); err == nil {
+		ip = host
+	}
+	if !isTrustedProxy(ip, trusted) {
+		return ip
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// A malformed entry cannot be traced further; the last proxy
+			// that wrote a valid one is the best we know.
+			return ip
+		}
+		ip = addr.Unmap().String()
+		if !isTrustedProxy(ip, trusted) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token from the "authorization: Bearer <token>"
+// metadata of the request.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
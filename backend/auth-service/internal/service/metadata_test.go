@@ -0,0 +1,108 @@
+package service
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+	tests := []struct {
+		name         string
+		peer         string
+		forwardedFor []string
+		trusted      []netip.Prefix
+		want         string
+	}{
+		{
+			name: "no proxy",
+			peer: "203.0.113.7:51234",
+			want: "203.0.113.7",
+		},
+		{
+			name:         "header from untrusted peer is ignored",
+			peer:         "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "header ignored without trusted proxies",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "10.0.0.2",
+		},
+		{
+			name:         "single trusted proxy",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"203.0.113.7"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "spoofed entry left of the client",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"1.2.3.4, 203.0.113.7"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "spoofed entry posing as a trusted proxy",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"1.2.3.4, 10.9.9.9, 203.0.113.7"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "multiple trusted hops",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"1.2.3.4, 203.0.113.7, 10.0.5.1, 10.0.6.1"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "hops spread over several headers",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"1.2.3.4, 203.0.113.7", "10.0.5.1"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "ipv6 client behind ipv6 proxy",
+			peer:         "[fd00::2]:51234",
+			forwardedFor: []string{"2001:db8::1"},
+			trusted:      trusted,
+			want:         "2001:db8::1",
+		},
+		{
+			name:         "ipv4-mapped peer",
+			peer:         "[::ffff:10.0.0.2]:51234",
+			forwardedFor: []string{"203.0.113.7"},
+			trusted:      trusted,
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "malformed entry stops at the last proxy",
+			peer:         "10.0.0.2:51234",
+			forwardedFor: []string{"203.0.113.7, bogus"},
+			trusted:      trusted,
+			want:         "10.0.0.2",
+		},
+		{
+			name:    "trusted peer without header",
+			peer:    "10.0.0.2:51234",
+			trusted: trusted,
+			want:    "10.0.0.2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientIP(tt.peer, tt.forwardedFor, tt.trusted); got != tt.want {
+				t.Errorf("clientIP(%q, %q) = %q, want %q", tt.peer, tt.forwardedFor, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/totp"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	totpSkew           = 1
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryAlphabet   = "abcdefghjkmnpqrstuvwxyz23456789"
+	// maxMFAChallengeFailures is the number of wrong codes an MFA token
+	// allows before it is revoked and the user has to log in again.
+	maxMFAChallengeFailures = 5
+)
+
+func (s *AuthService) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, _, _, err := s.authenticateForMFA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Enrolling TOTP", zap.Int64("user_id", user.ID))
+
+	if user.TOTPEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if err := s.reauthenticate(ctx, user, req.Password); err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Error("Failed to generate TOTP secret", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to generate TOTP secret")
+	}
+	_, err = s.db.UserQuery().SetTOTP(ctx, user.ID, &secret, false)
+	if err != nil {
+		s.logger.Error("Failed to store TOTP secret", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to store TOTP secret")
+	}
+
+	s.logger.Info("TOTP enrollment started", zap.Int64("user_id", user.ID))
+	return &pb.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUri: totp.URI(s.config.TOTPIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the
+// authenticator app produces valid codes, and hands out recovery codes. When
+// called with an MFA challenge token it also completes the pending login.
+// Wrong codes count towards the lockout and, with a challenge token, revoke
+// the token like in VerifyMFA.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, session, challenge, err := s.authenticateForMFA(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Confirming TOTP", zap.Int64("user_id", user.ID))
+
+	if user.TOTPEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication enrollment not started")
+	}
+	ip, _ := s.clientInfo(ctx)
+	if err := s.checkLoginLock(ctx, user.Username, ip); err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(*user.TOTPSecret, req.Code, time.Now(), totpSkew)
+	if !ok {
+		s.logger.Warn("Invalid TOTP code on confirmation", zap.Int64("user_id", user.ID))
+		err := status.Error(codes.InvalidArgument, "invalid code")
+		if challenge != "" {
+			return nil, s.mfaFailed(ctx, user, challenge, ip, err)
+		}
+		return nil, s.loginFailed(ctx, user.Username, ip, err)
+	}
+
+	_, err = s.db.UserQuery().SetTOTP(ctx, user.ID, user.TOTPSecret, true)
+	if err != nil {
+		s.logger.Error("Failed to enable TOTP", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to enable TOTP")
+	}
+	if _, err := s.db.UserQuery().UseTOTPStep(ctx, user.ID, step); err != nil {
+		s.logger.Warn("Failed to record TOTP step", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}
+	if session == nil {
+		role, err := s.userRole(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.useMFAChallenge(ctx, user, challenge); err != nil {
+			return nil, err
+		}
+		s.loginSucceeded(ctx, user.Username)
+		resp.AccessToken, resp.RefreshToken, err = s.startSession(ctx, user, role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditTOTPEnabled, actorID: user.ID, subjectID: user.ID})
+	s.logger.Info("TOTP enabled", zap.Int64("user_id", user.ID))
+	return resp, nil
+}
+
+func (s *AuthService) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, _, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Disabling TOTP", zap.Int64("user_id", user.ID))
+
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	role, err := s.userRole(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if role.MFARequired {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is mandatory for your role")
+	}
+
+	if err := s.reauthenticate(ctx, user, req.Password); err != nil {
+		return nil, err
+	}
+	// The step is recorded like at login, so a code seen once cannot be
+	// replayed to switch the second factor off.
+	step, ok := totp.Validate(*user.TOTPSecret, req.Code, time.Now(), totpSkew)
+	if ok {
+		ok, err = s.db.UserQuery().UseTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			s.logger.Error("Failed to record TOTP step", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to verify code")
+		}
+	}
+	if !ok {
+		s.logger.Warn("Invalid TOTP code", zap.Int64("user_id", user.ID))
+		ip, _ := s.clientInfo(ctx)
+		return nil, s.loginFailed(ctx, user.Username, ip, status.Error(codes.Unauthenticated, "invalid code"))
+	}
+
+	_, err = s.db.UserQuery().SetTOTP(ctx, user.ID, nil, false)
+	if err != nil {
+		s.logger.Error("Failed to disable TOTP", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to disable TOTP")
+	}
+	_, err = s.db.RecoveryCodeQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Warn("Failed to delete recovery codes", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditTOTPDisabled, actorID: user.ID, subjectID: user.ID})
+	s.logger.Info("TOTP disabled", zap.Int64("user_id", user.ID))
+	return &pb.DisableTOTPResponse{}, nil
+}
+
+// VerifyMFA completes a login that Login answered with an MFA challenge.
+// Wrong codes count towards the lockout of the account and the client
+// address like wrong passwords, and revoke the MFA token after
+// maxMFAChallengeFailures attempts. The token completes one login at most.
+func (s *AuthService) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.VerifyMFAResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, challenge, err := s.verifyMFAToken(ctx, req.MfaToken)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Verifying second factor", zap.Int64("user_id", user.ID))
+
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	ip, _ := s.clientInfo(ctx)
+	if err := s.checkLoginLock(ctx, user.Username, ip); err != nil {
+		s.recordAudit(ctx, auditRecord{eventType: AuditMFAVerified, subjectID: user.ID, err: err})
+		return nil, err
+	}
+
+	switch {
+	case req.Code != "":
+		step, ok := totp.Validate(*user.TOTPSecret, req.Code, time.Now(), totpSkew)
+		if ok {
+			ok, err = s.db.UserQuery().UseTOTPStep(ctx, user.ID, step)
+			if err != nil {
+				s.logger.Error("Failed to record TOTP step", zap.Error(err))
+				return nil, status.Error(codes.Internal, "failed to verify code")
+			}
+		}
+		if !ok {
+			s.logger.Warn("Invalid TOTP code", zap.Int64("user_id", user.ID))
+			err := status.Error(codes.Unauthenticated, "invalid code")
+			s.recordAudit(ctx, auditRecord{eventType: AuditMFAVerified, subjectID: user.ID, err: err})
+			return nil, s.mfaFailed(ctx, user, challenge, ip, err)
+		}
+	case req.RecoveryCode != "":
+		used, err := s.db.RecoveryCodeQuery().Use(ctx, user.ID, hashToken(normalizeRecoveryCode(req.RecoveryCode)))
+		if err != nil {
+			s.logger.Error("Failed to use recovery code", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to verify recovery code")
+		}
+		if !used {
+			s.logger.Warn("Invalid recovery code", zap.Int64("user_id", user.ID))
+			err := status.Error(codes.Unauthenticated, "invalid recovery code")
+			s.recordAudit(ctx, auditRecord{eventType: AuditMFAVerified, subjectID: user.ID, err: err})
+			return nil, s.mfaFailed(ctx, user, challenge, ip, err)
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "code or recovery code is required")
+	}
+
+	role, err := s.userRole(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.useMFAChallenge(ctx, user, challenge); err != nil {
+		return nil, err
+	}
+	s.loginSucceeded(ctx, user.Username)
+	accessToken, refreshToken, err := s.startSession(ctx, user, role)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditMFAVerified, subjectID: user.ID})
+	s.logger.Info("Second factor verified", zap.Int64("user_id", user.ID))
+	return &pb.VerifyMFAResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// mfaChallenge answers a login with correct password but pending second
+// factor: instead of a session the client gets a short-lived MFA token.
+func (s *AuthService) mfaChallenge(ctx context.Context, user *db.User, enrollmentRequired bool) (*pb.LoginResponse, error) {
+	now := time.Now()
+	claims := s.newClaims(user, MFATokenType, s.config.MFA_TOKEN_EXPIRES_IN, uuid.New().String(), now)
+	mfaToken, err := s.signToken(user, claims)
+	if err != nil {
+		s.logger.Error("Failed to generate MFA token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to generate MFA token")
+	}
+	err = s.db.MFAChallengeQuery().Insert(ctx, claims.ID, user.ID, now.Add(s.config.MFA_TOKEN_EXPIRES_IN))
+	if err != nil {
+		s.logger.Error("Failed to store MFA challenge", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to generate MFA token")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditLogin, subjectID: user.ID, reason: "second factor required"})
+	s.logger.Info("MFA challenge issued",
+		zap.Int64("user_id", user.ID),
+		zap.Bool("enrollment_required", enrollmentRequired))
+	return &pb.LoginResponse{
+		MfaRequired:           true,
+		MfaToken:              mfaToken,
+		MfaEnrollmentRequired: enrollmentRequired,
+	}, nil
+}
+
+// verifyMFAToken returns the user of an MFA token and the ID of its
+// challenge, which must still be open.
+func (s *AuthService) verifyMFAToken(ctx context.Context, token string) (*db.User, string, error) {
+	user, claims, err := s.parseToken(ctx, token, MFATokenType, s.ownExpectation())
+	if err != nil {
+		s.logger.Warn("Failed to parse MFA token", zap.Error(err))
+		return nil, "", status.Error(codes.Unauthenticated, "invalid MFA token")
+	}
+	open, err := s.db.MFAChallengeQuery().Exists(ctx, claims.ID, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to fetch MFA challenge", zap.Error(err))
+		return nil, "", status.Error(codes.Internal, "failed to verify MFA token")
+	}
+	if !open {
+		s.logger.Warn("MFA token used or revoked", zap.Int64("user_id", user.ID))
+		return nil, "", status.Error(codes.Unauthenticated, "invalid MFA token")
+	}
+	if err := s.accountStatusError(user); err != nil {
+		return nil, "", err
+	}
+	return user, claims.ID, nil
+}
+
+// mfaFailed counts a wrong second factor against the lockout and the MFA
+// challenge, revoking the challenge once it had too many, and returns the
+// error to report to the caller.
+func (s *AuthService) mfaFailed(ctx context.Context, user *db.User, challenge, ip string, cause error) error {
+	failures, err := s.db.MFAChallengeQuery().RegisterFailure(ctx, challenge)
+	if err != nil {
+		s.logger.Error("Failed to register failed MFA attempt", zap.Error(err))
+	} else if failures >= maxMFAChallengeFailures {
+		if _, err := s.db.MFAChallengeQuery().Use(ctx, challenge, user.ID); err != nil {
+			s.logger.Error("Failed to revoke MFA challenge", zap.Error(err))
+		}
+		s.logger.Warn("MFA token revoked after too many failures", zap.Int64("user_id", user.ID))
+	}
+	return s.loginFailed(ctx, user.Username, ip, cause)
+}
+
+// useMFAChallenge closes the challenge of an MFA token that passed, so the
+// token cannot complete another login.
+func (s *AuthService) useMFAChallenge(ctx context.Context, user *db.User, challenge string) error {
+	used, err := s.db.MFAChallengeQuery().Use(ctx, challenge, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to use MFA challenge", zap.Error(err))
+		return status.Error(codes.Internal, "failed to verify MFA token")
+	}
+	if !used {
+		s.logger.Warn("MFA token used concurrently", zap.Int64("user_id", user.ID))
+		return status.Error(codes.Unauthenticated, "invalid MFA token")
+	}
+	return nil
+}
+
+// authenticateForMFA accepts either a regular access token or, for users who
+// must enroll before their first login completes, an MFA challenge token. In
+// the latter case the returned session is nil and the challenge is the ID of
+// the MFA challenge; otherwise the challenge is empty.
+func (s *AuthService) authenticateForMFA(ctx context.Context) (*db.User, *db.Session, string, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, nil, "", status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if tokenType, err := peekTokenType(token); err == nil && tokenType == MFATokenType {
+		user, challenge, err := s.verifyMFAToken(ctx, token)
+		return user, nil, challenge, err
+	}
+	user, session, err := s.verifyToken(ctx, token, AccessTokenType)
+	return user, session, "", err
+}
+
+// replaceRecoveryCodes generates a fresh set of recovery codes, stores their
+// hashes and returns the plain codes to be shown to the user once.
+func (s *AuthService) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	recoveryCodes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			s.logger.Error("Failed to generate recovery code", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to generate recovery codes")
+		}
+		recoveryCodes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.db.RecoveryCodeQuery().Replace(ctx, userID, hashes); err != nil {
+		s.logger.Error("Failed to store recovery codes", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to store recovery codes")
+	}
+	return recoveryCodes, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(recoveryAlphabet)))
+	for i := 0; i < recoveryCodeLength; i++ {
+		if i == recoveryCodeLength/2 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatalf("generateRecoveryCode() error = %v", err)
+		}
+		first, second, ok := strings.Cut(code, "-")
+		if !ok || len(first) != recoveryCodeLength/2 || len(second) != recoveryCodeLength-recoveryCodeLength/2 {
+			t.Fatalf("generateRecoveryCode() = %q, want two halves of %d characters", code, recoveryCodeLength/2)
+		}
+		for _, r := range first + second {
+			if !strings.ContainsRune(recoveryAlphabet, r) {
+				t.Fatalf("generateRecoveryCode() = %q contains %q", code, r)
+			}
+		}
+		if seen[code] {
+			t.Fatalf("generateRecoveryCode() returned %q twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "abcde-fghjk", want: "abcdefghjk"},
+		{code: "ABCDE-FGHJK", want: "abcdefghjk"},
+		{code: "abcde fghjk", want: "abcdefghjk"},
+		{code: " abcdefghjk ", want: "abcdefghjk"},
+		{code: "ab-cd-ef-gh-jk", want: "abcdefghjk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := normalizeRecoveryCode(tt.code); got != tt.want {
+				t.Errorf("normalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	// The stored hash must match whatever way the user types the code.
+	if hashToken(normalizeRecoveryCode("ABCDE FGHJK")) != hashToken(normalizeRecoveryCode("abcde-fghjk")) {
+		t.Errorf("differently typed recovery codes hash differently")
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ChangePassword replaces the caller's password and revokes every other
+// session, keeping the one the request was made from.
+func (s *AuthService) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, session, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Changing password", zap.Int64("user_id", user.ID))
+
+	if err := s.reauthenticate(ctx, user, req.CurrentPassword); err != nil {
+		s.recordAudit(ctx, auditRecord{eventType: AuditPasswordChanged, actorID: user.ID, subjectID: user.ID, err: err})
+		return nil, err
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, status.Error(codes.InvalidArgument, "new password must differ from the current one")
+	}
+	if err := s.checkPasswordPolicy("new_password", req.NewPassword, user.Username, user.Email); err != nil {
+		return nil, err
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.SessionQuery().DeleteOthers(ctx, user.ID, session.ID)
+	if err != nil {
+		s.logger.Error("Failed to revoke other sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke other sessions")
+	}
+	_, err = s.db.PasswordResetQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Warn("Failed to delete outstanding reset tokens", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditPasswordChanged, actorID: user.ID, subjectID: user.ID})
+	s.logger.Info("Password changed successfully", zap.Int64("user_id", user.ID))
+	return &pb.ChangePasswordResponse{}, nil
+}
+
+// setPassword hashes and stores a new password for the user.
+func (s *AuthService) setPassword(ctx context.Context, user *db.User, password string) error {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return status.Error(codes.Internal, "failed to hash password")
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.UpdatedAt = &now
+	_, err = s.db.UserQuery().Update(ctx, user, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to update password", zap.Int64("user_id", user.ID), zap.Error(err))
+		return status.Error(codes.Internal, "failed to update password")
+	}
+	return nil
+}
+
+// checkPasswordPolicy rejects a password that breaks the policy. Every
+// violation is reported as a field violation of the given request field, so
+// the frontend can show them all at once.
+func (s *AuthService) checkPasswordPolicy(field, password string, userInputs ...string) error {
+	violations := s.passwords.Check(password, userInputs...)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: violation.Message,
+			Reason:      violation.Code,
+		})
+	}
+	st, err := status.New(codes.InvalidArgument, "password does not meet the policy").WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "password does not meet the policy")
+	}
+	return st.Err()
+}
+
+// checkPassword reports whether the password matches the user's stored hash,
+// and whether the hash is due to be replaced. A hash that cannot be read is
+// logged and treated as a mismatch.
+func (s *AuthService) checkPassword(user *db.User, password string) (ok, rehash bool) {
+	ok, rehash, err := s.hasher.Verify(user.Password, password)
+	if err != nil {
+		s.logger.Error("Failed to verify password hash", zap.Int64("user_id", user.ID), zap.Error(err))
+		return false, false
+	}
+	return ok, rehash
+}
+
+// rehashPassword replaces a hash made by an older algorithm or with weaker
+// parameters, while the plain password is at hand after a login. A failure
+// only postpones the upgrade to the next login.
+func (s *AuthService) rehashPassword(ctx context.Context, user *db.User, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		s.logger.Warn("Failed to rehash password", zap.Int64("user_id", user.ID), zap.Error(err))
+		return
+	}
+	user.Password = hashedPassword
+	if _, err := s.db.UserQuery().Update(ctx, user, user.ID); err != nil {
+		s.logger.Warn("Failed to store rehashed password", zap.Int64("user_id", user.ID), zap.Error(err))
+		return
+	}
+	s.logger.Info("Password hash upgraded", zap.Int64("user_id", user.ID))
+}
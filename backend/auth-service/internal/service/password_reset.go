@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/mail"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestPasswordReset always succeeds for well-formed requests so that it
+// cannot be used to find out which emails are registered. While the user has
+// a reset token younger than PASSWORD_RESET_RESEND_INTERVAL no further mail
+// is sent, so the RPC cannot be used to flood a mailbox.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	s.logger.Debug("Requesting password reset", zap.String("email", req.Email))
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	user, err := s.db.UserQuery().GetByEmail(ctx, req.Email)
+	if err != nil {
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
+	}
+	if user == nil {
+		s.logger.Warn("Password reset requested for unknown email", zap.String("email", req.Email))
+		return &pb.RequestPasswordResetResponse{}, nil
+	}
+
+	latest, err := s.db.PasswordResetQuery().GetLatestActiveByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to fetch latest reset token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch latest reset token")
+	}
+	if latest != nil && latest.CreatedAt != nil && time.Since(*latest.CreatedAt) < s.config.PASSWORD_RESET_RESEND_INTERVAL {
+		s.logger.Warn("Password reset throttled", zap.Int64("user_id", user.ID))
+		return &pb.RequestPasswordResetResponse{}, nil
+	}
+
+	token, err := db.GenerateSecretKey()
+	if err != nil {
+		s.logger.Error("Failed to generate reset token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to generate reset token")
+	}
+	_, err = s.db.PasswordResetQuery().Insert(ctx, &db.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.config.PASSWORD_RESET_EXPIRES_IN),
+	})
+	if err != nil {
+		s.logger.Error("Failed to store reset token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to store reset token")
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body:    s.passwordResetBody(user.Username, token),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send password reset email", zap.Int64("user_id", user.ID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to send password reset email")
+	}
+
+	s.logger.Info("Password reset requested", zap.Int64("user_id", user.ID))
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+func (s *AuthService) ConfirmPasswordReset(ctx context.Context, req *pb.ConfirmPasswordResetRequest) (*pb.ConfirmPasswordResetResponse, error) {
+	s.logger.Debug("Confirming password reset")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	reset, err := s.db.PasswordResetQuery().GetByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		s.logger.Error("Failed to fetch reset token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch reset token")
+	}
+	if reset == nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		s.logger.Warn("Invalid or expired reset token")
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired reset token")
+	}
+
+	user, err := s.db.UserQuery().GetByID(ctx, reset.UserID)
+	if err != nil {
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
+	}
+	if user == nil {
+		s.logger.Warn("User not found", zap.Int64("user_id", reset.UserID))
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	// The policy needs the user's name and email, and the token is only
+	// consumed once the new password is acceptable.
+	if err := s.checkPasswordPolicy("new_password", req.NewPassword, user.Username, user.Email); err != nil {
+		return nil, err
+	}
+
+	used, err := s.db.PasswordResetQuery().MarkUsed(ctx, reset.ID)
+	if err != nil {
+		s.logger.Error("Failed to consume reset token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to consume reset token")
+	}
+	if !used {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired reset token")
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.SessionQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to revoke sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
+	}
+	_, err = s.db.PasswordResetQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Warn("Failed to delete outstanding reset tokens", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditPasswordReset, subjectID: user.ID})
+	s.logger.Info("Password reset successfully", zap.Int64("user_id", user.ID))
+	return &pb.ConfirmPasswordResetResponse{}, nil
+}
+
+func (s *AuthService) passwordResetBody(username string, token string) string {
+	body := fmt.Sprintf("Hello, %s!\n\nSomeone requested a password reset for your account.\n", username)
+	if s.config.PasswordResetURL != "" {
+		body += fmt.Sprintf("Open the link below to choose a new password:\n\n%s?%s\n",
+			s.config.PasswordResetURL, url.Values{"token": {token}}.Encode())
+	} else {
+		body += fmt.Sprintf("Use this code to choose a new password:\n\n%s\n", token)
+	}
+	body += fmt.Sprintf("\nThe code expires in %s. If it was not you, ignore this message.\n", s.config.PASSWORD_RESET_EXPIRES_IN)
+	return body
+}
+
+// hashToken returns the SHA-256 hex digest under which one-time tokens are
+// stored, so that a database dump does not reveal usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
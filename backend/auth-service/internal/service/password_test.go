@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	other := addTestUser(t, s, fake, 2, "another long secret")
+	current, accessToken, _ := openTestSession(t, s, fake, user)
+	stolen, _, _ := openTestSession(t, s, fake, user)
+	unrelated, _, _ := openTestSession(t, s, fake, other)
+
+	_, err := s.ChangePassword(withBearer(accessToken), &pb.ChangePasswordRequest{
+		CurrentPassword: "correct horse battery",
+		NewPassword:     "purple monkey dishwasher",
+	})
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+
+	if fake.sessions.get(current.ID) == nil {
+		t.Errorf("ChangePassword() revoked the session it was called from")
+	}
+	if fake.sessions.get(stolen.ID) != nil {
+		t.Errorf("ChangePassword() kept another session of the user")
+	}
+	if fake.sessions.get(unrelated.ID) == nil {
+		t.Errorf("ChangePassword() revoked a session of another user")
+	}
+	if got := fake.passwordResets.deletedFor; len(got) != 1 || got[0] != user.ID {
+		t.Errorf("reset tokens deleted for %v, want [%d]", got, user.ID)
+	}
+	if ok, _ := s.checkPassword(fake.users.users[user.ID], "purple monkey dishwasher"); !ok {
+		t.Errorf("ChangePassword() did not store the new password")
+	}
+	if events := fake.audit.find(AuditPasswordChanged); len(events) != 1 || events[0].Outcome != db.AuditOutcomeSuccess {
+		t.Errorf("audit events = %+v, want one success", events)
+	}
+}
+
+func TestChangePasswordWithWrongPasswordKeepsSessions(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	_, accessToken, _ := openTestSession(t, s, fake, user)
+	other, _, _ := openTestSession(t, s, fake, user)
+
+	_, err := s.ChangePassword(withBearer(accessToken), &pb.ChangePasswordRequest{
+		CurrentPassword: "wrong horse battery",
+		NewPassword:     "purple monkey dishwasher",
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("ChangePassword() error = %v, want %s", err, codes.Unauthenticated)
+	}
+	if fake.sessions.get(other.ID) == nil {
+		t.Errorf("ChangePassword() with a wrong password revoked a session")
+	}
+	if events := fake.audit.find(AuditPasswordChanged); len(events) != 1 || events[0].Outcome != db.AuditOutcomeFailure {
+		t.Errorf("audit events = %+v, want one failure", events)
+	}
+}
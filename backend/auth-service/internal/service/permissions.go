@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Permissions the service checks itself. The admin role holds all of them
+// initially; they can be granted to any other role.
+const (
+	// PermissionUsersAdmin guards the management of user accounts.
+	PermissionUsersAdmin = "users:admin"
+	// PermissionRolesAdmin guards the management of roles and permissions and
+	// the assignment of roles to users.
+	PermissionRolesAdmin = "roles:admin"
+	// PermissionAuditRead allows reading and verifying the audit log.
+	PermissionAuditRead = "audit:read"
+	// PermissionKeysRotate allows emergency rotation of the signing key.
+	PermissionKeysRotate = "keys:rotate"
+)
+
+// servicePermissions are the permissions that cannot be deleted.
+var servicePermissions = []string{PermissionUsersAdmin, PermissionRolesAdmin, PermissionAuditRead, PermissionKeysRotate}
+
+var permissionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*:[a-z][a-z0-9_]*$`)
+
+func (s *AuthService) CreatePermission(ctx context.Context, req *pb.CreatePermissionRequest) (*pb.CreatePermissionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Creating permission", zap.Int64("admin_id", caller.ID), zap.String("permission", req.Name))
+
+	if !permissionNamePattern.MatchString(req.Name) {
+		return nil, status.Error(codes.InvalidArgument, "permission name must look like resource:action")
+	}
+	existing, err := s.db.PermissionQuery().GetByName(ctx, req.Name)
+	if err != nil {
+		s.logger.Error("Failed to fetch permission", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch permission")
+	}
+	if existing != nil {
+		return nil, status.Error(codes.AlreadyExists, "permission already exists")
+	}
+
+	permission, err := s.db.PermissionQuery().Insert(ctx, &db.Permission{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create permission", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to create permission")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditPermissionCreated, actorID: caller.ID, reason: permission.Name})
+	s.logger.Info("Permission created", zap.Int64("admin_id", caller.ID), zap.String("permission", permission.Name))
+	return &pb.CreatePermissionResponse{Permission: permissionToProto(permission)}, nil
+}
+
+func (s *AuthService) DeletePermission(ctx context.Context, req *pb.DeletePermissionRequest) (*pb.DeletePermissionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Deleting permission", zap.Int64("admin_id", caller.ID), zap.String("permission", req.Name))
+
+	if slices.Contains(servicePermissions, req.Name) {
+		return nil, status.Error(codes.FailedPrecondition, "permission is required by the service")
+	}
+	permission, err := s.permissionByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.PermissionQuery().Delete(ctx, permission.ID); err != nil {
+		s.logger.Error("Failed to delete permission", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to delete permission")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditPermissionDeleted, actorID: caller.ID, reason: permission.Name})
+	s.logger.Info("Permission deleted", zap.Int64("admin_id", caller.ID), zap.String("permission", permission.Name))
+	return &pb.DeletePermissionResponse{}, nil
+}
+
+func (s *AuthService) ListPermissions(ctx context.Context, req *pb.ListPermissionsRequest) (*pb.ListPermissionsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := s.authenticatePermission(ctx, PermissionRolesAdmin); err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Listing permissions", zap.String("role", req.Role))
+
+	var permissions []*db.Permission
+	var err error
+	if req.Role != "" {
+		role, roleErr := s.roleByName(ctx, req.Role)
+		if roleErr != nil {
+			return nil, roleErr
+		}
+		permissions, err = s.db.PermissionQuery().ListByRoleID(ctx, role.ID)
+	} else {
+		permissions, err = s.db.PermissionQuery().List(ctx)
+	}
+	if err != nil {
+		s.logger.Error("Failed to list permissions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list permissions")
+	}
+
+	resp := &pb.ListPermissionsResponse{Permissions: make([]*pb.Permission, 0, len(permissions))}
+	for _, permission := range permissions {
+		resp.Permissions = append(resp.Permissions, permissionToProto(permission))
+	}
+	return resp, nil
+}
+
+// GrantPermission gives a permission to a role. Tokens that embed
+// permissions pick the change up on their next refresh.
+func (s *AuthService) GrantPermission(ctx context.Context, req *pb.GrantPermissionRequest) (*pb.GrantPermissionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Granting permission",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", req.Role),
+		zap.String("permission", req.Permission))
+
+	role, err := s.roleByName(ctx, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	permission, err := s.permissionByName(ctx, req.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.PermissionQuery().Grant(ctx, role.ID, permission.ID); err != nil {
+		s.logger.Error("Failed to grant permission", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to grant permission")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditPermissionGranted, actorID: caller.ID, reason: role.Name + " " + permission.Name})
+	s.logger.Info("Permission granted",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", role.Name),
+		zap.String("permission", permission.Name))
+	return &pb.GrantPermissionResponse{}, nil
+}
+
+func (s *AuthService) RevokePermission(ctx context.Context, req *pb.RevokePermissionRequest) (*pb.RevokePermissionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Revoking permission",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", req.Role),
+		zap.String("permission", req.Permission))
+
+	role, err := s.roleByName(ctx, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	permission, err := s.permissionByName(ctx, req.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if role.ID == caller.RoleID && permission.Name == PermissionRolesAdmin {
+		return nil, status.Error(codes.FailedPrecondition, "cannot revoke roles:admin from your own role")
+	}
+	if _, err := s.db.PermissionQuery().Revoke(ctx, role.ID, permission.ID); err != nil {
+		s.logger.Error("Failed to revoke permission", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke permission")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditPermissionRevoked, actorID: caller.ID, reason: role.Name + " " + permission.Name})
+	s.logger.Info("Permission revoked",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", role.Name),
+		zap.String("permission", permission.Name))
+	return &pb.RevokePermissionResponse{}, nil
+}
+
+// CheckPermission tells whether the owner of an access token currently holds
+// the permission. The answer comes from the database, not from the token.
+func (s *AuthService) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, _, err := s.verifyToken(ctx, req.Token, AccessTokenType)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.hasPermission(ctx, user, req.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Permission checked",
+		zap.Int64("user_id", user.ID),
+		zap.String("permission", req.Permission),
+		zap.Bool("allowed", allowed))
+	return &pb.CheckPermissionResponse{Allowed: allowed}, nil
+}
+
+// authenticatePermission resolves the caller and requires the permission.
+func (s *AuthService) authenticatePermission(ctx context.Context, permission string) (*db.User, error) {
+	caller, _, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePermission(ctx, caller, permission); err != nil {
+		return nil, err
+	}
+	return caller, nil
+}
+
+// requirePermission fails with PermissionDenied unless the user's role
+// currently holds the permission.
+func (s *AuthService) requirePermission(ctx context.Context, user *db.User, permission string) error {
+	allowed, err := s.hasPermission(ctx, user, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		s.logger.Warn("Permission denied",
+			zap.Int64("user_id", user.ID),
+			zap.String("required_permission", permission))
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return nil
+}
+
+func (s *AuthService) hasPermission(ctx context.Context, user *db.User, permission string) (bool, error) {
+	permissions, err := s.rolePermissions(ctx, user.RoleID)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range permissions {
+		if name == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *AuthService) rolePermissions(ctx context.Context, roleID int64) ([]string, error) {
+	permissions, err := s.db.PermissionQuery().ListByRoleID(ctx, roleID)
+	if err != nil {
+		s.logger.Error("Failed to list role permissions", zap.Error(err), zap.Int64("role_id", roleID))
+		return nil, status.Error(codes.Internal, "failed to list permissions")
+	}
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return names, nil
+}
+
+func (s *AuthService) permissionByName(ctx context.Context, name string) (*db.Permission, error) {
+	permission, err := s.db.PermissionQuery().GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to fetch permission", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch permission")
+	}
+	if permission == nil {
+		return nil, status.Error(codes.NotFound, "permission not found")
+	}
+	return permission, nil
+}
+
+func (s *AuthService) roleByName(ctx context.Context, name string) (*db.Role, error) {
+	role, err := s.db.RoleQuery().GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to fetch role", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch role")
+	}
+	if role == nil {
+		return nil, status.Error(codes.NotFound, "role not found")
+	}
+	return role, nil
+}
+
+func permissionToProto(permission *db.Permission) *pb.Permission {
+	return &pb.Permission{
+		Name:        permission.Name,
+		Description: permission.Description,
+	}
+}
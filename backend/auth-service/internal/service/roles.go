@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func (s *AuthService) CreateRole(ctx context.Context, req *pb.CreateRoleRequest) (*pb.CreateRoleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	role, err := roleFromProto(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Creating role", zap.Int64("admin_id", caller.ID), zap.String("role", role.Name))
+
+	role, err = s.db.RoleQuery().Insert(ctx, role)
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "role with this name or code already exists")
+		}
+		s.logger.Error("Failed to create role", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to create role")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditRoleCreated, actorID: caller.ID, reason: role.Name})
+	s.logger.Info("Role created", zap.Int64("admin_id", caller.ID), zap.String("role", role.Name))
+	return &pb.CreateRoleResponse{Role: roleToProto(role)}, nil
+}
+
+// UpdateRole replaces the attributes of a role. Renaming it or starting to
+// require MFA ends the sessions of its users, whose tokens carry the old
+// state.
+func (s *AuthService) UpdateRole(ctx context.Context, req *pb.UpdateRoleRequest) (*pb.UpdateRoleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Updating role", zap.Int64("admin_id", caller.ID), zap.String("role", req.Name))
+
+	current, err := s.roleByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	role, err := roleFromProto(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name != current.Name && isBuiltinRole(current.Name) {
+		return nil, status.Error(codes.FailedPrecondition, "built-in role cannot be renamed")
+	}
+
+	role, err = s.db.RoleQuery().Update(ctx, role, current.ID)
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "role with this name or code already exists")
+		}
+		s.logger.Error("Failed to update role", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to update role")
+	}
+
+	var revoked int64
+	if role.Name != current.Name || (role.MFARequired && !current.MFARequired) {
+		revoked, err = s.db.SessionQuery().DeleteByRoleID(ctx, role.ID)
+		if err != nil {
+			s.logger.Error("Failed to revoke sessions of role", zap.Error(err), zap.Int64("role_id", role.ID))
+			return nil, status.Error(codes.Internal, "failed to revoke sessions")
+		}
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditRoleUpdated, actorID: caller.ID, reason: current.Name + " -> " + role.Name})
+	s.logger.Info("Role updated",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", role.Name),
+		zap.Int64("revoked_sessions", revoked))
+	return &pb.UpdateRoleResponse{Role: roleToProto(role), RevokedSessions: revoked}, nil
+}
+
+// DeleteRole deletes a role after moving its users to req.ReassignTo, the
+// default role when empty. The moved users are logged out.
+func (s *AuthService) DeleteRole(ctx context.Context, req *pb.DeleteRoleRequest) (*pb.DeleteRoleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Deleting role",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", req.Name),
+		zap.String("reassign_to", req.ReassignTo))
+
+	role, err := s.roleByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if isBuiltinRole(role.Name) {
+		return nil, status.Error(codes.FailedPrecondition, "built-in role cannot be deleted")
+	}
+	reassignTo := req.ReassignTo
+	if reassignTo == "" {
+		reassignTo = DefaultRoleName
+	}
+	replacement, err := s.roleByName(ctx, reassignTo)
+	if err != nil {
+		return nil, err
+	}
+	if replacement.ID == role.ID {
+		return nil, status.Error(codes.InvalidArgument, "cannot reassign users to the deleted role")
+	}
+
+	reassigned, err := s.db.RoleQuery().DeleteAndReassign(ctx, role.ID, replacement.ID)
+	if err != nil {
+		s.logger.Error("Failed to delete role", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to delete role")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditRoleDeleted, actorID: caller.ID, reason: role.Name + " -> " + replacement.Name})
+	s.logger.Info("Role deleted",
+		zap.Int64("admin_id", caller.ID),
+		zap.String("role", role.Name),
+		zap.String("reassigned_to", replacement.Name),
+		zap.Int64("reassigned_users", reassigned))
+	return &pb.DeleteRoleResponse{ReassignedUsers: reassigned}, nil
+}
+
+func (s *AuthService) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := s.authenticatePermission(ctx, PermissionRolesAdmin); err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Listing roles")
+
+	roles, err := s.db.RoleQuery().List(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list roles", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list roles")
+	}
+
+	resp := &pb.ListRolesResponse{Roles: make([]*pb.Role, 0, len(roles))}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, roleToProto(role))
+	}
+	return resp, nil
+}
+
+// AssignRole moves a user to another role and ends their sessions, so that
+// tokens with the old role claim stop being accepted.
+func (s *AuthService) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.AssignRoleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionRolesAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Assigning role",
+		zap.Int64("admin_id", caller.ID),
+		zap.Int64("user_id", req.UserId),
+		zap.String("role", req.Role))
+
+	if req.UserId == caller.ID {
+		return nil, status.Error(codes.FailedPrecondition, "cannot change your own role")
+	}
+	role, err := s.roleByName(ctx, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.db.UserQuery().GetByID(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
+	}
+	if user == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if user.RoleID == role.ID {
+		return &pb.AssignRoleResponse{}, nil
+	}
+
+	if _, err := s.db.UserQuery().SetRole(ctx, user.ID, role.ID); err != nil {
+		s.logger.Error("Failed to assign role", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to assign role")
+	}
+	revoked, err := s.db.SessionQuery().DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to revoke sessions", zap.Error(err), zap.Int64("user_id", user.ID))
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditRoleAssigned, actorID: caller.ID, subjectID: user.ID, reason: role.Name})
+	s.logger.Info("Role assigned",
+		zap.Int64("admin_id", caller.ID),
+		zap.Int64("user_id", user.ID),
+		zap.String("role", role.Name),
+		zap.Int64("revoked_sessions", revoked))
+	return &pb.AssignRoleResponse{RevokedSessions: revoked}, nil
+}
+
+// isBuiltinRole tells whether the service itself refers to the role by name.
+// Only the default role is; access is granted by permissions, not role names.
+func isBuiltinRole(name string) bool {
+	return name == DefaultRoleName
+}
+
+func roleFromProto(role *pb.Role) (*db.Role, error) {
+	if role == nil {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+	name := strings.ToLower(role.Name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, status.Error(codes.InvalidArgument, "role name must consist of letters, digits and underscores")
+	}
+	if role.Code <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "role code must be positive")
+	}
+	return &db.Role{
+		Code:        int(role.Code),
+		Name:        name,
+		Description: role.Description,
+		MFARequired: role.MfaRequired,
+	}, nil
+}
+
+func roleToProto(role *db.Role) *pb.Role {
+	return &pb.Role{
+		Name:        role.Name,
+		Code:        int32(role.Code),
+		Description: role.Description,
+		MfaRequired: role.MFARequired,
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AuthService) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, current, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Listing sessions", zap.Int64("user_id", user.ID))
+
+	sessions, err := s.db.SessionQuery().ListByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to list sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	resp := &pb.ListSessionsResponse{Sessions: make([]*pb.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionToProto(session, session.ID == current.ID))
+	}
+	return resp, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, _, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Revoking session", zap.Int64("user_id", user.ID), zap.String("session_id", req.SessionId))
+
+	session, err := s.db.SessionQuery().GetByID(ctx, req.SessionId)
+	if err != nil {
+		s.logger.Error("Failed to fetch session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch session")
+	}
+	if session == nil || session.UserID != user.ID {
+		s.logger.Warn("Session not found", zap.Int64("user_id", user.ID), zap.String("session_id", req.SessionId))
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+
+	if err := s.db.SessionQuery().Delete(ctx, session.ID); err != nil {
+		s.logger.Error("Failed to revoke session", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	s.recordAudit(ctx, auditRecord{eventType: AuditSessionRevoked, actorID: user.ID, subjectID: user.ID, reason: "session " + session.ID})
+	s.logger.Info("Session revoked successfully", zap.Int64("user_id", user.ID), zap.String("session_id", session.ID))
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+// authenticate resolves the caller from the bearer access token in the
+// request metadata.
+func (s *AuthService) authenticate(ctx context.Context) (*db.User, *db.Session, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	return s.verifyToken(ctx, token, AccessTokenType)
+}
+
+// authenticateAny is like authenticate but also accepts a refresh token, so a
+// client whose access token has already expired can still log out.
+func (s *AuthService) authenticateAny(ctx context.Context) (*db.User, *db.Session, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	tokenType, err := peekTokenType(token)
+	if err != nil || (tokenType != AccessTokenType && tokenType != RefreshTokenType) {
+		s.logger.Warn("Unexpected bearer token", zap.String("token_type", tokenType), zap.Error(err))
+		return nil, nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return s.verifyToken(ctx, token, tokenType)
+}
+
+func sessionToProto(session *db.Session, current bool) *pb.Session {
+	res := &pb.Session{
+		SessionId: session.ID,
+		UserAgent: session.UserAgent,
+		IpAddress: session.IPAddress,
+		ExpiresAt: session.ExpiresAt.Unix(),
+		Current:   current,
+	}
+	if session.CreatedAt != nil {
+		res.CreatedAt = session.CreatedAt.Unix()
+	}
+	if session.LastUsedAt != nil {
+		res.LastUsedAt = session.LastUsedAt.Unix()
+	}
+	return res
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorInfo reasons of the errors returned for accounts that are not active.
+const (
+	ReasonAccountPending   = "ACCOUNT_PENDING"
+	ReasonAccountSuspended = "ACCOUNT_SUSPENDED"
+)
+
+// statusTransitions lists the statuses each status may change to.
+var statusTransitions = map[string][]string{
+	db.UserStatusPending:   {db.UserStatusActive, db.UserStatusSuspended, db.UserStatusDeleted},
+	db.UserStatusActive:    {db.UserStatusSuspended, db.UserStatusDeleted},
+	db.UserStatusSuspended: {db.UserStatusActive, db.UserStatusPending, db.UserStatusDeleted},
+	db.UserStatusDeleted:   {},
+}
+
+// SuspendUser blocks an account. Its sessions end at once, so tokens already
+// handed out stop being accepted.
+func (s *AuthService) SuspendUser(ctx context.Context, req *pb.SuspendUserRequest) (*pb.SuspendUserResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Suspending user", zap.Int64("admin_id", caller.ID), zap.Int64("user_id", req.UserId))
+
+	if req.UserId == caller.ID {
+		return nil, status.Error(codes.FailedPrecondition, "cannot suspend yourself")
+	}
+	if req.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+	if _, err := s.changeStatus(ctx, req.UserId, db.UserStatusSuspended, req.Reason, &caller.ID); err != nil {
+		return nil, err
+	}
+	return &pb.SuspendUserResponse{}, nil
+}
+
+// ReinstateUser lifts a suspension. Accounts that still have to verify their
+// email go back to pending.
+func (s *AuthService) ReinstateUser(ctx context.Context, req *pb.ReinstateUserRequest) (*pb.ReinstateUserResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Reinstating user", zap.Int64("admin_id", caller.ID), zap.Int64("user_id", req.UserId))
+
+	user, err := s.userByID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != db.UserStatusSuspended {
+		return nil, status.Error(codes.FailedPrecondition, "user is not suspended")
+	}
+	to := db.UserStatusActive
+	if s.config.EmailVerificationMode == config.EmailVerificationRequired && !user.EmailVerified {
+		to = db.UserStatusPending
+	}
+	if _, err := s.changeStatus(ctx, user.ID, to, req.Reason, &caller.ID); err != nil {
+		return nil, err
+	}
+	return &pb.ReinstateUserResponse{Status: to}, nil
+}
+
+// DeleteUser marks an account as deleted. The row is kept so that the
+// username and email stay taken and the history stays readable.
+func (s *AuthService) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Deleting user", zap.Int64("admin_id", caller.ID), zap.Int64("user_id", req.UserId))
+
+	if req.UserId == caller.ID {
+		return nil, status.Error(codes.FailedPrecondition, "cannot delete yourself")
+	}
+	if _, err := s.changeStatus(ctx, req.UserId, db.UserStatusDeleted, req.Reason, &caller.ID); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
+
+// changeStatus moves the user to another status if the state machine allows
+// it. actorID is nil for changes the service makes on its own.
+func (s *AuthService) changeStatus(ctx context.Context, userID int64, to string, reason string, actorID *int64) (*db.User, error) {
+	user, err := s.userByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(statusTransitions[user.Status], to) {
+		s.logger.Warn("Status transition not allowed",
+			zap.Int64("user_id", user.ID),
+			zap.String("from", user.Status),
+			zap.String("to", to))
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot change status from %s to %s", user.Status, to)
+	}
+
+	updated, err := s.db.UserQuery().ChangeStatus(ctx, &db.UserStatusChange{
+		UserID:  user.ID,
+		From:    user.Status,
+		To:      to,
+		Reason:  reason,
+		ActorID: actorID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to change user status", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to change user status")
+	}
+	if updated == nil {
+		// The status changed between reading and updating it.
+		return nil, status.Error(codes.Aborted, "user status changed concurrently")
+	}
+
+	var actor int64
+	if actorID != nil {
+		actor = *actorID
+	}
+	s.recordAudit(ctx, auditRecord{
+		eventType: AuditUserStatusChanged,
+		actorID:   actor,
+		subjectID: user.ID,
+		reason:    user.Status + " -> " + to + ": " + reason,
+	})
+	s.logger.Info("User status changed",
+		zap.Int64("user_id", user.ID),
+		zap.String("from", user.Status),
+		zap.String("to", to),
+		zap.Int64p("actor_id", actorID))
+	return updated, nil
+}
+
+// accountStatusError tells why a user that is not active may not sign in or
+// use their tokens. Deleted accounts are reported as unknown.
+func (s *AuthService) accountStatusError(user *db.User) error {
+	var (
+		code    codes.Code
+		message string
+		reason  string
+	)
+	switch user.Status {
+	case db.UserStatusActive:
+		return nil
+	case db.UserStatusPending:
+		code, message, reason = codes.FailedPrecondition, "account is pending activation", ReasonAccountPending
+	case db.UserStatusSuspended:
+		code, message, reason = codes.PermissionDenied, "account is suspended", ReasonAccountSuspended
+	default:
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	s.logger.Warn("Account not active", zap.Int64("user_id", user.ID), zap.String("status", user.Status))
+	st, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+	})
+	if err != nil {
+		s.logger.Error("Failed to attach error details", zap.Error(err))
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+func (s *AuthService) userByID(ctx context.Context, id int64) (*db.User, error) {
+	user, err := s.db.UserQuery().GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
+	}
+	if user == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return user, nil
+}
+
+func statusChangeToProto(change *db.UserStatusChange) *pb.UserStatusChange {
+	res := &pb.UserStatusChange{
+		From:   change.From,
+		To:     change.To,
+		Reason: change.Reason,
+	}
+	if change.ActorID != nil {
+		res.ActorId = *change.ActorID
+	}
+	if change.CreatedAt != nil {
+		res.CreatedAt = change.CreatedAt.Unix()
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestChangeStatusTransitions(t *testing.T) {
+	const (
+		pending   = db.UserStatusPending
+		active    = db.UserStatusActive
+		suspended = db.UserStatusSuspended
+		deleted   = db.UserStatusDeleted
+	)
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{from: pending, to: active, allowed: true},
+		{from: pending, to: suspended, allowed: true},
+		{from: pending, to: deleted, allowed: true},
+		{from: active, to: pending},
+		{from: active, to: active},
+		{from: active, to: suspended, allowed: true},
+		{from: active, to: deleted, allowed: true},
+		{from: suspended, to: active, allowed: true},
+		{from: suspended, to: pending, allowed: true},
+		{from: suspended, to: suspended},
+		{from: suspended, to: deleted, allowed: true},
+		{from: deleted, to: active},
+		{from: deleted, to: pending},
+		{from: deleted, to: suspended},
+		{from: deleted, to: deleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			s, fake := newTestService(t)
+			user := addTestUser(t, s, fake, 1, "correct horse battery")
+			fake.users.users[user.ID].Status = tt.from
+
+			_, err := s.changeStatus(context.Background(), user.ID, tt.to, "test", nil)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("changeStatus() error = %v", err)
+				}
+				if got := fake.users.users[user.ID].Status; got != tt.to {
+					t.Errorf("changeStatus() left status %s, want %s", got, tt.to)
+				}
+				if events := fake.audit.find(AuditUserStatusChanged); len(events) != 1 {
+					t.Errorf("audit events = %+v, want one", events)
+				}
+				return
+			}
+			if status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("changeStatus() error = %v, want %s", err, codes.FailedPrecondition)
+			}
+			if got := fake.users.users[user.ID].Status; got != tt.from {
+				t.Errorf("refused changeStatus() changed status to %s", got)
+			}
+		})
+	}
+}
+
+func TestChangeStatusOfUnknownUser(t *testing.T) {
+	s, _ := newTestService(t)
+	if _, err := s.changeStatus(context.Background(), 42, db.UserStatusSuspended, "test", nil); status.Code(err) != codes.NotFound {
+		t.Errorf("changeStatus() error = %v, want %s", err, codes.NotFound)
+	}
+}
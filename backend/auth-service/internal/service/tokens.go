@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/config"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/pkg/authn"
+	"github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verifyToken checks the token and that the session it was issued for is
+// still live and holds the token's JTI.
+func (s *AuthService) verifyToken(ctx context.Context, tokenString string, tokenType string) (*db.User, *db.Session, error) {
+	user, session, _, err := s.verifyTokenClaims(ctx, tokenString, tokenType, s.ownExpectation())
+	return user, session, err
+}
+
+// verifyTokenClaims is verifyToken for a given issuer and audience that also
+// hands back the verified claims.
+func (s *AuthService) verifyTokenClaims(ctx context.Context, tokenString string, tokenType string, expect expectation) (*db.User, *db.Session, *authn.Claims, error) {
+	user, claims, err := s.parseToken(ctx, tokenString, tokenType, expect)
+	if err != nil {
+		s.logger.Error("Failed to parse token", zap.Error(err))
+		return nil, nil, nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if err := s.accountStatusError(user); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if claims.SessionID == "" {
+		s.logger.Warn("Token without session", zap.Int64("user_id", user.ID), zap.String("token_type", tokenType))
+		return nil, nil, nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	session, err := s.db.SessionQuery().GetByID(ctx, claims.SessionID)
+	if err != nil {
+		s.logger.Error("Failed to fetch session", zap.Error(err))
+		return nil, nil, nil, status.Error(codes.Internal, "failed to fetch session")
+	}
+	if session == nil || session.UserID != user.ID {
+		s.logger.Warn("Token revoked", zap.Int64("user_id", user.ID), zap.String("token_type", tokenType))
+		return nil, nil, nil, status.Error(codes.Unauthenticated, "token revoked (user logged out)")
+	}
+
+	storedJTI := session.RefreshTokenJTI
+	if tokenType == AccessTokenType {
+		storedJTI = session.AccessTokenJTI
+	}
+	if storedJTI != claims.ID {
+		s.logger.Warn("Invalid token jti", zap.Int64("user_id", user.ID), zap.String("token_type", tokenType))
+		return nil, nil, nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	s.touchSession(ctx, session)
+	return user, session, claims, nil
+}
+
+// touchSession moves last_used_at forward when a token of the session is used.
+// The write is skipped while the stored time is younger than
+// SESSION_TOUCH_INTERVAL, so busy clients do not update the row on every call.
+func (s *AuthService) touchSession(ctx context.Context, session *db.Session) {
+	now := time.Now()
+	usedBefore := now.Add(-s.config.SESSION_TOUCH_INTERVAL)
+	if session.LastUsedAt != nil && !session.LastUsedAt.Before(usedBefore) {
+		return
+	}
+	if err := s.db.SessionQuery().Touch(ctx, session.ID, usedBefore); err != nil {
+		// The time is informational; a failed write must not fail the request.
+		s.logger.Warn("Failed to touch session", zap.String("session_id", session.ID), zap.Error(err))
+		return
+	}
+	session.LastUsedAt = &now
+}
+
+// parseToken verifies the signature, type, issuer, audience and validity
+// window of the token and returns its owner. Whether the session and JTI are
+// still current is left to the caller.
+func (s *AuthService) parseToken(ctx context.Context, tokenString string, tokenType string, expect expectation) (*db.User, *authn.Claims, error) {
+	var user *db.User
+	claims := &authn.Claims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if claims.Type != tokenType {
+			return nil, fmt.Errorf("invalid token type: expected %s, got %s", tokenType, claims.Type)
+		}
+
+		var err error
+		user, err = s.db.UserQuery().GetByID(ctx, claims.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch user: %w", err)
+		}
+		if user == nil {
+			return nil, fmt.Errorf("user not found")
+		}
+		return s.verificationKey(ctx, token, user, tokenType)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := expect.validate(claims, s.config.JWT_LEEWAY, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	return user, claims, nil
+}
+
+// ownExpectation is what tokens presented to this service must carry: all
+// tokens it issues name the service itself among their audiences.
+func (s *AuthService) ownExpectation() expectation {
+	return expectation{issuer: s.config.JWTIssuer, audience: s.config.JWTIssuer}
+}
+
+// issueTokens signs a new access/refresh pair for the session's current JTIs.
+// With JWT_PERMISSIONS_CLAIM the access token also lists the role's
+// permissions; those go stale until the next refresh, so CheckPermission and
+// ValidateToken keep answering from the database.
+func (s *AuthService) issueTokens(ctx context.Context, user *db.User, role *db.Role, session *db.Session) (string, string, error) {
+	var permissions []string
+	if s.config.JWTPermissionsClaim {
+		var err error
+		permissions, err = s.rolePermissions(ctx, role.ID)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	accessToken, err := s.generateJWT(user, AccessTokenType, role.Name, s.config.ACCESS_TOKEN_EXPIRES_IN, session.AccessTokenJTI, session, permissions)
+	if err != nil {
+		s.logger.Error("Failed to generate access token", zap.Error(err))
+		return "", "", status.Error(codes.Internal, "failed to generate access token")
+	}
+	refreshToken, err := s.generateJWT(user, RefreshTokenType, role.Name, s.config.REFRESH_TOKEN_EXPIRES_IN, session.RefreshTokenJTI, session, nil)
+	if err != nil {
+		s.logger.Error("Failed to generate refresh token", zap.Error(err))
+		return "", "", status.Error(codes.Internal, "failed to generate refresh token")
+	}
+	return accessToken, refreshToken, nil
+}
+
+func (s *AuthService) generateJWT(user *db.User, tokenType string, roleName string, expiresIn time.Duration, jti string, session *db.Session, permissions []string) (string, error) {
+	now := time.Now()
+	// auth_time is when the user last entered credentials, i.e. when the
+	// session was opened; refreshing keeps it.
+	authTime := now
+	if session.CreatedAt != nil {
+		authTime = *session.CreatedAt
+	}
+	claims := s.newClaims(user, tokenType, expiresIn, jti, now)
+	claims.Role = roleName
+	claims.SessionID = session.ID
+	claims.AuthTime = authTime.Unix()
+	claims.Permissions = permissions
+	if s.config.EmailVerificationMode != config.EmailVerificationOff {
+		claims.EmailVerified = &user.EmailVerified
+	}
+	return s.signToken(user, claims)
+}
+
+// newClaims fills in the registered claims. Access tokens are addressed to
+// every configured audience, the other token types only to this service.
+func (s *AuthService) newClaims(user *db.User, tokenType string, expiresIn time.Duration, jti string, now time.Time) *authn.Claims {
+	audience := authn.Audience{s.config.JWTIssuer}
+	if tokenType == AccessTokenType {
+		audience = append(audience, s.config.JWTAudiences...)
+	}
+	return &authn.Claims{
+		Issuer:    s.config.JWTIssuer,
+		Subject:   user.ID,
+		Audience:  audience,
+		ExpiresAt: now.Add(expiresIn).Unix(),
+		NotBefore: now.Unix(),
+		IssuedAt:  now.Unix(),
+		ID:        jti,
+		Type:      tokenType,
+	}
+}
+
+// signToken signs access tokens with the server key set so that other
+// services can verify them against the JWKS. Refresh and MFA tokens are only
+// ever read back by this service and stay HMAC-signed with the user's secrets.
+func (s *AuthService) signToken(user *db.User, claims *authn.Claims) (string, error) {
+	switch claims.Type {
+	case AccessTokenType:
+		key := s.keys.Current().Signing()
+		token := jwt.NewWithClaims(key.SigningMethod(), claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.Private)
+	case RefreshTokenType:
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(user.RefreshTokenSecret))
+	default:
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(user.AccessTokenSecret))
+	}
+}
+
+// verificationKey is the counterpart of signToken. It pins the algorithm to
+// the one of the selected key, so an HMAC token can never be checked against
+// a public key or the other way round.
+func (s *AuthService) verificationKey(ctx context.Context, token *jwt.Token, user *db.User, tokenType string) (interface{}, error) {
+	if tokenType == AccessTokenType {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys.Lookup(ctx, kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Public, nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	if tokenType == RefreshTokenType {
+		return []byte(user.RefreshTokenSecret), nil
+	}
+	return []byte(user.AccessTokenSecret), nil
+}
+
+// peekTokenType reads the type claim without verifying the token; the result
+// must only be used to choose how to verify it.
+func peekTokenType(tokenString string) (string, error) {
+	claims := &authn.Claims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return "", err
+	}
+	return claims.Type, nil
+}
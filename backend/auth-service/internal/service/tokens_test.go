@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestVerifyTokenTouchesSession(t *testing.T) {
+	s, fake := newTestService(t)
+	user := addTestUser(t, s, fake, 1, "correct horse battery")
+	session, accessToken, _ := openTestSession(t, s, fake, user)
+
+	if _, _, err := s.verifyToken(context.Background(), accessToken, AccessTokenType); err != nil {
+		t.Fatalf("verifyToken() error = %v", err)
+	}
+	if got := fake.sessions.get(session.ID).LastUsedAt; !got.Equal(*session.LastUsedAt) {
+		t.Errorf("verifyToken() moved last_used_at within SESSION_TOUCH_INTERVAL")
+	}
+
+	stale := time.Now().Add(-2 * s.config.SESSION_TOUCH_INTERVAL)
+	fake.sessions.sessions[session.ID].LastUsedAt = &stale
+	if _, _, err := s.verifyToken(context.Background(), accessToken, AccessTokenType); err != nil {
+		t.Fatalf("verifyToken() error = %v", err)
+	}
+	if got := fake.sessions.get(session.ID).LastUsedAt; !got.After(stale) {
+		t.Errorf("verifyToken() left a stale last_used_at at %v", got)
+	}
+}
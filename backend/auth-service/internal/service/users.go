@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultUsersPageSize = 50
+	maxUsersPageSize     = 200
+)
+
+// usersQuery is the common part of ListUsers and SearchUsers.
+type usersQuery struct {
+	prefix     string
+	filter     *pb.UserFilter
+	sortBy     pb.UserSortField
+	descending bool
+	pageSize   int32
+	pageToken  string
+}
+
+// usersPageToken is the position after the last user of a page. Sort and
+// Descending are kept to reject tokens reused with another ordering.
+type usersPageToken struct {
+	Sort       pb.UserSortField `json:"s"`
+	Descending bool             `json:"d"`
+	ID         int64            `json:"id"`
+	Value      string           `json:"v,omitempty"`
+}
+
+func (s *AuthService) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Listing users", zap.Int64("admin_id", caller.ID))
+
+	users, next, err := s.listUsers(ctx, usersQuery{
+		filter:     req.Filter,
+		sortBy:     req.SortBy,
+		descending: req.Descending,
+		pageSize:   req.PageSize,
+		pageToken:  req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListUsersResponse{Users: users, NextPageToken: next}, nil
+}
+
+func (s *AuthService) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Searching users", zap.Int64("admin_id", caller.ID), zap.String("query", req.Query))
+
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	users, next, err := s.listUsers(ctx, usersQuery{
+		prefix:     req.Query,
+		filter:     req.Filter,
+		sortBy:     req.SortBy,
+		descending: req.Descending,
+		pageSize:   req.PageSize,
+		pageToken:  req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SearchUsersResponse{Users: users, NextPageToken: next}, nil
+}
+
+// GetUser returns a user together with their role, active sessions and
+// status history.
+func (s *AuthService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	caller, err := s.authenticatePermission(ctx, PermissionUsersAdmin)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Fetching user", zap.Int64("admin_id", caller.ID), zap.Int64("user_id", req.UserId))
+
+	user, err := s.db.UserQuery().GetByID(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to fetch user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to fetch user")
+	}
+	if user == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	role, err := s.userRole(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.db.SessionQuery().ListByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to list sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	changes, err := s.db.UserStatusChangeQuery().ListByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Failed to list status changes", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list status changes")
+	}
+
+	resp := &pb.GetUserResponse{
+		User:          userToProto(user, role.Name),
+		Role:          roleToProto(role),
+		Sessions:      make([]*pb.Session, 0, len(sessions)),
+		StatusHistory: make([]*pb.UserStatusChange, 0, len(changes)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionToProto(session, false))
+	}
+	for _, change := range changes {
+		resp.StatusHistory = append(resp.StatusHistory, statusChangeToProto(change))
+	}
+	return resp, nil
+}
+
+func (s *AuthService) listUsers(ctx context.Context, q usersQuery) ([]*pb.UserSummary, string, error) {
+	page, err := s.usersPage(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	limit := page.Limit
+	// One extra row tells whether there is a next page.
+	page.Limit++
+
+	users, err := s.db.UserQuery().List(ctx, page)
+	if err != nil {
+		s.logger.Error("Failed to list users", zap.Error(err))
+		return nil, "", status.Error(codes.Internal, "failed to list users")
+	}
+	next := ""
+	if uint64(len(users)) > limit {
+		users = users[:limit]
+		next = encodeUsersPageToken(q, users[len(users)-1])
+	}
+
+	roles, err := s.db.RoleQuery().List(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list roles", zap.Error(err))
+		return nil, "", status.Error(codes.Internal, "failed to list roles")
+	}
+	roleNames := make(map[int64]string, len(roles))
+	for _, role := range roles {
+		roleNames[role.ID] = role.Name
+	}
+
+	res := make([]*pb.UserSummary, 0, len(users))
+	for _, user := range users {
+		res = append(res, userToProto(user, roleNames[user.RoleID]))
+	}
+	return res, next, nil
+}
+
+// usersPage turns the request into a db.UserPage, resolving the role filter
+// and the page token.
+func (s *AuthService) usersPage(ctx context.Context, q usersQuery) (*db.UserPage, error) {
+	page := &db.UserPage{
+		Descending: q.descending,
+		Limit:      defaultUsersPageSize,
+	}
+	switch {
+	case q.pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page size must not be negative")
+	case q.pageSize > maxUsersPageSize:
+		page.Limit = maxUsersPageSize
+	case q.pageSize > 0:
+		page.Limit = uint64(q.pageSize)
+	}
+
+	switch q.sortBy {
+	case pb.UserSortField_USER_SORT_FIELD_ID:
+		page.Sort = db.UserSortID
+	case pb.UserSortField_USER_SORT_FIELD_USERNAME:
+		page.Sort = db.UserSortUsername
+	case pb.UserSortField_USER_SORT_FIELD_EMAIL:
+		page.Sort = db.UserSortEmail
+	case pb.UserSortField_USER_SORT_FIELD_CREATED_AT:
+		page.Sort = db.UserSortCreatedAt
+	case pb.UserSortField_USER_SORT_FIELD_AUTH_TIME:
+		page.Sort = db.UserSortAuthTime
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unknown sort field")
+	}
+
+	page.Filter.Prefix = q.prefix
+	if f := q.filter; f != nil {
+		if f.Role != "" {
+			role, err := s.roleByName(ctx, f.Role)
+			if err != nil {
+				return nil, err
+			}
+			page.Filter.RoleID = role.ID
+		}
+		page.Filter.Status = f.Status
+		page.Filter.CreatedAfter = unixFilter(f.CreatedAfter)
+		page.Filter.CreatedBefore = unixFilter(f.CreatedBefore)
+		page.Filter.AuthAfter = unixFilter(f.AuthAfter)
+		page.Filter.AuthBefore = unixFilter(f.AuthBefore)
+	}
+
+	if q.pageToken != "" {
+		token, err := decodeUsersPageToken(q.pageToken)
+		if err != nil || token.Sort != q.sortBy || token.Descending != q.descending {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		page.AfterID = token.ID
+		switch page.Sort {
+		case db.UserSortUsername, db.UserSortEmail:
+			page.AfterValue = token.Value
+		case db.UserSortCreatedAt, db.UserSortAuthTime:
+			after, err := time.Parse(time.RFC3339Nano, token.Value)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid page token")
+			}
+			page.AfterValue = after
+		}
+	}
+	return page, nil
+}
+
+func encodeUsersPageToken(q usersQuery, last *db.User) string {
+	token := usersPageToken{Sort: q.sortBy, Descending: q.descending, ID: last.ID}
+	switch q.sortBy {
+	case pb.UserSortField_USER_SORT_FIELD_USERNAME:
+		token.Value = last.Username
+	case pb.UserSortField_USER_SORT_FIELD_EMAIL:
+		token.Value = last.Email
+	case pb.UserSortField_USER_SORT_FIELD_CREATED_AT:
+		token.Value = timeOrEpoch(last.CreatedAt).Format(time.RFC3339Nano)
+	case pb.UserSortField_USER_SORT_FIELD_AUTH_TIME:
+		token.Value = timeOrEpoch(last.AuthTime).Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUsersPageToken(s string) (*usersPageToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	token := &usersPageToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// timeOrEpoch mirrors the ordering of the database, which sorts missing times
+// as the epoch.
+func timeOrEpoch(t *time.Time) time.Time {
+	if t == nil {
+		return time.Unix(0, 0).UTC()
+	}
+	return *t
+}
+
+func unixFilter(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
+
+func userToProto(user *db.User, roleName string) *pb.UserSummary {
+	res := &pb.UserSummary{
+		UserId:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          roleName,
+		TotpEnabled:   user.TOTPEnabled,
+		Status:        user.Status,
+	}
+	if user.StatusReason != nil {
+		res.StatusReason = *user.StatusReason
+	}
+	if user.CreatedAt != nil {
+		res.CreatedAt = user.CreatedAt.Unix()
+	}
+	if user.AuthTime != nil {
+		res.AuthTime = user.AuthTime.Unix()
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUsersPageToken(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	last := &db.User{ID: 42, Username: "jörg", Email: "jorg@example.com", CreatedAt: &createdAt}
+
+	tests := []struct {
+		name       string
+		sortBy     pb.UserSortField
+		descending bool
+		wantSort   db.UserSort
+		wantValue  interface{}
+	}{
+		{name: "id", sortBy: pb.UserSortField_USER_SORT_FIELD_ID, wantSort: db.UserSortID},
+		{name: "id descending", sortBy: pb.UserSortField_USER_SORT_FIELD_ID, descending: true, wantSort: db.UserSortID},
+		{name: "username", sortBy: pb.UserSortField_USER_SORT_FIELD_USERNAME, wantSort: db.UserSortUsername, wantValue: "jörg"},
+		{name: "email", sortBy: pb.UserSortField_USER_SORT_FIELD_EMAIL, wantSort: db.UserSortEmail, wantValue: "jorg@example.com"},
+		{name: "created at keeps nanoseconds", sortBy: pb.UserSortField_USER_SORT_FIELD_CREATED_AT, wantSort: db.UserSortCreatedAt, wantValue: createdAt},
+		{name: "missing auth time sorts as the epoch", sortBy: pb.UserSortField_USER_SORT_FIELD_AUTH_TIME, descending: true, wantSort: db.UserSortAuthTime, wantValue: time.Unix(0, 0).UTC()},
+	}
+	s := &AuthService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := usersQuery{sortBy: tt.sortBy, descending: tt.descending}
+			q.pageToken = encodeUsersPageToken(q, last)
+			page, err := s.usersPage(context.Background(), q)
+			if err != nil {
+				t.Fatalf("usersPage() error = %v", err)
+			}
+			if page.Sort != tt.wantSort || page.Descending != tt.descending || page.AfterID != last.ID {
+				t.Errorf("usersPage() = sort %v descending %v after %d, want sort %v descending %v after %d",
+					page.Sort, page.Descending, page.AfterID, tt.wantSort, tt.descending, last.ID)
+			}
+			if after, ok := page.AfterValue.(time.Time); ok {
+				if want, _ := tt.wantValue.(time.Time); !after.Equal(want) {
+					t.Errorf("usersPage() AfterValue = %v, want %v", after, want)
+				}
+			} else if page.AfterValue != tt.wantValue {
+				t.Errorf("usersPage() AfterValue = %v, want %v", page.AfterValue, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestUsersPageRejectsForeignTokens(t *testing.T) {
+	byUsername := usersQuery{sortBy: pb.UserSortField_USER_SORT_FIELD_USERNAME}
+	token := encodeUsersPageToken(byUsername, &db.User{ID: 42, Username: "joe"})
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name string
+		q    usersQuery
+	}{
+		{name: "other sort field", q: usersQuery{sortBy: pb.UserSortField_USER_SORT_FIELD_EMAIL, pageToken: token}},
+		{name: "other direction", q: usersQuery{sortBy: byUsername.sortBy, descending: true, pageToken: token}},
+		{name: "not base64", q: usersQuery{sortBy: byUsername.sortBy, pageToken: "not a token!"}},
+		{name: "padded base64", q: usersQuery{sortBy: byUsername.sortBy, pageToken: token + "="}},
+		{name: "not JSON", q: usersQuery{sortBy: byUsername.sortBy, pageToken: encode("42")}},
+		{name: "malformed time", q: usersQuery{sortBy: pb.UserSortField_USER_SORT_FIELD_CREATED_AT, pageToken: encode(`{"s":3,"d":false,"id":42,"v":"yesterday"}`)}},
+	}
+	s := &AuthService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.usersPage(context.Background(), tt.q); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("usersPage() error = %v, want %s", err, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestUsersPageSize(t *testing.T) {
+	tests := []struct {
+		pageSize int32
+		want     uint64
+	}{
+		{pageSize: 0, want: defaultUsersPageSize},
+		{pageSize: 10, want: 10},
+		{pageSize: maxUsersPageSize + 1, want: maxUsersPageSize},
+	}
+	s := &AuthService{}
+	for _, tt := range tests {
+		page, err := s.usersPage(context.Background(), usersQuery{pageSize: tt.pageSize})
+		if err != nil {
+			t.Fatalf("usersPage() error = %v", err)
+		}
+		if page.Limit != tt.want {
+			t.Errorf("usersPage() with page size %d Limit = %d, want %d", tt.pageSize, page.Limit, tt.want)
+		}
+	}
+	if _, err := s.usersPage(context.Background(), usersQuery{pageSize: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("usersPage() with a negative page size error = %v, want %s", err, codes.InvalidArgument)
+	}
+}
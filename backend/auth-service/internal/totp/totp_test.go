@@ -0,0 +1,120 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes; with 6 digits they keep their last 6.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{unix: 59, code: "287082"},
+	{unix: 1111111109, code: "081804"},
+	{unix: 1111111111, code: "050471"},
+	{unix: 1234567890, code: "005924"},
+	{unix: 2000000000, code: "279037"},
+	{unix: 20000000000, code: "353130"},
+}
+
+func TestCode(t *testing.T) {
+	for _, tt := range rfc6238Vectors {
+		t.Run(tt.code, func(t *testing.T) {
+			got, err := Code(rfc6238Secret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.code {
+				t.Errorf("Code() at %d = %s, want %s", tt.unix, got, tt.code)
+			}
+		})
+	}
+
+	if got, err := Code(strings.ToLower(rfc6238Secret), Step(time.Unix(59, 0))); err != nil || got != "287082" {
+		t.Errorf("Code() with a lower case secret = %q, %v, want 287082", got, err)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Errorf("Code() with an invalid secret succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	previous, _ := Code(rfc6238Secret, step-1)
+	next, _ := Code(rfc6238Secret, step+1)
+	tooOld, _ := Code(rfc6238Secret, step-2)
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfc6238Secret, code: "050471", skew: 1, wantStep: step, wantOK: true},
+		{name: "surrounding spaces", secret: rfc6238Secret, code: " 050471 ", skew: 1, wantStep: step, wantOK: true},
+		{name: "previous step", secret: rfc6238Secret, code: previous, skew: 1, wantStep: step - 1, wantOK: true},
+		{name: "next step", secret: rfc6238Secret, code: next, skew: 1, wantStep: step + 1, wantOK: true},
+		{name: "previous step without skew", secret: rfc6238Secret, code: previous, skew: 0},
+		{name: "beyond skew", secret: rfc6238Secret, code: tooOld, skew: 1},
+		{name: "wrong code", secret: rfc6238Secret, code: "123456", skew: 1},
+		{name: "short code", secret: rfc6238Secret, code: "05047", skew: 1},
+		{name: "long code", secret: rfc6238Secret, code: "0504710", skew: 1},
+		{name: "invalid secret", secret: "not base32!", code: "050471", skew: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := Validate(tt.secret, tt.code, now, tt.skew)
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = %d, %v, want %d, %v", gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("GenerateSecret() = %q is not base32: %v", secret, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("GenerateSecret() key is %d bytes, want %d", len(key), secretSize)
+	}
+	if other, _ := GenerateSecret(); other == secret {
+		t.Errorf("GenerateSecret() returned the same secret twice")
+	}
+}
+
+func TestURI(t *testing.T) {
+	got := URI("Sirius Lingo", "alice@example.com", rfc6238Secret)
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("URI() = %q does not parse: %v", got, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Sirius Lingo:alice@example.com" {
+		t.Errorf("URI() = %q, want an otpauth://totp/ URI labelled issuer:account", got)
+	}
+	want := url.Values{
+		"secret":    {rfc6238Secret},
+		"issuer":    {"Sirius Lingo"},
+		"algorithm": {"SHA1"},
+		"digits":    {"6"},
+		"period":    {"30"},
+	}
+	if query := u.Query(); query.Encode() != want.Encode() {
+		t.Errorf("URI() query = %q, want %q", query.Encode(), want.Encode())
+	}
+}
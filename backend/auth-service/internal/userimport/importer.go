@@ -0,0 +1,264 @@
+// Package userimport brings the users of another platform into the users
+// table. Their password hashes are kept in the scheme they were exported in
+// and replaced with a current hash when they first log in.
+package userimport
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/db"
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/passwords"
+	"go.uber.org/zap"
+)
+
+// Conflict reasons.
+const (
+	ConflictExists    = "exists"
+	ConflictDuplicate = "duplicate"
+)
+
+const (
+	maxUsernameLength = 100
+	// lookupBatchSize bounds the number of names sent in one conflict query.
+	lookupBatchSize = 1000
+)
+
+// Conflict is a record whose username or email is taken, by an existing
+// user or by an earlier record of the same import.
+type Conflict struct {
+	Line           int    `json:"line"`
+	Username       string `json:"username"`
+	Email          string `json:"email"`
+	Field          string `json:"field"`
+	Reason         string `json:"reason"`
+	ExistingUserID int64  `json:"existing_user_id,omitempty"`
+}
+
+// Invalid is a record that cannot be imported as it is.
+type Invalid struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// Report is the outcome of an import. On a dry run Imported counts the
+// records that would have been imported.
+type Report struct {
+	Total     int        `json:"total"`
+	Imported  int        `json:"imported"`
+	DryRun    bool       `json:"dry_run"`
+	Conflicts []Conflict `json:"conflicts"`
+	Invalid   []Invalid  `json:"invalid"`
+}
+
+// Options control an import. Imported users get RoleID and are active.
+type Options struct {
+	RoleID int64
+	DryRun bool
+}
+
+type Importer struct {
+	db     db.Implementation
+	logger *zap.Logger
+}
+
+func NewImporter(db db.Implementation, logger *zap.Logger) *Importer {
+	return &Importer{db: db, logger: logger}
+}
+
+// candidate is a record that passed the checks, in the form it is stored in.
+type candidate struct {
+	record *Record
+	hash   string
+}
+
+// Import checks every record, skips the invalid and conflicting ones and
+// inserts the rest. Each user is inserted on its own, so when the import
+// fails halfway the users inserted so far stay; importing the same file
+// again reports them as conflicts.
+func (i *Importer) Import(ctx context.Context, records []Record, opts Options) (*Report, error) {
+	report := &Report{Total: len(records), DryRun: opts.DryRun}
+	i.logger.Info("Importing users", zap.Int("records", len(records)), zap.Bool("dry_run", opts.DryRun))
+
+	candidates := i.check(records, report)
+	candidates, err := i.skipExisting(ctx, candidates, report)
+	if err != nil {
+		return report, err
+	}
+
+	if opts.DryRun {
+		report.Imported = len(candidates)
+		i.logger.Info("Dry run of user import finished",
+			zap.Int("importable", report.Imported),
+			zap.Int("conflicts", len(report.Conflicts)),
+			zap.Int("invalid", len(report.Invalid)))
+		return report, nil
+	}
+
+	for _, c := range candidates {
+		user, err := i.insert(ctx, c, opts.RoleID)
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				// Someone registered the name since the conflict check.
+				field := "username"
+				if strings.Contains(db.ViolatedConstraint(err), db.UsersEmail) {
+					field = "email"
+				}
+				report.Conflicts = append(report.Conflicts, Conflict{
+					Line:     c.record.Line,
+					Username: c.record.Username,
+					Email:    c.record.Email,
+					Field:    field,
+					Reason:   ConflictExists,
+				})
+				continue
+			}
+			return report, fmt.Errorf("line %d: %w", c.record.Line, err)
+		}
+		report.Imported++
+		i.logger.Debug("User imported", zap.Int64("user_id", user.ID), zap.Int("line", c.record.Line))
+	}
+
+	i.logger.Info("User import finished",
+		zap.Int("imported", report.Imported),
+		zap.Int("conflicts", len(report.Conflicts)),
+		zap.Int("invalid", len(report.Invalid)))
+	return report, nil
+}
+
+// check normalizes the records, converts their hashes and reports invalid
+// records and duplicates within the import.
+func (i *Importer) check(records []Record, report *Report) []candidate {
+	usernames := make(map[string]bool)
+	emails := make(map[string]bool)
+	var candidates []candidate
+	for k := range records {
+		record := &records[k]
+		record.Username = strings.TrimSpace(record.Username)
+		record.Email = strings.ToLower(strings.TrimSpace(record.Email))
+
+		if reason := checkRecord(record); reason != "" {
+			report.Invalid = append(report.Invalid, Invalid{Line: record.Line, Reason: reason})
+			continue
+		}
+		hash, err := passwords.ImportHash(record.PasswordScheme, record.PasswordHash, record.PasswordSalt)
+		if err != nil {
+			report.Invalid = append(report.Invalid, Invalid{Line: record.Line, Reason: err.Error()})
+			continue
+		}
+
+		field := ""
+		switch {
+		case usernames[record.Username]:
+			field = "username"
+		case emails[record.Email]:
+			field = "email"
+		}
+		if field != "" {
+			report.Conflicts = append(report.Conflicts, Conflict{
+				Line:     record.Line,
+				Username: record.Username,
+				Email:    record.Email,
+				Field:    field,
+				Reason:   ConflictDuplicate,
+			})
+			continue
+		}
+		usernames[record.Username] = true
+		emails[record.Email] = true
+		candidates = append(candidates, candidate{record: record, hash: hash})
+	}
+	return candidates
+}
+
+// checkRecord returns why a record cannot be imported, or "". Usernames
+// with "@" are refused like at registration, since Login would take them
+// for emails.
+func checkRecord(record *Record) string {
+	switch {
+	case record.Username == "":
+		return "username is required"
+	case utf8.RuneCountInString(record.Username) > maxUsernameLength:
+		return fmt.Sprintf("username must be at most %d characters long", maxUsernameLength)
+	case strings.Contains(record.Username, "@"):
+		return "username must not contain @"
+	case strings.IndexFunc(record.Username, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0:
+		return "username must not contain spaces or control characters"
+	case record.Email == "":
+		return "email is required"
+	}
+	if addr, err := mail.ParseAddress(record.Email); err != nil || addr.Address != record.Email {
+		return "email is not a valid address"
+	}
+	return ""
+}
+
+// skipExisting reports the candidates whose username or email is taken and
+// returns the others.
+func (i *Importer) skipExisting(ctx context.Context, candidates []candidate, report *Report) ([]candidate, error) {
+	takenUsernames := make(map[string]int64)
+	takenEmails := make(map[string]int64)
+	for start := 0; start < len(candidates); start += lookupBatchSize {
+		batch := candidates[start:min(start+lookupBatchSize, len(candidates))]
+		usernames := make([]string, len(batch))
+		emails := make([]string, len(batch))
+		for k, c := range batch {
+			usernames[k] = c.record.Username
+			emails[k] = c.record.Email
+		}
+		users, err := i.db.UserQuery().ListByUsernamesOrEmails(ctx, usernames, emails)
+		if err != nil {
+			i.logger.Error("Failed to look up existing users", zap.Error(err))
+			return nil, fmt.Errorf("failed to look up existing users: %w", err)
+		}
+		for _, user := range users {
+			takenUsernames[user.Username] = user.ID
+			takenEmails[strings.ToLower(user.Email)] = user.ID
+		}
+	}
+
+	remaining := candidates[:0]
+	for _, c := range candidates {
+		conflict := Conflict{
+			Line:     c.record.Line,
+			Username: c.record.Username,
+			Email:    c.record.Email,
+			Reason:   ConflictExists,
+		}
+		if id, ok := takenUsernames[c.record.Username]; ok {
+			conflict.Field, conflict.ExistingUserID = "username", id
+		} else if id, ok := takenEmails[c.record.Email]; ok {
+			conflict.Field, conflict.ExistingUserID = "email", id
+		} else {
+			remaining = append(remaining, c)
+			continue
+		}
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+	return remaining, nil
+}
+
+func (i *Importer) insert(ctx context.Context, c candidate, roleID int64) (*db.User, error) {
+	accessTokenSecret, err := db.GenerateSecretKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token secret: %w", err)
+	}
+	refreshTokenSecret, err := db.GenerateSecretKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token secret: %w", err)
+	}
+	return i.db.UserQuery().Insert(ctx, &db.User{
+		Username:           c.record.Username,
+		Password:           c.hash,
+		Email:              c.record.Email,
+		EmailVerified:      c.record.EmailVerified,
+		RoleID:             roleID,
+		Status:             db.UserStatusActive,
+		AccessTokenSecret:  accessTokenSecret,
+		RefreshTokenSecret: refreshTokenSecret,
+	})
+}
@@ -0,0 +1,122 @@
+package userimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Export formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Record is one user of an export. Line is where it starts in the file,
+// counting the CSV header.
+type Record struct {
+	Line           int    `json:"-"`
+	Username       string `json:"username"`
+	Email          string `json:"email"`
+	PasswordHash   string `json:"password_hash"`
+	PasswordScheme string `json:"password_scheme"`
+	PasswordSalt   string `json:"password_salt"`
+	EmailVerified  bool   `json:"email_verified"`
+}
+
+var requiredColumns = []string{"username", "email", "password_hash", "password_scheme"}
+
+// Read parses an export in the given format. A file that cannot be parsed
+// is refused as a whole; the contents of the records are checked by Import.
+func Read(r io.Reader, format string) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+}
+
+// readCSV reads a CSV file whose header names the columns, in any order.
+// Unknown columns are ignored.
+func readCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header lacks the %s column", name)
+		}
+	}
+	value := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		record := Record{
+			Line:           line,
+			Username:       value(row, "username"),
+			Email:          value(row, "email"),
+			PasswordHash:   value(row, "password_hash"),
+			PasswordScheme: value(row, "password_scheme"),
+			PasswordSalt:   value(row, "password_salt"),
+		}
+		if verified := value(row, "email_verified"); verified != "" {
+			record.EmailVerified, err = strconv.ParseBool(verified)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid email_verified %q", line, verified)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readJSONL reads one JSON object per line. Blank lines are skipped.
+func readJSONL(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		record := Record{Line: line}
+		if err := json.Unmarshal(text, &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read JSONL: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Violation reasons, reported in BadRequest.FieldViolation.Reason.
+const (
+	ReasonRequired      = "FIELD_REQUIRED"
+	ReasonTooShort      = "FIELD_TOO_SHORT"
+	ReasonTooLong       = "FIELD_TOO_LONG"
+	ReasonInvalidFormat = "FIELD_INVALID_FORMAT"
+	ReasonOutOfRange    = "FIELD_OUT_OF_RANGE"
+	ReasonUnknownValue  = "FIELD_UNKNOWN_VALUE"
+	ReasonTooManyItems  = "FIELD_TOO_MANY_ITEMS"
+)
+
+var (
+	stringKinds  = []protoreflect.Kind{protoreflect.StringKind}
+	bytesKinds   = []protoreflect.Kind{protoreflect.StringKind, protoreflect.BytesKind}
+	integerKinds = []protoreflect.Kind{
+		protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+	}
+	enumKinds = []protoreflect.Kind{protoreflect.EnumKind}
+)
+
+// Check is a single rule on a field. Apart from Required, checks only run on
+// fields that are set, which in proto3 means fields with a non-zero value.
+type Check struct {
+	reason      string
+	description string
+	required    bool
+	// kinds lists the field kinds the check applies to; nil means any.
+	kinds []protoreflect.Kind
+	// onList makes the check look at a repeated field as a whole.
+	onList bool
+	// items are checked against every element of a repeated field.
+	items []Check
+	valid func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool
+}
+
+func (c Check) accepts(fd protoreflect.FieldDescriptor) bool {
+	switch {
+	case c.required:
+		return true
+	case c.items != nil:
+		if !fd.IsList() {
+			return false
+		}
+		for _, item := range c.items {
+			if item.required || item.onList || item.items != nil || !item.acceptsKind(fd.Kind()) {
+				return false
+			}
+		}
+		return true
+	case c.onList:
+		return fd.IsList()
+	default:
+		return !fd.IsList() && !fd.IsMap() && c.acceptsKind(fd.Kind())
+	}
+}
+
+func (c Check) acceptsKind(kind protoreflect.Kind) bool {
+	return c.kinds == nil || slices.Contains(c.kinds, kind)
+}
+
+func (c Check) violation(field string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: field + " " + c.description,
+		Reason:      c.reason,
+	}
+}
+
+// Required rejects unset fields: empty strings and lists, zero numbers and
+// missing messages.
+func Required() Check {
+	return Check{reason: ReasonRequired, description: "is required", required: true}
+}
+
+// MinLen rejects strings shorter than n characters.
+func MinLen(n int) Check {
+	return Check{
+		reason:      ReasonTooShort,
+		description: fmt.Sprintf("must be at least %d characters long", n),
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return utf8.RuneCountInString(value.String()) >= n
+		},
+	}
+}
+
+// MaxLen rejects strings longer than n characters.
+func MaxLen(n int) Check {
+	return Check{
+		reason:      ReasonTooLong,
+		description: fmt.Sprintf("must be at most %d characters long", n),
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return utf8.RuneCountInString(value.String()) <= n
+		},
+	}
+}
+
+// MaxBytes rejects strings and byte fields longer than n bytes. It bounds
+// opaque values such as tokens, where characters mean nothing.
+func MaxBytes(n int) Check {
+	return Check{
+		reason:      ReasonTooLong,
+		description: fmt.Sprintf("must be at most %d bytes long", n),
+		kinds:       bytesKinds,
+		valid: func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			if fd.Kind() == protoreflect.BytesKind {
+				return len(value.Bytes()) <= n
+			}
+			return len(value.String()) <= n
+		},
+	}
+}
+
+// Pattern rejects strings that do not match re. The description completes
+// "<field> ...", like "must consist of digits".
+func Pattern(re *regexp.Regexp, description string) Check {
+	return Check{
+		reason:      ReasonInvalidFormat,
+		description: description,
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return re.MatchString(value.String())
+		},
+	}
+}
+
+// Email rejects strings that are not a bare email address with a dotted
+// domain, like "name@example.com".
+func Email() Check {
+	return Check{
+		reason:      ReasonInvalidFormat,
+		description: "must be a valid email address",
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			addr, err := mail.ParseAddress(value.String())
+			if err != nil || addr.Name != "" || addr.Address != value.String() {
+				return false
+			}
+			_, domain, _ := strings.Cut(addr.Address, "@")
+			return strings.Contains(strings.Trim(domain, "."), ".")
+		},
+	}
+}
+
+// IPAddress rejects strings that are not an IPv4 or IPv6 address.
+func IPAddress() Check {
+	return Check{
+		reason:      ReasonInvalidFormat,
+		description: "must be an IP address",
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			_, err := netip.ParseAddr(value.String())
+			return err == nil
+		},
+	}
+}
+
+// UUID rejects strings that are not a UUID in its canonical form.
+func UUID() Check {
+	return Check{
+		reason:      ReasonInvalidFormat,
+		description: "must be a UUID",
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			_, err := uuid.Parse(value.String())
+			return err == nil && len(value.String()) == 36
+		},
+	}
+}
+
+// In rejects strings other than the given values.
+func In(values ...string) Check {
+	return Check{
+		reason:      ReasonUnknownValue,
+		description: "must be one of " + strings.Join(values, ", "),
+		kinds:       stringKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return slices.Contains(values, value.String())
+		},
+	}
+}
+
+// Min rejects integers below n.
+func Min(n int64) Check {
+	return Check{
+		reason:      ReasonOutOfRange,
+		description: fmt.Sprintf("must be at least %d", n),
+		kinds:       integerKinds,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return value.Int() >= n
+		},
+	}
+}
+
+// DefinedEnum rejects enum numbers the enum does not declare.
+func DefinedEnum() Check {
+	return Check{
+		reason:      ReasonUnknownValue,
+		description: "must be a defined value",
+		kinds:       enumKinds,
+		valid: func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return fd.Enum().Values().ByNumber(value.Enum()) != nil
+		},
+	}
+}
+
+// MaxItems rejects repeated fields with more than n elements.
+func MaxItems(n int) Check {
+	return Check{
+		reason:      ReasonTooManyItems,
+		description: fmt.Sprintf("must have at most %d items", n),
+		onList:      true,
+		valid: func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+			return value.List().Len() <= n
+		},
+	}
+}
+
+// Items applies checks to every element of a repeated field. Violations name
+// the element, like "types[2]".
+func Items(checks ...Check) Check {
+	return Check{onList: true, items: checks}
+}
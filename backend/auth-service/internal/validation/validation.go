@@ -0,0 +1,177 @@
+// Package validation checks protobuf requests against rules declared per
+// message and field, in the spirit of protovalidate annotations. Violations
+// are reported as google.rpc.BadRequest field violations named after the
+// proto fields, so clients can attach them to their form inputs.
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageRules holds the field rules of one message type.
+type MessageRules struct {
+	message protoreflect.MessageDescriptor
+	fields  []FieldRules
+}
+
+// FieldRules holds the checks of one field. The path is the proto name of the
+// field, with dots to reach into nested messages, like "role.name".
+type FieldRules struct {
+	path   string
+	checks []Check
+}
+
+// For declares the rules of a message type. A message without field rules
+// is still declared, to state that it was considered.
+func For(message proto.Message, fields ...FieldRules) MessageRules {
+	return MessageRules{message: message.ProtoReflect().Descriptor(), fields: fields}
+}
+
+// Field declares the checks of a field. They run in order and the first one
+// that fails is reported.
+func Field(path string, checks ...Check) FieldRules {
+	return FieldRules{path: path, checks: checks}
+}
+
+// Validator validates messages against their declared rules.
+type Validator struct {
+	messages map[protoreflect.FullName][]resolvedField
+}
+
+type resolvedField struct {
+	path   string
+	fields []protoreflect.FieldDescriptor
+	checks []Check
+}
+
+// NewValidator resolves the declared field paths and verifies that every
+// check applies to the type of its field, so a typo in the rules fails at
+// startup instead of silently passing requests.
+func NewValidator(rules ...MessageRules) (*Validator, error) {
+	v := &Validator{messages: make(map[protoreflect.FullName][]resolvedField)}
+	for _, message := range rules {
+		name := message.message.FullName()
+		if _, ok := v.messages[name]; ok {
+			return nil, fmt.Errorf("rules for %s declared twice", name)
+		}
+		resolved := make([]resolvedField, 0, len(message.fields))
+		for _, field := range message.fields {
+			path, err := resolvePath(message.message, field.path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			last := path[len(path)-1]
+			for i, check := range field.checks {
+				if !check.accepts(last) {
+					return nil, fmt.Errorf("%s.%s: check %d does not apply to a %s field", name, field.path, i+1, describeField(last))
+				}
+			}
+			resolved = append(resolved, resolvedField{path: field.path, fields: path, checks: field.checks})
+		}
+		v.messages[name] = resolved
+	}
+	return v, nil
+}
+
+func resolvePath(message protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	var fields []protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			prev := fields[i-1]
+			if prev.Kind() != protoreflect.MessageKind || prev.IsList() || prev.IsMap() {
+				return nil, fmt.Errorf("field %s is not a message", prev.Name())
+			}
+			message = prev.Message()
+		}
+		field := message.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			return nil, fmt.Errorf("unknown field %q", path)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func describeField(field protoreflect.FieldDescriptor) string {
+	if field.IsList() {
+		return "repeated " + field.Kind().String()
+	}
+	return field.Kind().String()
+}
+
+// Declared reports whether rules were declared for the message type.
+func (v *Validator) Declared(message protoreflect.MessageDescriptor) bool {
+	_, ok := v.messages[message.FullName()]
+	return ok
+}
+
+// Violations returns the field violations of a message, one per failing
+// field. Messages without declared rules have none.
+func (v *Validator) Violations(message proto.Message) []*errdetails.BadRequest_FieldViolation {
+	reflected := message.ProtoReflect()
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, field := range v.messages[reflected.Descriptor().FullName()] {
+		// Unset nested messages read as empty ones, so the checks below them
+		// see zero values.
+		parent := reflected
+		for _, fd := range field.fields[:len(field.fields)-1] {
+			parent = parent.Get(fd).Message()
+		}
+		fd := field.fields[len(field.fields)-1]
+		if violation := checkField(field, parent, fd); violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
+
+func checkField(field resolvedField, parent protoreflect.Message, fd protoreflect.FieldDescriptor) *errdetails.BadRequest_FieldViolation {
+	set := parent.Has(fd)
+	for _, check := range field.checks {
+		if !set {
+			// Only Required looks at unset fields; everything else leaves
+			// optional fields alone.
+			if check.required {
+				return check.violation(field.path)
+			}
+			continue
+		}
+		value := parent.Get(fd)
+		if check.items != nil {
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				for _, item := range check.items {
+					if !item.valid(fd, list.Get(i)) {
+						return item.violation(fmt.Sprintf("%s[%d]", field.path, i))
+					}
+				}
+			}
+			continue
+		}
+		if check.valid != nil && !check.valid(fd, value) {
+			return check.violation(field.path)
+		}
+	}
+	return nil
+}
+
+// Validate returns an InvalidArgument error carrying a BadRequest with every
+// field violation of the message, or nil if it has none.
+func (v *Validator) Validate(message proto.Message) error {
+	violations := v.Violations(message)
+	if len(violations) == 0 {
+		return nil
+	}
+	st, err := status.New(codes.InvalidArgument, "invalid request").WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+	return st.Err()
+}
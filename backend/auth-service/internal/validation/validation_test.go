@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestChecks(t *testing.T) {
+	digits := regexp.MustCompile(`^[0-9]+$`)
+	tests := []struct {
+		name    string
+		rules   MessageRules
+		message proto.Message
+		reason  string // empty when the message is valid
+	}{
+		{name: "required set", rules: For(&pb.RegisterRequest{}, Field("username", Required())), message: &pb.RegisterRequest{Username: "alice"}},
+		{name: "required unset", rules: For(&pb.RegisterRequest{}, Field("username", Required())), message: &pb.RegisterRequest{}, reason: ReasonRequired},
+		{name: "required zero number", rules: For(&pb.LogoutRequest{}, Field("user_id", Required())), message: &pb.LogoutRequest{}, reason: ReasonRequired},
+		{name: "optional unset skips checks", rules: For(&pb.RegisterRequest{}, Field("username", MinLen(3))), message: &pb.RegisterRequest{}},
+		{name: "min length counts characters", rules: For(&pb.RegisterRequest{}, Field("username", MinLen(3))), message: &pb.RegisterRequest{Username: "äöü"}},
+		{name: "too short", rules: For(&pb.RegisterRequest{}, Field("username", MinLen(3))), message: &pb.RegisterRequest{Username: "al"}, reason: ReasonTooShort},
+		{name: "max length counts characters", rules: For(&pb.RegisterRequest{}, Field("username", MaxLen(3))), message: &pb.RegisterRequest{Username: "äöü"}},
+		{name: "too long", rules: For(&pb.RegisterRequest{}, Field("username", MaxLen(3))), message: &pb.RegisterRequest{Username: "alice"}, reason: ReasonTooLong},
+		{name: "max bytes counts bytes", rules: For(&pb.RegisterRequest{}, Field("username", MaxBytes(3))), message: &pb.RegisterRequest{Username: "äö"}, reason: ReasonTooLong},
+		{name: "max bytes on bytes", rules: For(&pb.ImportUsersRequest{}, Field("data", MaxBytes(3))), message: &pb.ImportUsersRequest{Data: []byte("abcd")}, reason: ReasonTooLong},
+		{name: "pattern match", rules: For(&pb.ConfirmTOTPRequest{}, Field("code", Pattern(digits, "must be digits"))), message: &pb.ConfirmTOTPRequest{Code: "123456"}},
+		{name: "pattern mismatch", rules: For(&pb.ConfirmTOTPRequest{}, Field("code", Pattern(digits, "must be digits"))), message: &pb.ConfirmTOTPRequest{Code: "12345a"}, reason: ReasonInvalidFormat},
+		{name: "email", rules: For(&pb.RegisterRequest{}, Field("email", Email())), message: &pb.RegisterRequest{Email: "alice@example.com"}},
+		{name: "email with display name", rules: For(&pb.RegisterRequest{}, Field("email", Email())), message: &pb.RegisterRequest{Email: "Alice <alice@example.com>"}, reason: ReasonInvalidFormat},
+		{name: "email without dotted domain", rules: For(&pb.RegisterRequest{}, Field("email", Email())), message: &pb.RegisterRequest{Email: "alice@localhost"}, reason: ReasonInvalidFormat},
+		{name: "email without at", rules: For(&pb.RegisterRequest{}, Field("email", Email())), message: &pb.RegisterRequest{Email: "alice.example.com"}, reason: ReasonInvalidFormat},
+		{name: "uuid", rules: For(&pb.RevokeSessionRequest{}, Field("session_id", UUID())), message: &pb.RevokeSessionRequest{SessionId: "5f0c6d1e-3b7a-4c1d-9e2f-8a6b4c3d2e1f"}},
+		{name: "uuid without hyphens", rules: For(&pb.RevokeSessionRequest{}, Field("session_id", UUID())), message: &pb.RevokeSessionRequest{SessionId: "5f0c6d1e3b7a4c1d9e2f8a6b4c3d2e1f"}, reason: ReasonInvalidFormat},
+		{name: "uuid in braces", rules: For(&pb.RevokeSessionRequest{}, Field("session_id", UUID())), message: &pb.RevokeSessionRequest{SessionId: "{5f0c6d1e-3b7a-4c1d-9e2f-8a6b4c3d2e1f}"}, reason: ReasonInvalidFormat},
+		{name: "in", rules: For(&pb.RegisterRequest{}, Field("username", In("a", "b"))), message: &pb.RegisterRequest{Username: "b"}},
+		{name: "not in", rules: For(&pb.RegisterRequest{}, Field("username", In("a", "b"))), message: &pb.RegisterRequest{Username: "c"}, reason: ReasonUnknownValue},
+		{name: "min", rules: For(&pb.LogoutRequest{}, Field("user_id", Min(1))), message: &pb.LogoutRequest{UserId: 1}},
+		{name: "below min", rules: For(&pb.LogoutRequest{}, Field("user_id", Min(1))), message: &pb.LogoutRequest{UserId: -1}, reason: ReasonOutOfRange},
+		{name: "defined enum", rules: For(&pb.ImportUsersRequest{}, Field("format", DefinedEnum())), message: &pb.ImportUsersRequest{Format: pb.ImportFormat_IMPORT_FORMAT_JSONL}},
+		{name: "undefined enum", rules: For(&pb.ImportUsersRequest{}, Field("format", DefinedEnum())), message: &pb.ImportUsersRequest{Format: 42}, reason: ReasonUnknownValue},
+		{name: "max items", rules: For(&pb.QueryAuditEventsRequest{}, Field("types", MaxItems(2))), message: &pb.QueryAuditEventsRequest{Types: []string{"a", "b"}}},
+		{name: "too many items", rules: For(&pb.QueryAuditEventsRequest{}, Field("types", MaxItems(2))), message: &pb.QueryAuditEventsRequest{Types: []string{"a", "b", "c"}}, reason: ReasonTooManyItems},
+		{name: "items", rules: For(&pb.QueryAuditEventsRequest{}, Field("types", Items(MaxLen(3)))), message: &pb.QueryAuditEventsRequest{Types: []string{"abc", "de"}}},
+		{name: "invalid item", rules: For(&pb.QueryAuditEventsRequest{}, Field("types", Items(MaxLen(3)))), message: &pb.QueryAuditEventsRequest{Types: []string{"abc", "defg"}}, reason: ReasonTooLong},
+		{name: "nested field", rules: For(&pb.ListUsersRequest{}, Field("filter.role", MaxLen(3))), message: &pb.ListUsersRequest{Filter: &pb.UserFilter{Role: "admin"}}, reason: ReasonTooLong},
+		{name: "nested field of unset message", rules: For(&pb.ListUsersRequest{}, Field("filter.role", Required())), message: &pb.ListUsersRequest{}, reason: ReasonRequired},
+		{name: "first failing check wins", rules: For(&pb.RegisterRequest{}, Field("username", MinLen(3), MaxLen(1))), message: &pb.RegisterRequest{Username: "al"}, reason: ReasonTooShort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator, err := NewValidator(tt.rules)
+			if err != nil {
+				t.Fatalf("NewValidator() error = %v", err)
+			}
+			violations := validator.Violations(tt.message)
+			if tt.reason == "" {
+				if len(violations) != 0 {
+					t.Errorf("Violations() = %v, want none", violations)
+				}
+				return
+			}
+			if len(violations) != 1 || violations[0].Reason != tt.reason {
+				t.Errorf("Violations() = %v, want one with reason %s", violations, tt.reason)
+			}
+		})
+	}
+}
+
+func TestViolationFieldNames(t *testing.T) {
+	validator, err := NewValidator(
+		For(&pb.QueryAuditEventsRequest{}, Field("types", Items(MaxLen(3)))),
+		For(&pb.ListUsersRequest{}, Field("filter.role", MaxLen(3))),
+	)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+	tests := []struct {
+		message proto.Message
+		field   string
+	}{
+		{message: &pb.QueryAuditEventsRequest{Types: []string{"abc", "ok", "toolong"}}, field: "types[2]"},
+		{message: &pb.ListUsersRequest{Filter: &pb.UserFilter{Role: "admin"}}, field: "filter.role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			violations := validator.Violations(tt.message)
+			if len(violations) != 1 || violations[0].Field != tt.field {
+				t.Fatalf("Violations() = %v, want one for %s", violations, tt.field)
+			}
+			if !strings.HasPrefix(violations[0].Description, tt.field+" ") {
+				t.Errorf("Description = %q, want it to start with the field name", violations[0].Description)
+			}
+		})
+	}
+}
+
+func TestNewValidatorRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []MessageRules
+	}{
+		{name: "unknown field", rules: []MessageRules{For(&pb.RegisterRequest{}, Field("nickname", Required()))}},
+		{name: "path through a scalar", rules: []MessageRules{For(&pb.RegisterRequest{}, Field("username.first", Required()))}},
+		{name: "string check on an integer", rules: []MessageRules{For(&pb.LogoutRequest{}, Field("user_id", MaxLen(3)))}},
+		{name: "integer check on a string", rules: []MessageRules{For(&pb.RegisterRequest{}, Field("username", Min(1)))}},
+		{name: "scalar check on a list", rules: []MessageRules{For(&pb.QueryAuditEventsRequest{}, Field("types", MaxLen(3)))}},
+		{name: "list check on a scalar", rules: []MessageRules{For(&pb.RegisterRequest{}, Field("username", MaxItems(3)))}},
+		{name: "required item", rules: []MessageRules{For(&pb.QueryAuditEventsRequest{}, Field("types", Items(Required())))}},
+		{name: "enum check on a string", rules: []MessageRules{For(&pb.RegisterRequest{}, Field("username", DefinedEnum()))}},
+		{name: "declared twice", rules: []MessageRules{For(&pb.RegisterRequest{}), For(&pb.RegisterRequest{})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewValidator(tt.rules...); err == nil {
+				t.Errorf("NewValidator() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	validator, err := NewValidator(For(&pb.RegisterRequest{},
+		Field("username", Required()),
+		Field("email", Required(), Email()),
+	))
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+	if err := validator.Validate(&pb.RegisterRequest{Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Errorf("Validate() of a valid request error = %v", err)
+	}
+	if err := validator.Validate(&pb.LoginRequest{}); err != nil {
+		t.Errorf("Validate() of an undeclared message error = %v", err)
+	}
+	if validator.Declared((&pb.LoginRequest{}).ProtoReflect().Descriptor()) {
+		t.Errorf("Declared() of an undeclared message = true")
+	}
+
+	err = validator.Validate(&pb.RegisterRequest{Email: "nope"})
+	st, _ := status.FromError(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("Validate() code = %s, want %s", st.Code(), codes.InvalidArgument)
+	}
+	var fields []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field+":"+violation.Reason)
+			}
+		}
+	}
+	if want := "username:FIELD_REQUIRED,email:FIELD_INVALID_FORMAT"; strings.Join(fields, ",") != want {
+		t.Errorf("Validate() violations = %v, want %s", fields, want)
+	}
+}
@@ -0,0 +1,94 @@
+package authn
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Claims is the payload of the tokens issued by the auth service. Which of
+// the optional claims are present depends on the token type.
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   int64    `json:"sub"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	ID        string   `json:"jti"`
+
+	Type          string `json:"type"`
+	Role          string `json:"role,omitempty"`
+	SessionID     string `json:"sid,omitempty"`
+	AuthTime      int64  `json:"auth_time,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	Scope         string `json:"scope,omitempty"`
+	// Permissions of the role at the time the token was issued. Only set
+	// when the auth service runs with JWT_PERMISSIONS_CLAIM.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Valid satisfies jwt.Claims. It checks exp, nbf and jti without any leeway,
+// so that parsers that do not skip it still refuse expired tokens. The
+// parsers of this package skip it and call Validate, which knows the
+// expected issuer, audience and leeway.
+func (c *Claims) Valid() error {
+	if err := c.validateLifetime(0, time.Now()); err != nil {
+		return err
+	}
+	if c.ID == "" {
+		return fmt.Errorf("token has no jti")
+	}
+	return nil
+}
+
+// Validate checks the registered claims against the expected issuer and
+// audience, allowing leeway of clock skew on the time based ones.
+func (c *Claims) Validate(issuer, audience string, leeway time.Duration, now time.Time) error {
+	if c.Issuer != issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if !slices.Contains(c.Audience, audience) {
+		return fmt.Errorf("token is not issued for audience %q", audience)
+	}
+	if err := c.validateLifetime(leeway, now); err != nil {
+		return err
+	}
+	if now.Before(time.Unix(c.IssuedAt, 0).Add(-leeway)) {
+		return fmt.Errorf("token is issued in the future")
+	}
+	if c.ID == "" {
+		return fmt.Errorf("token has no jti")
+	}
+	return nil
+}
+
+// validateLifetime checks exp, which is required, and nbf.
+func (c *Claims) validateLifetime(leeway time.Duration, now time.Time) error {
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(leeway)) {
+		return fmt.Errorf("token is expired")
+	}
+	if now.Before(time.Unix(c.NotBefore, 0).Add(-leeway)) {
+		return fmt.Errorf("token is not valid yet")
+	}
+	return nil
+}
+
+// Audience is the aud claim, which RFC 7519 allows to be a single string or
+// an array of strings.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("invalid aud claim: %w", err)
+	}
+	*a = multiple
+	return nil
+}
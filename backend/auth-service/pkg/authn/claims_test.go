@@ -0,0 +1,125 @@
+package authn
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func validClaims(now time.Time) Claims {
+	return Claims{
+		Issuer:    "https://auth.example.com",
+		Audience:  Audience{"learning", "profiles"},
+		ExpiresAt: now.Add(time.Minute).Unix(),
+		NotBefore: now.Unix(),
+		IssuedAt:  now.Unix(),
+		ID:        "5f0c6d1e-3b7a-4c1d-9e2f-8a6b4c3d2e1f",
+	}
+}
+
+func TestClaimsValidate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	leeway := 30 * time.Second
+	tests := []struct {
+		name    string
+		change  func(c *Claims)
+		wantErr bool
+	}{
+		{name: "valid", change: func(c *Claims) {}},
+		{name: "wrong issuer", change: func(c *Claims) { c.Issuer = "https://evil.example.com" }, wantErr: true},
+		{name: "other audience", change: func(c *Claims) { c.Audience = Audience{"billing"} }, wantErr: true},
+		{name: "no audience", change: func(c *Claims) { c.Audience = nil }, wantErr: true},
+		{name: "no exp", change: func(c *Claims) { c.ExpiresAt = 0 }, wantErr: true},
+		{name: "expired within leeway", change: func(c *Claims) { c.ExpiresAt = now.Add(-leeway).Unix() }},
+		{name: "expired beyond leeway", change: func(c *Claims) { c.ExpiresAt = now.Add(-leeway - time.Second).Unix() }, wantErr: true},
+		{name: "nbf within leeway", change: func(c *Claims) { c.NotBefore = now.Add(leeway).Unix() }},
+		{name: "nbf beyond leeway", change: func(c *Claims) { c.NotBefore = now.Add(leeway + time.Second).Unix() }, wantErr: true},
+		{name: "iat within leeway", change: func(c *Claims) { c.IssuedAt = now.Add(leeway).Unix() }},
+		{name: "iat beyond leeway", change: func(c *Claims) { c.IssuedAt = now.Add(leeway + time.Second).Unix() }, wantErr: true},
+		{name: "no jti", change: func(c *Claims) { c.ID = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims(now)
+			tt.change(&claims)
+			err := claims.Validate("https://auth.example.com", "profiles", leeway, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClaimsValid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		change  func(c *Claims)
+		wantErr bool
+	}{
+		{name: "valid", change: func(c *Claims) {}},
+		{name: "issuer and audience are not checked", change: func(c *Claims) { c.Issuer, c.Audience = "", nil }},
+		{name: "no exp", change: func(c *Claims) { c.ExpiresAt = 0 }, wantErr: true},
+		{name: "expired", change: func(c *Claims) { c.ExpiresAt = now.Add(-2 * time.Second).Unix() }, wantErr: true},
+		{name: "not valid yet", change: func(c *Claims) { c.NotBefore = now.Add(time.Minute).Unix() }, wantErr: true},
+		{name: "no jti", change: func(c *Claims) { c.ID = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims(now)
+			tt.change(&claims)
+			if err := claims.Valid(); (err != nil) != tt.wantErr {
+				t.Errorf("Valid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAudienceUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Audience
+		wantErr bool
+	}{
+		{name: "single string", data: `"profiles"`, want: Audience{"profiles"}},
+		{name: "array", data: `["learning","profiles"]`, want: Audience{"learning", "profiles"}},
+		{name: "empty array", data: `[]`, want: Audience{}},
+		{name: "number", data: `42`, wantErr: true},
+		{name: "array of numbers", data: `[1,2]`, wantErr: true},
+		{name: "object", data: `{"aud":"profiles"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Audience
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %q, want an error", tt.data, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.data, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Unmarshal(%s) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimsUnmarshalJSON(t *testing.T) {
+	data := `{"iss":"https://auth.example.com","sub":42,"aud":"profiles","exp":1700000060,"nbf":1700000000,"iat":1700000000,"jti":"abc","type":"access","role":"user","permissions":["users:read"]}`
+	var claims Claims
+	if err := json.Unmarshal([]byte(data), &claims); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if claims.Subject != 42 || claims.Type != "access" || claims.Role != "user" || claims.ID != "abc" {
+		t.Errorf("Unmarshal() = %+v", claims)
+	}
+	if !slices.Equal(claims.Audience, Audience{"profiles"}) || !slices.Equal(claims.Permissions, []string{"users:read"}) {
+		t.Errorf("Unmarshal() audience = %q, permissions = %q", claims.Audience, claims.Permissions)
+	}
+}
@@ -0,0 +1,110 @@
+package authn
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Rule is the access rule of a single method. Public methods are served
+// without a token; otherwise a valid token is required and, when Roles is not
+// empty, the caller must hold one of them. The caller must also hold every
+// one of Permissions.
+type Rule struct {
+	Public      bool
+	Roles       []string
+	Permissions []string
+}
+
+// Policy maps full method names, e.g. "/tests.TestService/CreateTest", to
+// their rules. Methods that are not listed require a valid token of any role.
+type Policy map[string]Rule
+
+func (p Policy) rule(fullMethod string) Rule {
+	return p[fullMethod]
+}
+
+// UnaryServerInterceptor authenticates unary calls according to policy and
+// stores the Principal in the handler's context.
+func UnaryServerInterceptor(validator Validator, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, validator, policy.rule(info.FullMethod))
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls.
+func StreamServerInterceptor(validator Validator, policy Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), validator, policy.rule(info.FullMethod))
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, validator Validator, rule Rule) (context.Context, error) {
+	if rule.Public {
+		return ctx, nil
+	}
+
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	principal, err := validator.Validate(ctx, token)
+	if err != nil {
+		// Errors of the remote validator already carry a gRPC status, e.g.
+		// Unavailable when the auth service is down; keep those.
+		if st, ok := status.FromError(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if len(rule.Roles) > 0 && !slices.Contains(rule.Roles, principal.Role) {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	for _, permission := range rule.Permissions {
+		if !slices.Contains(principal.Permissions, permission) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+	}
+	return NewContext(ctx, principal), nil
+}
+
+// bearerToken returns the token from the "authorization: Bearer <token>"
+// metadata of the request.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// serverStream swaps the context of a stream for the authenticated one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
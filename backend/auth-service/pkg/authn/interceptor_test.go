@@ -0,0 +1,88 @@
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/keys"
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	key, err := keys.GenerateKey(keys.AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(keys.NewKeySet(key).JWKS())
+	}))
+	defer jwks.Close()
+
+	validator := NewJWKSValidator(JWKSConfig{URL: jwks.URL, Issuer: "https://auth.example.com", Audience: "profiles"})
+	policy := Policy{
+		"/profiles.Profiles/Health":     {Public: true},
+		"/profiles.Profiles/DeleteUser": {Roles: []string{"admin"}},
+	}
+	interceptor := UnaryServerInterceptor(validator, policy)
+
+	sign := func(change func(c *Claims)) string {
+		claims := validClaims(time.Now())
+		claims.Subject = 42
+		claims.Type = accessTokenType
+		claims.Role = "user"
+		change(&claims)
+		token := jwt.NewWithClaims(key.SigningMethod(), &claims)
+		token.Header["kid"] = key.ID
+		signed, err := token.SignedString(key.Private)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		want   codes.Code
+	}{
+		{name: "valid token", method: "/profiles.Profiles/GetProfile", token: sign(func(c *Claims) {})},
+		{name: "public method without token", method: "/profiles.Profiles/Health"},
+		{name: "missing token", method: "/profiles.Profiles/GetProfile", want: codes.Unauthenticated},
+		{name: "other issuer", method: "/profiles.Profiles/GetProfile", token: sign(func(c *Claims) { c.Issuer = "https://evil.example.com" }), want: codes.Unauthenticated},
+		{name: "other audience", method: "/profiles.Profiles/GetProfile", token: sign(func(c *Claims) { c.Audience = Audience{"billing"} }), want: codes.Unauthenticated},
+		{name: "expired token", method: "/profiles.Profiles/GetProfile", token: sign(func(c *Claims) { c.ExpiresAt = time.Now().Add(-time.Hour).Unix() }), want: codes.Unauthenticated},
+		{name: "refresh token", method: "/profiles.Profiles/GetProfile", token: sign(func(c *Claims) { c.Type = "refresh" }), want: codes.Unauthenticated},
+		{name: "role not allowed", method: "/profiles.Profiles/DeleteUser", token: sign(func(c *Claims) {}), want: codes.PermissionDenied},
+		{name: "role allowed", method: "/profiles.Profiles/DeleteUser", token: sign(func(c *Claims) { c.Role = "admin" })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+			var principal *Principal
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				principal, _ = FromContext(ctx)
+				return nil, nil
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.want {
+				t.Fatalf("interceptor() error = %v, want %s", err, tt.want)
+			}
+			if tt.want == codes.OK && tt.token != "" && (principal == nil || principal.UserID != 42) {
+				t.Errorf("interceptor() principal = %+v, want user 42", principal)
+			}
+		})
+	}
+}
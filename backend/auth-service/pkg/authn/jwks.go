@@ -0,0 +1,204 @@
+package authn
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/internal/keys"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const accessTokenType = "access"
+
+const (
+	defaultLeeway          = 30 * time.Second
+	defaultRefreshInterval = 5 * time.Minute
+	// minFetchInterval limits how often tokens with an unknown kid may make
+	// the validator fetch the key set again.
+	minFetchInterval = 5 * time.Second
+)
+
+type JWKSConfig struct {
+	// URL of the auth service key set, e.g. http://auth-service:8081/.well-known/jwks.json.
+	URL string
+	// Issuer and Audience the tokens must carry. Audience is the name of the
+	// validating service as listed in the auth service's JWT_AUDIENCES.
+	Issuer   string
+	Audience string
+	// Leeway is the tolerated clock skew, 30s by default.
+	Leeway time.Duration
+	// RefreshInterval is how long the key set is cached, 5m by default.
+	RefreshInterval time.Duration
+	HTTPClient      *http.Client
+}
+
+// JWKSValidator validates tokens locally against the key set the auth service
+// publishes. It cannot see sessions, so a token stays valid until it expires
+// even if the user logged out; use RemoteValidator where that matters.
+type JWKSValidator struct {
+	config JWKSConfig
+
+	mu        sync.Mutex
+	keys      map[string]publicKey
+	fetchedAt time.Time
+}
+
+type publicKey struct {
+	algorithm string
+	key       interface{}
+}
+
+func NewJWKSValidator(cfg JWKSConfig) *JWKSValidator {
+	if cfg.Leeway == 0 {
+		cfg.Leeway = defaultLeeway
+	}
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &JWKSValidator{config: cfg}
+}
+
+func (v *JWKSValidator) Validate(ctx context.Context, token string) (*Principal, error) {
+	claims := &Claims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.lookup(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != accessTokenType {
+		return nil, fmt.Errorf("invalid token type: expected %s, got %s", accessTokenType, claims.Type)
+	}
+	if err := claims.Validate(v.config.Issuer, v.config.Audience, v.config.Leeway, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		UserID:      claims.Subject,
+		Role:        claims.Role,
+		Scopes:      strings.Fields(claims.Scope),
+		SessionID:   claims.SessionID,
+		IssuedAt:    unixTime(claims.IssuedAt),
+		ExpiresAt:   unixTime(claims.ExpiresAt),
+		AuthTime:    unixTime(claims.AuthTime),
+		Permissions: claims.Permissions,
+	}, nil
+}
+
+// lookup returns the key with the given kid, fetching the key set when the
+// cache is stale or, at a limited rate, when the kid is unknown because the
+// auth service has rotated keys in the meantime.
+func (v *JWKSValidator) lookup(ctx context.Context, kid string) (publicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	age := time.Since(v.fetchedAt)
+	if (ok && age < v.config.RefreshInterval) || (!ok && age < minFetchInterval) {
+		if !ok {
+			return publicKey{}, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+
+	fetched, err := v.fetch(ctx)
+	if err != nil {
+		if ok {
+			// Keep serving the cached key while the auth service is unreachable.
+			return key, nil
+		}
+		return publicKey{}, err
+	}
+	v.keys = fetched
+	v.fetchedAt = time.Now()
+
+	key, ok = v.keys[kid]
+	if !ok {
+		return publicKey{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (v *JWKSValidator) fetch(ctx context.Context) (map[string]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %s", resp.Status)
+	}
+
+	var set keys.JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	fetched := make(map[string]publicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			// Skip keys of types this package does not know.
+			continue
+		}
+		fetched[jwk.KeyID] = key
+	}
+	return fetched, nil
+}
+
+func parseJWK(jwk keys.JWK) (publicKey, error) {
+	switch {
+	case jwk.KeyType == "RSA" && jwk.Algorithm == keys.AlgorithmRS256:
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		return publicKey{
+			algorithm: jwk.Algorithm,
+			key: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			},
+		}, nil
+	case jwk.KeyType == "OKP" && jwk.Curve == "Ed25519" && jwk.Algorithm == keys.AlgorithmEdDSA:
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return publicKey{}, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+		return publicKey{algorithm: jwk.Algorithm, key: ed25519.PublicKey(x)}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key %s/%s", jwk.KeyType, jwk.Algorithm)
+	}
+}
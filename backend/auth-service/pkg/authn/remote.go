@@ -0,0 +1,48 @@
+package authn
+
+import (
+	"context"
+
+	pb "github.com/SanctusNiccolum/SiriusLingo/backend/auth-service/gen/go/proto"
+)
+
+// RemoteValidator validates tokens by calling AuthService.ValidateToken. It is
+// slower than JWKSValidator but sees logouts and revoked sessions at once.
+type RemoteValidator struct {
+	client   pb.AuthServiceClient
+	issuer   string
+	audience string
+}
+
+// NewRemoteValidator validates tokens through client. Empty issuer and
+// audience leave the choice to the auth service, which then expects its own.
+func NewRemoteValidator(client pb.AuthServiceClient, issuer, audience string) *RemoteValidator {
+	return &RemoteValidator{
+		client:   client,
+		issuer:   issuer,
+		audience: audience,
+	}
+}
+
+func (v *RemoteValidator) Validate(ctx context.Context, token string) (*Principal, error) {
+	resp, err := v.client.ValidateToken(ctx, &pb.ValidateTokenRequest{
+		Token:     token,
+		TokenType: accessTokenType,
+		Audience:  v.audience,
+		Issuer:    v.issuer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{
+		UserID:      resp.UserId,
+		Username:    resp.Username,
+		Role:        resp.Role,
+		Scopes:      resp.Scopes,
+		SessionID:   resp.SessionId,
+		IssuedAt:    unixTime(resp.IssuedAt),
+		ExpiresAt:   unixTime(resp.ExpiresAt),
+		AuthTime:    unixTime(resp.AuthTime),
+		Permissions: resp.Permissions,
+	}, nil
+}